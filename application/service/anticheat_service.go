@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"strings"
+	"time"
+)
+
+const (
+	// antiCheatMaxDuration 单次记录的最长合理时长（秒）
+	antiCheatMaxDuration = 2 * 60 * 60
+	// antiCheatDailyCap 单个自然日的记录上限
+	antiCheatDailyCap = 12
+	// antiCheatMaxBackdate 补记时间最多早于提交时间多久
+	antiCheatMaxBackdate = 72 * time.Hour
+	// antiCheatBurstWindow 集中提交的检测窗口
+	antiCheatBurstWindow = 10 * time.Minute
+	// antiCheatBurstCount 检测窗口内提交的记录数达到该值视为集中提交（含本条）
+	antiCheatBurstCount = 5
+)
+
+var (
+	// ErrRecordFlagNotFound 标记不存在或不属于当前用户
+	ErrRecordFlagNotFound = errors.New("标记不存在")
+	// ErrNotModerator 当前用户不是审核员
+	ErrNotModerator = errors.New("没有审核权限")
+)
+
+// 审核结果
+const (
+	ReviewDecisionDismiss = "dismiss" // 误判，恢复记录
+	ReviewDecisionConfirm = "confirm" // 确认作弊
+)
+
+// AntiCheatService 反作弊服务接口
+type AntiCheatService interface {
+	// HandleRecordChanged 记录创建或更新后执行反作弊规则，命中时标记记录并放入审核队列
+	// 更新后不再命中规则的记录，未审核的标记自动恢复
+	HandleRecordChanged(ctx context.Context, e event.Event)
+
+	// GetUserFlags 分页获取用户自己被标记的记录
+	GetUserFlags(ctx context.Context, userID uint64, page, size int) ([]*entity.RecordFlag, int64, error)
+
+	// Appeal 用户对标记提出申诉，每个标记只能申诉一次
+	Appeal(ctx context.Context, userID, flagID uint64, reason string) (*entity.RecordFlag, error)
+
+	// GetReviewQueue 审核员分页获取待审核的标记，status为空时返回全部待审核和已申诉的标记
+	GetReviewQueue(ctx context.Context, moderatorID uint64, status string, page, size int) ([]*entity.RecordFlag, int64, error)
+
+	// Review 审核员审核标记
+	Review(ctx context.Context, moderatorID, flagID uint64, decision, note string) (*entity.RecordFlag, error)
+}
+
+// antiCheatService 反作弊服务实现
+type antiCheatService struct {
+	flagRepo     repository.RecordFlagRepository
+	recordRepo   repository.RecordRepository
+	publisher    event.Publisher
+	moderatorIDs map[uint64]bool
+}
+
+// NewAntiCheatService 创建反作弊服务
+func NewAntiCheatService(
+	flagRepo repository.RecordFlagRepository,
+	recordRepo repository.RecordRepository,
+	publisher event.Publisher,
+	moderatorIDs []uint64,
+) AntiCheatService {
+	moderators := make(map[uint64]bool, len(moderatorIDs))
+	for _, id := range moderatorIDs {
+		moderators[id] = true
+	}
+	return &antiCheatService{
+		flagRepo:     flagRepo,
+		recordRepo:   recordRepo,
+		publisher:    publisher,
+		moderatorIDs: moderators,
+	}
+}
+
+// cheatCheckContext 反作弊规则检查所需的数据
+type cheatCheckContext struct {
+	record        *entity.Record
+	nearby        []*entity.Record // 前后相邻日期内的其他记录
+	recentCreated int64            // 检测窗口内提交的记录数（含本条）
+	submittedAt   time.Time
+}
+
+// cheatRule 反作弊规则，命中时返回说明
+type cheatRule struct {
+	name  string
+	check func(c *cheatCheckContext) (bool, string)
+}
+
+// cheatRules 按顺序执行的反作弊规则
+var cheatRules = []cheatRule{
+	{name: entity.CheatRuleOverlap, check: checkOverlap},
+	{name: entity.CheatRuleDailyCap, check: checkDailyCap},
+	{name: entity.CheatRuleDuration, check: checkDuration},
+	{name: entity.CheatRuleBackdated, check: checkBackdated},
+	{name: entity.CheatRuleBurst, check: checkBurst},
+}
+
+// checkOverlap 与其他记录的时间段重叠
+func checkOverlap(c *cheatCheckContext) (bool, string) {
+	start, end := recordInterval(c.record)
+	for _, other := range c.nearby {
+		otherStart, otherEnd := recordInterval(other)
+		if start.Before(otherEnd) && otherStart.Before(end) {
+			return true, fmt.Sprintf("与记录%d的时间段重叠", other.ID)
+		}
+	}
+	return false, ""
+}
+
+// checkDailyCap 当天记录数超过上限
+func checkDailyCap(c *cheatCheckContext) (bool, string) {
+	day := startOfDay(c.record.RecordTime)
+	count := 1
+	for _, other := range c.nearby {
+		if startOfDay(other.RecordTime).Equal(day) {
+			count++
+		}
+	}
+	if count > antiCheatDailyCap {
+		return true, fmt.Sprintf("当天已有%d条记录，超过上限%d条", count, antiCheatDailyCap)
+	}
+	return false, ""
+}
+
+// checkDuration 时长不合理
+func checkDuration(c *cheatCheckContext) (bool, string) {
+	if c.record.Duration < 0 || c.record.Duration > antiCheatMaxDuration {
+		return true, fmt.Sprintf("时长%d秒不在合理范围内", c.record.Duration)
+	}
+	return false, ""
+}
+
+// checkBackdated 记录时间远早于提交时间
+func checkBackdated(c *cheatCheckContext) (bool, string) {
+	if c.submittedAt.Sub(c.record.RecordTime) > antiCheatMaxBackdate {
+		return true, fmt.Sprintf("记录时间早于提交时间超过%d小时", int(antiCheatMaxBackdate.Hours()))
+	}
+	return false, ""
+}
+
+// checkBurst 短时间内集中提交
+func checkBurst(c *cheatCheckContext) (bool, string) {
+	if c.recentCreated >= antiCheatBurstCount {
+		return true, fmt.Sprintf("%d分钟内提交了%d条记录", int(antiCheatBurstWindow.Minutes()), c.recentCreated)
+	}
+	return false, ""
+}
+
+// recordInterval 记录占用的时间段，时长为0时按1秒计算
+func recordInterval(record *entity.Record) (time.Time, time.Time) {
+	duration := record.Duration
+	if duration < 1 {
+		duration = 1
+	}
+	return record.RecordTime, record.RecordTime.Add(time.Duration(duration) * time.Second)
+}
+
+// HandleRecordChanged 记录创建或更新后执行反作弊规则
+func (s *antiCheatService) HandleRecordChanged(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RecordChanged)
+	if !ok || changed.Record == nil {
+		return
+	}
+
+	var err error
+	switch changed.Name {
+	case event.RecordCreated:
+		err = s.inspectNew(ctx, changed.Record, time.Now())
+	case event.RecordUpdated:
+		err = s.inspectUpdated(ctx, changed.Record, changed.Previous)
+	}
+	if err != nil {
+		log.Printf("检查记录%d失败: %v", changed.Record.ID, err)
+	}
+}
+
+// inspectNew 检查尚未被标记的记录，命中任意规则时标记记录
+func (s *antiCheatService) inspectNew(ctx context.Context, record *entity.Record, submittedAt time.Time) error {
+	rules, reason, err := s.inspect(ctx, record, submittedAt)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	flag := &entity.RecordFlag{
+		RecordID: record.ID,
+		UserID:   record.UserID,
+		Rules:    rules,
+		Reason:   reason,
+		Status:   entity.RecordFlagStatusPending,
+	}
+	if err := s.flagRepo.Save(ctx, flag); err != nil {
+		return err
+	}
+
+	s.publisher.Publish(ctx, &event.RecordFlagChanged{Flag: flag, Record: record})
+	return nil
+}
+
+// inspectUpdated 重新检查更新后的记录，提交时间仍按记录的创建时间计算
+// 未审核的标记：命中时更新规则，不再命中时自动恢复；已恢复的标记在命中其他问题时重新进入审核队列；
+// 已确认作弊的标记保持不变，只能通过申诉处理
+func (s *antiCheatService) inspectUpdated(ctx context.Context, record, previous *entity.Record) error {
+	submittedAt := record.CreatedAt
+	if previous != nil {
+		submittedAt = previous.CreatedAt
+	}
+	if submittedAt.IsZero() {
+		submittedAt = time.Now()
+	}
+
+	flag, err := s.flagRepo.FindByRecordID(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if flag == nil {
+		return s.inspectNew(ctx, record, submittedAt)
+	}
+	if flag.Status == entity.RecordFlagStatusConfirmed {
+		return nil
+	}
+
+	rules, reason, err := s.inspect(ctx, record, submittedAt)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(rules) == 0 && flag.Status == entity.RecordFlagStatusDismissed:
+		return nil
+	case len(rules) == 0:
+		now := time.Now()
+		flag.Status = entity.RecordFlagStatusDismissed
+		flag.ReviewerID = 0
+		flag.ReviewNote = "记录修改后未命中反作弊规则，自动恢复"
+		flag.ReviewedAt = &now
+	case flag.Reason == reason:
+		return nil
+	case flag.Status == entity.RecordFlagStatusDismissed:
+		// 审核员恢复的是修改前的问题，新的问题需要重新审核
+		flag.Rules = rules
+		flag.Reason = reason
+		flag.Status = entity.RecordFlagStatusPending
+		flag.AppealReason = ""
+		flag.AppealedAt = nil
+		flag.ReviewerID = 0
+		flag.ReviewNote = ""
+		flag.ReviewedAt = nil
+	default:
+		flag.Rules = rules
+		flag.Reason = reason
+	}
+	if err := s.flagRepo.Update(ctx, flag); err != nil {
+		return err
+	}
+
+	s.publisher.Publish(ctx, &event.RecordFlagChanged{Flag: flag, Record: record})
+	return nil
+}
+
+// inspect 执行所有规则，返回命中的规则及说明
+func (s *antiCheatService) inspect(ctx context.Context, record *entity.Record, submittedAt time.Time) ([]string, string, error) {
+	day := startOfDay(record.RecordTime)
+	records, err := s.recordRepo.FindAllByDateRange(ctx, record.UserID, day.AddDate(0, 0, -1), endOfRange(day.AddDate(0, 0, 2)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	nearby := make([]*entity.Record, 0, len(records))
+	for _, other := range records {
+		if other.ID != record.ID {
+			nearby = append(nearby, other)
+		}
+	}
+
+	// 数据库中的创建时间可能按秒取整，结束时间放宽1秒以包含本条记录
+	recentCreated, err := s.recordRepo.CountCreatedBetween(ctx, record.UserID, submittedAt.Add(-antiCheatBurstWindow), submittedAt.Add(time.Second))
+	if err != nil {
+		return nil, "", err
+	}
+
+	c := &cheatCheckContext{
+		record:        record,
+		nearby:        nearby,
+		recentCreated: recentCreated,
+		submittedAt:   submittedAt,
+	}
+
+	var rules, reasons []string
+	for _, rule := range cheatRules {
+		if hit, reason := rule.check(c); hit {
+			rules = append(rules, rule.name)
+			reasons = append(reasons, reason)
+		}
+	}
+	return rules, strings.Join(reasons, "；"), nil
+}
+
+// GetUserFlags 分页获取用户自己被标记的记录
+func (s *antiCheatService) GetUserFlags(ctx context.Context, userID uint64, page, size int) ([]*entity.RecordFlag, int64, error) {
+	flags, total, err := s.flagRepo.FindByUserID(ctx, userID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.fillRecords(ctx, flags); err != nil {
+		return nil, 0, err
+	}
+	return flags, total, nil
+}
+
+// Appeal 用户对标记提出申诉
+func (s *antiCheatService) Appeal(ctx context.Context, userID, flagID uint64, reason string) (*entity.RecordFlag, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > 500 {
+		return nil, errors.New("申诉理由不能为空且不能超过500个字符")
+	}
+
+	flag, err := s.flagRepo.FindByID(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil || flag.UserID != userID {
+		return nil, ErrRecordFlagNotFound
+	}
+	if flag.AppealedAt != nil {
+		return nil, errors.New("该标记已申诉过")
+	}
+	if flag.Status != entity.RecordFlagStatusPending && flag.Status != entity.RecordFlagStatusConfirmed {
+		return nil, errors.New("该标记无需申诉")
+	}
+
+	now := time.Now()
+	flag.Status = entity.RecordFlagStatusAppealed
+	flag.AppealReason = reason
+	flag.AppealedAt = &now
+	if err := s.flagRepo.Update(ctx, flag); err != nil {
+		return nil, err
+	}
+	return flag, nil
+}
+
+// GetReviewQueue 审核员分页获取待审核的标记
+func (s *antiCheatService) GetReviewQueue(ctx context.Context, moderatorID uint64, status string, page, size int) ([]*entity.RecordFlag, int64, error) {
+	if !s.moderatorIDs[moderatorID] {
+		return nil, 0, ErrNotModerator
+	}
+
+	statuses := entity.RecordFlagStatusesInQueue
+	if status != "" {
+		if status != entity.RecordFlagStatusPending && status != entity.RecordFlagStatusAppealed {
+			return nil, 0, errors.New("无效的审核状态，可选值为pending、appealed")
+		}
+		statuses = []string{status}
+	}
+
+	flags, total, err := s.flagRepo.FindByStatuses(ctx, statuses, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.fillRecords(ctx, flags); err != nil {
+		return nil, 0, err
+	}
+	return flags, total, nil
+}
+
+// Review 审核员审核标记，恢复或确认后发布事件以便刷新排行榜
+func (s *antiCheatService) Review(ctx context.Context, moderatorID, flagID uint64, decision, note string) (*entity.RecordFlag, error) {
+	if !s.moderatorIDs[moderatorID] {
+		return nil, ErrNotModerator
+	}
+
+	flag, err := s.flagRepo.FindByID(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		return nil, ErrRecordFlagNotFound
+	}
+	if flag.Status != entity.RecordFlagStatusPending && flag.Status != entity.RecordFlagStatusAppealed {
+		return nil, errors.New("该标记已审核")
+	}
+
+	switch decision {
+	case ReviewDecisionDismiss:
+		flag.Status = entity.RecordFlagStatusDismissed
+	case ReviewDecisionConfirm:
+		flag.Status = entity.RecordFlagStatusConfirmed
+	default:
+		return nil, errors.New("无效的审核结果，可选值为dismiss、confirm")
+	}
+
+	now := time.Now()
+	flag.ReviewerID = moderatorID
+	flag.ReviewNote = strings.TrimSpace(note)
+	flag.ReviewedAt = &now
+	if err := s.flagRepo.Update(ctx, flag); err != nil {
+		return nil, err
+	}
+
+	record, err := s.recordRepo.FindByID(ctx, flag.RecordID)
+	if err != nil {
+		return nil, err
+	}
+	flag.Record = record
+	if record != nil {
+		s.publisher.Publish(ctx, &event.RecordFlagChanged{Flag: flag, Record: record})
+	}
+	return flag, nil
+}
+
+// fillRecords 为标记补充记录详情，记录已删除时为空
+func (s *antiCheatService) fillRecords(ctx context.Context, flags []*entity.RecordFlag) error {
+	for _, flag := range flags {
+		record, err := s.recordRepo.FindByID(ctx, flag.RecordID)
+		if err != nil {
+			return err
+		}
+		flag.Record = record
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"record-project/domain/entity"
+)
+
+// cheatTestTime 测试使用的记录时间，东八区上午10点
+var cheatTestTime = time.Date(2024, 5, 20, 10, 0, 0, 0, shanghaiLocation)
+
+// cheatRecord 创建指定时间和时长（秒）的记录
+func cheatRecord(id uint64, recordTime time.Time, duration int) *entity.Record {
+	return &entity.Record{ID: id, UserID: 1, RecordTime: recordTime, Duration: duration}
+}
+
+func TestCheckOverlap(t *testing.T) {
+	tests := []struct {
+		name   string
+		record *entity.Record
+		nearby []*entity.Record
+		want   bool
+	}{
+		{
+			name:   "没有其他记录",
+			record: cheatRecord(1, cheatTestTime, 300),
+			want:   false,
+		},
+		{
+			name:   "开始时间落在其他记录的时间段内",
+			record: cheatRecord(1, cheatTestTime, 300),
+			nearby: []*entity.Record{cheatRecord(2, cheatTestTime.Add(-2*time.Minute), 300)},
+			want:   true,
+		},
+		{
+			name:   "包含其他记录的时间段",
+			record: cheatRecord(1, cheatTestTime, 600),
+			nearby: []*entity.Record{cheatRecord(2, cheatTestTime.Add(2*time.Minute), 60)},
+			want:   true,
+		},
+		{
+			name:   "首尾相接不算重叠",
+			record: cheatRecord(1, cheatTestTime, 300),
+			nearby: []*entity.Record{cheatRecord(2, cheatTestTime.Add(5*time.Minute), 300)},
+			want:   false,
+		},
+		{
+			name:   "时长为0的记录按1秒计算",
+			record: cheatRecord(1, cheatTestTime, 0),
+			nearby: []*entity.Record{cheatRecord(2, cheatTestTime, 0)},
+			want:   true,
+		},
+		{
+			name:   "时长为0的记录与下一秒的记录不重叠",
+			record: cheatRecord(1, cheatTestTime, 0),
+			nearby: []*entity.Record{cheatRecord(2, cheatTestTime.Add(time.Second), 0)},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := checkOverlap(&cheatCheckContext{record: tt.record, nearby: tt.nearby})
+			if got != tt.want {
+				t.Fatalf("checkOverlap() = %v, want %v", got, tt.want)
+			}
+			if got && !strings.Contains(reason, "记录2") {
+				t.Errorf("reason = %q, want mention of 记录2", reason)
+			}
+		})
+	}
+}
+
+func TestCheckDailyCap(t *testing.T) {
+	// sameDay 当天其他时间的n条记录
+	sameDay := func(n int) []*entity.Record {
+		records := make([]*entity.Record, n)
+		for i := range records {
+			records[i] = cheatRecord(uint64(i+2), cheatTestTime.Add(time.Duration(i+1)*10*time.Minute), 60)
+		}
+		return records
+	}
+
+	tests := []struct {
+		name   string
+		nearby []*entity.Record
+		want   bool
+	}{
+		{name: "没有其他记录", want: false},
+		{name: "含本条恰好达到上限", nearby: sameDay(antiCheatDailyCap - 1), want: false},
+		{name: "含本条超过上限", nearby: sameDay(antiCheatDailyCap), want: true},
+		{
+			name: "前后一天的记录不计入当天",
+			nearby: append(sameDay(antiCheatDailyCap-1),
+				cheatRecord(100, cheatTestTime.AddDate(0, 0, -1), 60),
+				cheatRecord(101, cheatTestTime.AddDate(0, 0, 1), 60)),
+			want: false,
+		},
+		{
+			// 东八区当天0点前的记录属于前一天，即使UTC日期相同
+			name: "按东八区划分自然日",
+			nearby: append(sameDay(antiCheatDailyCap-1),
+				cheatRecord(100, startOfDay(cheatTestTime).Add(-time.Minute), 60)),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := checkDailyCap(&cheatCheckContext{record: cheatRecord(1, cheatTestTime, 60), nearby: tt.nearby})
+			if got != tt.want {
+				t.Fatalf("checkDailyCap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBurst(t *testing.T) {
+	tests := []struct {
+		name          string
+		recentCreated int64
+		want          bool
+	}{
+		{name: "只有本条", recentCreated: 1, want: false},
+		{name: "未达到阈值", recentCreated: antiCheatBurstCount - 1, want: false},
+		{name: "恰好达到阈值", recentCreated: antiCheatBurstCount, want: true},
+		{name: "超过阈值", recentCreated: antiCheatBurstCount + 3, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := checkBurst(&cheatCheckContext{record: cheatRecord(1, cheatTestTime, 60), recentCreated: tt.recentCreated})
+			if got != tt.want {
+				t.Fatalf("checkBurst() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"log"
 	"record-project/domain/entity"
 	"record-project/infrastructure/auth"
 	"record-project/infrastructure/wechat"
@@ -14,7 +15,7 @@ import (
 // AuthService 认证服务接口
 // 确保AuthService接口中有以下方法
 type AuthService interface {
-	WechatLogin(ctx context.Context, code string) (*entity.User, string, error)
+	WechatLogin(ctx context.Context, code, scene string) (*entity.User, string, error)
 	UpdateUserInfo(ctx context.Context, userID uint64, nickname, avatarURL string) error
 	GetUserIDFromToken(ctx *gin.Context) (uint64, error)
 }
@@ -39,19 +40,22 @@ func (s *authService) GetUserIDFromToken(ctx *gin.Context) (uint64, error) {
 type authService struct {
 	userService   UserService
 	wechatService wechat.WechatService
+	inviteService InviteService
 }
 
 // NewAuthService 创建认证服务
-func NewAuthService(userService UserService, wechatService wechat.WechatService) AuthService {
+func NewAuthService(userService UserService, wechatService wechat.WechatService, inviteService InviteService) AuthService {
 	return &authService{
 		userService:   userService,
 		wechatService: wechatService,
+		inviteService: inviteService,
 	}
 }
 
 // WechatLogin 微信登录
 // 修改WechatLogin方法，使用JWT生成token
-func (s *authService) WechatLogin(ctx context.Context, code string) (*entity.User, string, error) {
+// scene为通过邀请小程序码进入时携带的参数，登录后与邀请人成为好友
+func (s *authService) WechatLogin(ctx context.Context, code, scene string) (*entity.User, string, error) {
 	// 调用微信服务获取openid
 	wxResp, err := s.wechatService.Code2Session(code)
 	if err != nil {
@@ -65,6 +69,7 @@ func (s *authService) WechatLogin(ctx context.Context, code string) (*entity.Use
 	}
 
 	// 用户不存在则创建新用户
+	newUser := user == nil
 	if user == nil {
 		openIDSuffix := wxResp.OpenID
 		if len(openIDSuffix) > 6 {
@@ -84,6 +89,13 @@ func (s *authService) WechatLogin(ctx context.Context, code string) (*entity.Use
 		}
 	}
 
+	// 处理邀请，失败时不影响登录
+	if scene != "" {
+		if err := s.inviteService.RedeemScene(ctx, user.ID, scene, newUser); err != nil {
+			log.Printf("用户%d通过邀请添加好友失败(scene=%s): %v", user.ID, scene, err)
+		}
+	}
+
 	// 使用JWT生成token
 	token, err := auth.GenerateToken(user)
 	if err != nil {
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"time"
+)
+
+// BadgeService 徽章服务接口
+type BadgeService interface {
+	// GetCatalog 获取全部徽章定义
+	GetCatalog() []*entity.Badge
+
+	// GetUserBadges 获取用户获得的徽章（按获得时间倒序）
+	GetUserBadges(ctx context.Context, userID uint64) ([]*entity.UserBadge, error)
+
+	// HandleRecordChanged 记录创建或修改时判断记录相关的徽章
+	HandleRecordChanged(ctx context.Context, e event.Event)
+
+	// HandleFriendAccepted 成为好友时为双方判断好友相关的徽章
+	HandleFriendAccepted(ctx context.Context, e event.Event)
+
+	// Backfill 按现有数据为用户补发徽章，userIDs为空时处理全部用户，返回补发的徽章数
+	// 补发的徽章不发送通知也不生成动态
+	Backfill(ctx context.Context, userIDs []uint64, now time.Time) (int, error)
+}
+
+// badgeService 徽章服务实现
+type badgeService struct {
+	badgeRepo           repository.UserBadgeRepository
+	recordRepo          repository.RecordRepository
+	friendRepo          repository.FriendRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+	publisher           event.Publisher
+}
+
+// NewBadgeService 创建徽章服务
+func NewBadgeService(
+	badgeRepo repository.UserBadgeRepository,
+	recordRepo repository.RecordRepository,
+	friendRepo repository.FriendRepository,
+	userRepo repository.UserRepository,
+	notificationService NotificationService,
+	publisher event.Publisher,
+) BadgeService {
+	return &badgeService{
+		badgeRepo:           badgeRepo,
+		recordRepo:          recordRepo,
+		friendRepo:          friendRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		publisher:           publisher,
+	}
+}
+
+// GetCatalog 获取全部徽章定义
+func (s *badgeService) GetCatalog() []*entity.Badge {
+	return entity.BadgeCatalog
+}
+
+// GetUserBadges 获取用户获得的徽章，目录中已移除的徽章不再展示
+func (s *badgeService) GetUserBadges(ctx context.Context, userID uint64) ([]*entity.UserBadge, error) {
+	badges, err := s.badgeRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.UserBadge, 0, len(badges))
+	for _, badge := range badges {
+		if badge.Badge != nil {
+			result = append(result, badge)
+		}
+	}
+	return result, nil
+}
+
+// HandleRecordChanged 记录创建或修改时判断记录相关的徽章
+// 需要在反作弊检查之后订阅，以便被标记的记录不计入；记录删除后已获得的徽章不收回
+// 与好友同分钟记录的徽章只检查这条记录所在的分钟
+func (s *badgeService) HandleRecordChanged(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RecordChanged)
+	if !ok || changed.Record == nil || changed.Name == event.RecordDeleted {
+		return
+	}
+
+	userID := changed.Record.UserID
+	facts := newBadgeFacts(userID)
+	facts.syncFrom = changed.Record.RecordTime.Truncate(time.Minute)
+	facts.syncTo = facts.syncFrom.Add(time.Minute)
+	if _, err := s.evaluate(ctx, facts, time.Now(), true, func(rule entity.BadgeRule) bool {
+		return rule.RecordBased() || rule.Kind == entity.BadgeRuleFriendSync
+	}); err != nil {
+		log.Printf("判断用户%d的徽章失败: %v", userID, err)
+	}
+}
+
+// HandleFriendAccepted 成为好友时为双方判断好友相关的徽章
+// 与好友同分钟记录的徽章只需要检查新的好友
+func (s *badgeService) HandleFriendAccepted(ctx context.Context, e event.Event) {
+	accepted, ok := e.(*event.FriendAcceptedEvent)
+	if !ok || accepted.Relation == nil {
+		return
+	}
+
+	now := time.Now()
+	pairs := [][2]uint64{
+		{accepted.Relation.UserID, accepted.Relation.FriendID},
+		{accepted.Relation.FriendID, accepted.Relation.UserID},
+	}
+	for _, pair := range pairs {
+		userID := pair[0]
+		facts := newBadgeFacts(userID)
+		facts.syncWith = []uint64{pair[1]}
+		if _, err := s.evaluate(ctx, facts, now, true, func(rule entity.BadgeRule) bool {
+			return rule.FriendBased()
+		}); err != nil {
+			log.Printf("判断用户%d的徽章失败: %v", userID, err)
+		}
+	}
+}
+
+// Backfill 按现有数据为用户补发徽章
+func (s *badgeService) Backfill(ctx context.Context, userIDs []uint64, now time.Time) (int, error) {
+	if len(userIDs) == 0 {
+		ids, err := s.userRepo.FindAllIDs(ctx)
+		if err != nil {
+			return 0, err
+		}
+		userIDs = ids
+	}
+
+	total, failed := 0, 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		awarded, err := s.evaluate(ctx, newBadgeFacts(userID), now, false, func(entity.BadgeRule) bool { return true })
+		total += awarded
+		if err != nil {
+			log.Printf("补发用户%d的徽章失败: %v", userID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return total, fmt.Errorf("%d个用户的徽章补发失败", failed)
+	}
+	return total, nil
+}
+
+// badgeFacts 判断徽章时用到的用户数据，按需加载且只加载一次
+type badgeFacts struct {
+	userID  uint64
+	records []*entity.Record
+	friends []uint64
+	loaded  map[string]bool
+
+	// syncFrom、syncTo 判断与好友同分钟记录时只查找用户在[syncFrom, syncTo)内的记录，为空时查找全部记录
+	syncFrom, syncTo time.Time
+	// syncWith 判断与好友同分钟记录时只查找这些好友，为空时查找全部好友
+	syncWith []uint64
+}
+
+// newBadgeFacts 创建用户的徽章判断数据
+func newBadgeFacts(userID uint64) *badgeFacts {
+	return &badgeFacts{userID: userID, loaded: make(map[string]bool)}
+}
+
+// evaluate 判断用户尚未获得且符合filter的徽章，满足条件的立即发放，返回发放的徽章数
+// announce为true时发送通知并发布事件；与好友同分钟记录的徽章同时发给对应的好友
+func (s *badgeService) evaluate(ctx context.Context, facts *badgeFacts, now time.Time, announce bool, filter func(entity.BadgeRule) bool) (int, error) {
+	userID := facts.userID
+	owned, err := s.badgeRepo.FindCodesByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	awarded := 0
+	for _, badge := range entity.BadgeCatalog {
+		if !filter(badge.Rule) {
+			continue
+		}
+
+		if badge.Rule.Kind == entity.BadgeRuleFriendSync {
+			// 已获得时只有新的好友可能因此获得，其他好友在自己记录时会检查到该用户
+			if owned[badge.Code] && len(facts.syncWith) == 0 {
+				continue
+			}
+			count, err := s.checkFriendSync(ctx, facts, badge, owned[badge.Code], now, announce)
+			awarded += count
+			if err != nil {
+				return awarded, err
+			}
+			continue
+		}
+		if owned[badge.Code] {
+			continue
+		}
+
+		metAt, err := s.check(ctx, facts, badge.Rule, now)
+		if err != nil {
+			return awarded, err
+		}
+		if metAt == nil {
+			continue
+		}
+		ok, err := s.award(ctx, userID, badge, *metAt, announce)
+		if err != nil {
+			return awarded, err
+		}
+		if ok {
+			awarded++
+		}
+	}
+	return awarded, nil
+}
+
+// check 判断规则是否满足，满足时返回达成的时间，无法确定达成时间时使用now
+func (s *badgeService) check(ctx context.Context, facts *badgeFacts, rule entity.BadgeRule, now time.Time) (*time.Time, error) {
+	switch rule.Kind {
+	case entity.BadgeRuleRecordCount:
+		records, err := s.loadRecords(ctx, facts)
+		if err != nil || len(records) < rule.Threshold || rule.Threshold < 1 {
+			return nil, err
+		}
+		return &records[rule.Threshold-1].RecordTime, nil
+
+	case entity.BadgeRuleStreak:
+		records, err := s.loadRecords(ctx, facts)
+		if err != nil {
+			return nil, err
+		}
+		return streakReachedAt(records, rule.Threshold), nil
+
+	case entity.BadgeRulePerfectWeek:
+		records, err := s.loadRecords(ctx, facts)
+		if err != nil {
+			return nil, err
+		}
+		return perfectWeekAt(records, rule.PoopTypeID, now), nil
+
+	case entity.BadgeRuleHourRange:
+		records, err := s.loadRecords(ctx, facts)
+		if err != nil {
+			return nil, err
+		}
+		count := 0
+		for _, record := range records {
+			hour := record.RecordTime.In(shanghaiLocation).Hour()
+			if hour >= rule.HourFrom && hour < rule.HourTo {
+				count++
+				if count >= rule.Threshold {
+					return &record.RecordTime, nil
+				}
+			}
+		}
+		return nil, nil
+
+	case entity.BadgeRuleFriendCount:
+		friends, err := s.loadFriends(ctx, facts)
+		if err != nil || len(friends) < rule.Threshold {
+			return nil, err
+		}
+		return &now, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的徽章规则: %s", rule.Kind)
+	}
+}
+
+// checkFriendSync 查找与用户在同一分钟内记录过的好友，为用户和这些好友发放徽章
+func (s *badgeService) checkFriendSync(ctx context.Context, facts *badgeFacts, badge *entity.Badge, owned bool, now time.Time, announce bool) (int, error) {
+	friends := facts.syncWith
+	if len(friends) == 0 {
+		var err error
+		if friends, err = s.loadFriends(ctx, facts); err != nil || len(friends) == 0 {
+			return 0, err
+		}
+	}
+
+	from, to := facts.syncFrom, facts.syncTo
+	if to.IsZero() {
+		// 没有指定范围时查找用户的全部记录
+		records, err := s.loadRecords(ctx, facts)
+		if err != nil || len(records) == 0 {
+			return 0, err
+		}
+		from = records[0].RecordTime
+		to = records[len(records)-1].RecordTime.Add(time.Second)
+	}
+	synced, err := s.recordRepo.FindSameMinuteUserIDs(ctx, facts.userID, friends, from, to)
+	if err != nil || len(synced) == 0 {
+		return 0, err
+	}
+
+	awarded := 0
+	recipients := synced
+	if !owned {
+		recipients = append([]uint64{facts.userID}, synced...)
+	}
+	for _, userID := range recipients {
+		ok, err := s.award(ctx, userID, badge, now, announce)
+		if err != nil {
+			return awarded, err
+		}
+		if ok {
+			awarded++
+		}
+	}
+	return awarded, nil
+}
+
+// award 发放徽章，已获得过时不重复发放也不重复通知
+func (s *badgeService) award(ctx context.Context, userID uint64, badge *entity.Badge, awardedAt time.Time, announce bool) (bool, error) {
+	userBadge := &entity.UserBadge{
+		UserID:    userID,
+		BadgeCode: badge.Code,
+		AwardedAt: awardedAt,
+		Badge:     badge,
+	}
+	created, err := s.badgeRepo.Award(ctx, userBadge)
+	if err != nil || !created || !announce {
+		return created, err
+	}
+
+	// 徽章已保存，通知失败时只记录日志
+	notification := &entity.Notification{
+		UserID:  userID,
+		Type:    entity.NotificationTypeBadge,
+		Content: fmt.Sprintf("恭喜获得徽章「%s」：%s", badge.Name, badge.Description),
+		RefID:   userBadge.ID,
+	}
+	if err := s.notificationService.Send(ctx, notification); err != nil {
+		log.Printf("发送徽章%d的通知失败: %v", userBadge.ID, err)
+	}
+	s.publisher.Publish(ctx, &event.BadgeAwardedEvent{Badge: userBadge})
+	return true, nil
+}
+
+// loadRecords 加载用户计入统计的全部记录
+func (s *badgeService) loadRecords(ctx context.Context, facts *badgeFacts) ([]*entity.Record, error) {
+	if !facts.loaded["records"] {
+		records, err := s.recordRepo.FindAllCounted(ctx, facts.userID)
+		if err != nil {
+			return nil, err
+		}
+		facts.records = records
+		facts.loaded["records"] = true
+	}
+	return facts.records, nil
+}
+
+// loadFriends 加载用户的好友ID
+func (s *badgeService) loadFriends(ctx context.Context, facts *badgeFacts) ([]uint64, error) {
+	if !facts.loaded["friends"] {
+		friends, err := s.friendRepo.FindFriendIDs(ctx, facts.userID)
+		if err != nil {
+			return nil, err
+		}
+		facts.friends = friends
+		facts.loaded["friends"] = true
+	}
+	return facts.friends, nil
+}
+
+// streakReachedAt 返回连续记录天数第一次达到days的那一天的首条记录时间，记录需按时间升序
+func streakReachedAt(records []*entity.Record, days int) *time.Time {
+	var prev time.Time
+	streak := 0
+	for _, record := range records {
+		day := startOfDay(record.RecordTime)
+		if streak > 0 && day.Equal(prev) {
+			continue
+		}
+		if streak > 0 && isNextDay(prev, day) {
+			streak++
+		} else {
+			streak = 1
+		}
+		prev = day
+		if streak >= days {
+			return &record.RecordTime
+		}
+	}
+	return nil
+}
+
+// perfectWeekAt 返回第一个已结束的完美自然周的最后一条记录时间
+// 完美周要求周一至周日每天都有记录，且这一周的记录全部为指定类型
+func perfectWeekAt(records []*entity.Record, poopTypeID uint64, now time.Time) *time.Time {
+	var (
+		weekStart time.Time
+		days      map[string]bool
+		perfect   bool
+		last      *entity.Record
+	)
+	finish := func() *time.Time {
+		if last != nil && perfect && len(days) == 7 && !now.Before(windowEnd(windowWeek, weekStart)) {
+			return &last.RecordTime
+		}
+		return nil
+	}
+
+	for _, record := range records {
+		start := windowStart(windowWeek, record.RecordTime)
+		if last == nil || !start.Equal(weekStart) {
+			if metAt := finish(); metAt != nil {
+				return metAt
+			}
+			weekStart, days, perfect = start, make(map[string]bool), true
+		}
+		days[startOfDay(record.RecordTime).Format("2006-01-02")] = true
+		perfect = perfect && record.PoopTypeID == poopTypeID
+		last = record
+	}
+	return finish()
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"record-project/domain/entity"
+)
+
+// badgeTestMonday 测试使用的周一零点（东八区）
+var badgeTestMonday = time.Date(2024, 5, 20, 0, 0, 0, 0, shanghaiLocation)
+
+// badgeRecord 创建相对badgeTestMonday的记录，offset为距周一零点的时长
+func badgeRecord(offset time.Duration, poopTypeID uint64) *entity.Record {
+	return &entity.Record{UserID: 1, RecordTime: badgeTestMonday.Add(offset), PoopTypeID: poopTypeID}
+}
+
+// badgeDay 第day天（从0开始）的hour点
+func badgeDay(day, hour int) time.Duration {
+	return time.Duration(day)*24*time.Hour + time.Duration(hour)*time.Hour
+}
+
+// badgeWeek 从第startDay天起连续7天每天一条指定类型的记录
+func badgeWeek(startDay int, poopTypeID uint64) []*entity.Record {
+	records := make([]*entity.Record, 7)
+	for i := range records {
+		records[i] = badgeRecord(badgeDay(startDay+i, 9), poopTypeID)
+	}
+	return records
+}
+
+func TestStreakReachedAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		offsets []time.Duration
+		days    int
+		want    *time.Duration
+	}{
+		{
+			name: "没有记录",
+			days: 1,
+		},
+		{
+			name:    "一天即可达成时返回第一条记录",
+			offsets: []time.Duration{badgeDay(0, 9), badgeDay(0, 20)},
+			days:    1,
+			want:    durationPtr(badgeDay(0, 9)),
+		},
+		{
+			name:    "连续天数达到时返回当天的第一条记录",
+			offsets: []time.Duration{badgeDay(0, 9), badgeDay(1, 9), badgeDay(2, 8), badgeDay(2, 21)},
+			days:    3,
+			want:    durationPtr(badgeDay(2, 8)),
+		},
+		{
+			name:    "同一天多条记录只算一天",
+			offsets: []time.Duration{badgeDay(0, 9), badgeDay(0, 12), badgeDay(0, 18), badgeDay(1, 9)},
+			days:    3,
+		},
+		{
+			name:    "中断后重新计算",
+			offsets: []time.Duration{badgeDay(0, 9), badgeDay(1, 9), badgeDay(3, 9), badgeDay(4, 9), badgeDay(5, 9)},
+			days:    3,
+			want:    durationPtr(badgeDay(5, 9)),
+		},
+		{
+			name:    "按东八区的自然日计算",
+			offsets: []time.Duration{badgeDay(0, 23) + 30*time.Minute, badgeDay(1, 0) + 10*time.Minute},
+			days:    2,
+			want:    durationPtr(badgeDay(1, 0) + 10*time.Minute),
+		},
+		{
+			name:    "天数不足",
+			offsets: []time.Duration{badgeDay(0, 9), badgeDay(1, 9)},
+			days:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := make([]*entity.Record, len(tt.offsets))
+			for i, offset := range tt.offsets {
+				records[i] = badgeRecord(offset, 4)
+			}
+			assertBadgeTime(t, streakReachedAt(records, tt.days), tt.want)
+		})
+	}
+}
+
+func TestPerfectWeekAt(t *testing.T) {
+	const poopTypeID = 4
+	afterWeek := badgeTestMonday.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name    string
+		records []*entity.Record
+		now     time.Time
+		want    *time.Duration
+	}{
+		{
+			name:    "整周每天都是指定类型",
+			records: badgeWeek(0, poopTypeID),
+			now:     afterWeek,
+			want:    durationPtr(badgeDay(6, 9)),
+		},
+		{
+			name:    "本周尚未结束",
+			records: badgeWeek(0, poopTypeID),
+			now:     afterWeek.Add(-time.Minute),
+		},
+		{
+			name:    "缺少一天",
+			records: badgeWeek(0, poopTypeID)[:6],
+			now:     afterWeek,
+		},
+		{
+			name:    "有一条其他类型的记录",
+			records: append(badgeWeek(0, poopTypeID), badgeRecord(badgeDay(6, 21), 6)),
+			now:     afterWeek,
+		},
+		{
+			name:    "连续7天但跨了两个自然周",
+			records: badgeWeek(2, poopTypeID),
+			now:     afterWeek.AddDate(0, 0, 7),
+		},
+		{
+			name:    "第一周不完美时返回之后的完美周",
+			records: append(append(badgeWeek(0, poopTypeID)[:5], badgeRecord(badgeDay(5, 9), 6)), badgeWeek(7, poopTypeID)...),
+			now:     afterWeek.AddDate(0, 0, 7),
+			want:    durationPtr(badgeDay(13, 9)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertBadgeTime(t, perfectWeekAt(tt.records, poopTypeID, tt.now), tt.want)
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+// assertBadgeTime 比较达成时间，want为距badgeTestMonday的时长，nil表示未达成
+func assertBadgeTime(t *testing.T, got *time.Time, want *time.Duration) {
+	t.Helper()
+	switch {
+	case want == nil && got != nil:
+		t.Fatalf("got %v, want nil", *got)
+	case want != nil && got == nil:
+		t.Fatalf("got nil, want %v", badgeTestMonday.Add(*want))
+	case want != nil && !got.Equal(badgeTestMonday.Add(*want)):
+		t.Fatalf("got %v, want %v", *got, badgeTestMonday.Add(*want))
+	}
+}
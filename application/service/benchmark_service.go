@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// minBenchmarkCohortSize 对比群体的最小人数（不含自己），不足时不返回聚合结果，避免反推出个人数据
+	minBenchmarkCohortSize = 10
+	// benchmarkPercentileStep 百分位的取整粒度
+	benchmarkPercentileStep = 5.0
+	// benchmarkCohortTTL 全体用户分布的缓存时间
+	benchmarkCohortTTL = 10 * time.Minute
+)
+
+// benchmarkMetrics 参与对比的指标
+var benchmarkMetrics = []string{
+	entity.BenchmarkMetricFrequency,
+	entity.BenchmarkMetricAvgDuration,
+	entity.BenchmarkMetricHealthyShare,
+}
+
+// benchmarkMedianSteps 各指标中位数的取整粒度，只返回区间值，避免与百分位组合反推出个人的精确值
+var benchmarkMedianSteps = map[string]float64{
+	entity.BenchmarkMetricFrequency:    0.1,
+	entity.BenchmarkMetricAvgDuration:  30,
+	entity.BenchmarkMetricHealthyShare: 0.05,
+}
+
+// BenchmarkService 匿名百分位对比服务接口
+type BenchmarkService interface {
+	// GetBenchmarks 获取用户最近days天的各项指标在好友和全体用户中的百分位
+	GetBenchmarks(ctx context.Context, userID uint64, days int, now time.Time) (*entity.BenchmarkReport, error)
+}
+
+// benchmarkService 匿名百分位对比服务实现
+type benchmarkService struct {
+	recordRepo repository.RecordRepository
+	friendRepo repository.FriendRepository
+	userRepo   repository.UserRepository
+
+	// globalCohorts 按统计天数和开始日期缓存的全体用户分布，避免每次请求都汇总全部用户的记录
+	globalCohorts map[string]*benchmarkDistribution
+	mu            sync.Mutex
+}
+
+// benchmarkDistribution 全体用户在一个统计周期内的指标分布
+type benchmarkDistribution struct {
+	// values 各指标的全部可计算值，升序排列
+	values map[string][]float64
+	// userValues 各用户计入分布的指标值，计算百分位时用于从分布中去掉自己
+	userValues map[uint64]map[string]float64
+	expireAt   time.Time
+}
+
+// NewBenchmarkService 创建匿名百分位对比服务
+func NewBenchmarkService(
+	recordRepo repository.RecordRepository,
+	friendRepo repository.FriendRepository,
+	userRepo repository.UserRepository,
+) BenchmarkService {
+	return &benchmarkService{
+		recordRepo:    recordRepo,
+		friendRepo:    friendRepo,
+		userRepo:      userRepo,
+		globalCohorts: make(map[string]*benchmarkDistribution),
+	}
+}
+
+// GetBenchmarks 获取用户最近days天的各项指标在好友和全体用户中的百分位
+func (s *benchmarkService) GetBenchmarks(ctx context.Context, userID uint64, days int, now time.Time) (*entity.BenchmarkReport, error) {
+	start := startOfDay(now).AddDate(0, 0, -days+1)
+	end := now
+
+	global, err := s.globalDistribution(ctx, days, start, end, now)
+	if err != nil {
+		return nil, err
+	}
+
+	friendIDs, err := s.friendRepo.FindFriendIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// 对比群体都不包含自己
+	friendCohort := excludeBenchmarkUser(friendIDs, userID)
+
+	// 好友群体统计好友可见和公开的记录，全体用户只统计公开的记录，自己的指标值统计全部记录
+	friendStats := make(map[uint64]*entity.UserRecordStats, len(friendCohort))
+	if len(friendCohort) > 0 {
+		stats, err := s.recordRepo.GetUserRecordStats(ctx, friendCohort, entity.RecordVisibilitiesFriends, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range stats {
+			friendStats[stat.UserID] = stat
+		}
+	}
+	selfStats, err := s.recordRepo.GetUserRecordStats(ctx, []uint64{userID}, nil, start, end)
+	if err != nil {
+		return nil, err
+	}
+	var selfStat *entity.UserRecordStats
+	if len(selfStats) > 0 {
+		selfStat = selfStats[0]
+	}
+
+	report := &entity.BenchmarkReport{
+		UserID: userID,
+		Start:  start,
+		End:    end,
+	}
+
+	for _, metric := range benchmarkMetrics {
+		self := benchmarkValue(metric, selfStat, days)
+
+		friendValues := collectBenchmarkValues(metric, friendCohort, friendStats, days)
+		sort.Float64s(friendValues)
+
+		var excluded *float64
+		if value, ok := global.userValues[userID][metric]; ok {
+			excluded = &value
+		}
+
+		report.Metrics = append(report.Metrics, &entity.BenchmarkMetric{
+			Metric:  metric,
+			Value:   self,
+			Friends: summarizeCohort(metric, friendValues, nil, self),
+			Global:  summarizeCohort(metric, global.values[metric], excluded, self),
+		})
+	}
+
+	return report, nil
+}
+
+// globalDistribution 获取全体用户的指标分布，缓存过期后重新汇总
+func (s *benchmarkService) globalDistribution(ctx context.Context, days int, start, end, now time.Time) (*benchmarkDistribution, error) {
+	key := fmt.Sprintf("%d:%s", days, start.Format("2006-01-02"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if distribution, ok := s.globalCohorts[key]; ok && now.Before(distribution.expireAt) {
+		return distribution, nil
+	}
+
+	allIDs, err := s.userRepo.FindAllIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.recordRepo.GetUserRecordStats(ctx, nil, entity.RecordVisibilitiesPublic, start, end)
+	if err != nil {
+		return nil, err
+	}
+	statsMap := make(map[uint64]*entity.UserRecordStats, len(stats))
+	for _, stat := range stats {
+		statsMap[stat.UserID] = stat
+	}
+
+	distribution := &benchmarkDistribution{
+		values:     make(map[string][]float64, len(benchmarkMetrics)),
+		userValues: make(map[uint64]map[string]float64, len(allIDs)),
+		expireAt:   now.Add(benchmarkCohortTTL),
+	}
+	for _, id := range excludeBenchmarkUser(allIDs, 0) {
+		for _, metric := range benchmarkMetrics {
+			value := benchmarkValue(metric, statsMap[id], days)
+			if value == nil {
+				continue
+			}
+			distribution.values[metric] = append(distribution.values[metric], *value)
+			if distribution.userValues[id] == nil {
+				distribution.userValues[id] = make(map[string]float64, len(benchmarkMetrics))
+			}
+			distribution.userValues[id][metric] = *value
+		}
+	}
+	for _, values := range distribution.values {
+		sort.Float64s(values)
+	}
+
+	// 清理过期的分布，统计天数的取值有限，缓存不会无限增长
+	for k, cached := range s.globalCohorts {
+		if !now.Before(cached.expireAt) {
+			delete(s.globalCohorts, k)
+		}
+	}
+	s.globalCohorts[key] = distribution
+	return distribution, nil
+}
+
+// benchmarkValue 计算单个用户的指标值，无法计算时返回nil
+func benchmarkValue(metric string, stat *entity.UserRecordStats, days int) *float64 {
+	var value float64
+	switch metric {
+	case entity.BenchmarkMetricFrequency:
+		// 没有记录的用户日均次数为0，同样参与对比
+		if stat != nil {
+			value = float64(stat.RecordCount) / float64(days)
+		}
+	case entity.BenchmarkMetricAvgDuration:
+		if stat == nil || stat.RecordCount == 0 {
+			return nil
+		}
+		value = float64(stat.TotalDuration) / float64(stat.RecordCount)
+	case entity.BenchmarkMetricHealthyShare:
+		if stat == nil || stat.RecordCount == 0 {
+			return nil
+		}
+		value = float64(stat.HealthyCount) / float64(stat.RecordCount)
+	default:
+		return nil
+	}
+	return &value
+}
+
+// excludeBenchmarkUser 去除重复的用户和自己
+func excludeBenchmarkUser(userIDs []uint64, userID uint64) []uint64 {
+	cohort := make([]uint64, 0, len(userIDs))
+	seen := map[uint64]bool{userID: true}
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			cohort = append(cohort, id)
+		}
+	}
+	return cohort
+}
+
+// collectBenchmarkValues 收集群体中所有可计算的指标值
+func collectBenchmarkValues(metric string, userIDs []uint64, statsMap map[uint64]*entity.UserRecordStats, days int) []float64 {
+	values := make([]float64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if value := benchmarkValue(metric, statsMap[id], days); value != nil {
+			values = append(values, *value)
+		}
+	}
+	return values
+}
+
+// summarizeCohort 计算用户在群体（不含自己）中的百分位和群体中位数，两者都按粒度取整
+// sorted为升序排列的群体指标值，excluded不为空时表示sorted中包含自己的这个值，计算时将其去掉
+func summarizeCohort(metric string, sorted []float64, excluded *float64, self *float64) *entity.BenchmarkCohort {
+	size := len(sorted)
+	removed := size
+	if excluded != nil {
+		size--
+		removed = sort.SearchFloat64s(sorted, *excluded)
+	}
+	if self == nil || size < minBenchmarkCohortSize {
+		return &entity.BenchmarkCohort{Available: false}
+	}
+
+	// 百分位 = 低于自己的比例 + 与自己相同的一半
+	below := sort.SearchFloat64s(sorted, *self)
+	equal := sort.Search(len(sorted), func(i int) bool { return sorted[i] > *self }) - below
+	if excluded != nil {
+		if *excluded < *self {
+			below--
+		} else if *excluded == *self {
+			equal--
+		}
+	}
+	percentile := roundToStep((float64(below)+float64(equal)/2)/float64(size)*100, benchmarkPercentileStep)
+
+	// 去掉自己之后第i个值
+	at := func(i int) float64 {
+		if i >= removed {
+			return sorted[i+1]
+		}
+		return sorted[i]
+	}
+	median := at(size / 2)
+	if size%2 == 0 {
+		median = (at(size/2-1) + at(size/2)) / 2
+	}
+	median = roundToStep(median, benchmarkMedianSteps[metric])
+
+	return &entity.BenchmarkCohort{
+		Available:  true,
+		CohortSize: size,
+		Percentile: &percentile,
+		Median:     &median,
+	}
+}
+
+// roundTo 四舍五入到指定小数位
+func roundTo(value float64, digits int) float64 {
+	pow := math.Pow(10, float64(digits))
+	return math.Round(value*pow) / pow
+}
+
+// roundToStep 四舍五入到step的整数倍
+func roundToStep(value, step float64) float64 {
+	return roundTo(math.Round(value/step)*step, 2)
+}
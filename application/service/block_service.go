@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+)
+
+var (
+	// ErrBlockUserNotFound 要屏蔽的用户不存在
+	ErrBlockUserNotFound = errors.New("用户不存在")
+	// ErrBlockNotFound 没有屏蔽该用户
+	ErrBlockNotFound = errors.New("未屏蔽该用户")
+)
+
+// BlockService 屏蔽服务接口
+type BlockService interface {
+	// BlockUser 屏蔽用户，屏蔽后双方的好友关系解除，且都不能再向对方发送好友申请
+	BlockUser(ctx context.Context, userID, targetID uint64) (*entity.Block, error)
+
+	// UnblockUser 解除屏蔽，不会恢复之前的好友关系
+	UnblockUser(ctx context.Context, userID, targetID uint64) error
+
+	// GetBlockedUsers 分页获取用户屏蔽的人
+	GetBlockedUsers(ctx context.Context, userID uint64, page, size int) ([]*entity.Block, int64, error)
+
+	// IsBlocked 判断两个用户之间是否有任意一方屏蔽了另一方
+	IsBlocked(ctx context.Context, userID, otherID uint64) (bool, error)
+}
+
+// blockService 屏蔽服务实现
+type blockService struct {
+	blockRepo repository.BlockRepository
+	userRepo  repository.UserRepository
+}
+
+// NewBlockService 创建屏蔽服务
+func NewBlockService(blockRepo repository.BlockRepository, userRepo repository.UserRepository) BlockService {
+	return &blockService{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// BlockUser 屏蔽用户
+func (s *blockService) BlockUser(ctx context.Context, userID, targetID uint64) (*entity.Block, error) {
+	if userID == targetID {
+		return nil, errors.New("不能屏蔽自己")
+	}
+
+	target, err := s.userRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrBlockUserNotFound
+	}
+
+	block := &entity.Block{UserID: userID, BlockedID: targetID}
+	if err := s.blockRepo.Save(ctx, block); err != nil {
+		return nil, err
+	}
+	block.BlockedUser = target
+	return block, nil
+}
+
+// UnblockUser 解除屏蔽
+func (s *blockService) UnblockUser(ctx context.Context, userID, targetID uint64) error {
+	deleted, err := s.blockRepo.Delete(ctx, userID, targetID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrBlockNotFound
+	}
+	return nil
+}
+
+// GetBlockedUsers 分页获取用户屏蔽的人
+func (s *blockService) GetBlockedUsers(ctx context.Context, userID uint64, page, size int) ([]*entity.Block, int64, error) {
+	return s.blockRepo.FindByUserID(ctx, userID, page, size)
+}
+
+// IsBlocked 判断两个用户之间是否有任意一方屏蔽了另一方
+func (s *blockService) IsBlocked(ctx context.Context, userID, otherID uint64) (bool, error) {
+	return s.blockRepo.IsBlocked(ctx, userID, otherID)
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// challengeStartTolerance 开始时间早于当前时间不超过该值时按当前时间开始，兼容客户端时钟误差
+const challengeStartTolerance = 5 * time.Minute
+
+var (
+	// ErrChallengeNotFound 挑战不存在，或查看者不是参与者
+	ErrChallengeNotFound = errors.New("挑战不存在")
+	// ErrChallengeTitleInvalid 挑战标题为空或过长
+	ErrChallengeTitleInvalid = errors.New("挑战标题不能为空且不能超过20个字")
+	// ErrChallengeGoalInvalid 不支持的挑战目标
+	ErrChallengeGoalInvalid = errors.New("无效的挑战目标")
+	// ErrChallengeTimeInvalid 开始或结束时间不符合要求
+	ErrChallengeTimeInvalid = errors.New("挑战时间无效：不能早于当前时间，最多提前30天创建，持续1小时到31天")
+	// ErrChallengeParticipantsInvalid 没有邀请任何人或人数超过上限
+	ErrChallengeParticipantsInvalid = errors.New("至少邀请1位好友，参与人数不能超过20人")
+	// ErrChallengeInviteeNotFriend 只能邀请好友参加挑战
+	ErrChallengeInviteeNotFriend = errors.New("只能邀请好友参加挑战")
+	// ErrChallengeEnded 挑战已结束
+	ErrChallengeEnded = errors.New("挑战已结束")
+	// ErrChallengeNotInvited 没有待回应的邀请
+	ErrChallengeNotInvited = errors.New("没有待回应的挑战邀请")
+)
+
+// ChallengeService 好友挑战服务接口
+type ChallengeService interface {
+	// CreateChallenge 创建挑战并邀请好友，创建者自动参加
+	CreateChallenge(ctx context.Context, creatorID uint64, title, goal string, startAt, endAt time.Time, inviteeIDs []uint64, now time.Time) (*entity.Challenge, error)
+
+	// GetChallenges 分页获取用户的挑战，history为false时返回未结算的挑战和待回应的邀请，为true时返回已结算的挑战
+	GetChallenges(ctx context.Context, userID uint64, history bool, page, size int) ([]*entity.Challenge, int64, error)
+
+	// GetChallenge 获取挑战详情，进行中时返回实时排名，结算后返回最终排名
+	GetChallenge(ctx context.Context, viewerID, challengeID uint64) (*entity.ChallengeDetail, error)
+
+	// Respond 接受或拒绝挑战邀请，挑战结束后不能再回应
+	Respond(ctx context.Context, userID, challengeID uint64, accept bool, now time.Time) error
+
+	// SettleDueChallenges 结算已结束的挑战并通知参与者获胜结果
+	SettleDueChallenges(ctx context.Context, now time.Time) error
+}
+
+// challengeService 好友挑战服务实现
+type challengeService struct {
+	challengeRepo       repository.ChallengeRepository
+	recordRepo          repository.RecordRepository
+	friendRepo          repository.FriendRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+}
+
+// NewChallengeService 创建好友挑战服务
+func NewChallengeService(
+	challengeRepo repository.ChallengeRepository,
+	recordRepo repository.RecordRepository,
+	friendRepo repository.FriendRepository,
+	userRepo repository.UserRepository,
+	notificationService NotificationService,
+) ChallengeService {
+	return &challengeService{
+		challengeRepo:       challengeRepo,
+		recordRepo:          recordRepo,
+		friendRepo:          friendRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+// CreateChallenge 创建挑战并邀请好友
+func (s *challengeService) CreateChallenge(ctx context.Context, creatorID uint64, title, goal string, startAt, endAt time.Time, inviteeIDs []uint64, now time.Time) (*entity.Challenge, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > entity.ChallengeTitleMaxLength {
+		return nil, ErrChallengeTitleInvalid
+	}
+	if !entity.IsValidChallengeGoal(goal) {
+		return nil, ErrChallengeGoalInvalid
+	}
+
+	if startAt.IsZero() || (startAt.Before(now) && now.Sub(startAt) <= challengeStartTolerance) {
+		startAt = now
+	}
+	duration := endAt.Sub(startAt)
+	if startAt.Before(now) || startAt.Sub(now) > entity.ChallengeMaxStartInFuture ||
+		duration < entity.ChallengeMinDuration || duration > entity.ChallengeMaxDuration {
+		return nil, ErrChallengeTimeInvalid
+	}
+
+	// 去重并排除创建者自己
+	invitees := make([]uint64, 0, len(inviteeIDs))
+	seen := map[uint64]bool{creatorID: true}
+	for _, id := range inviteeIDs {
+		if !seen[id] {
+			seen[id] = true
+			invitees = append(invitees, id)
+		}
+	}
+	if len(invitees) == 0 || len(invitees)+1 > entity.ChallengeMaxParticipants {
+		return nil, ErrChallengeParticipantsInvalid
+	}
+
+	friendIDs, err := s.friendRepo.FindFriendIDs(ctx, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	friends := make(map[uint64]bool, len(friendIDs))
+	for _, id := range friendIDs {
+		friends[id] = true
+	}
+	for _, id := range invitees {
+		if !friends[id] {
+			return nil, ErrChallengeInviteeNotFriend
+		}
+	}
+
+	challenge := &entity.Challenge{
+		CreatorID: creatorID,
+		Title:     title,
+		Goal:      goal,
+		StartAt:   startAt,
+		EndAt:     endAt,
+		Status:    entity.ChallengeStatusActive,
+	}
+	respondedAt := now
+	participants := []*entity.ChallengeParticipant{
+		{UserID: creatorID, Status: entity.ChallengeParticipantJoined, RespondedAt: &respondedAt},
+	}
+	for _, id := range invitees {
+		participants = append(participants, &entity.ChallengeParticipant{UserID: id, Status: entity.ChallengeParticipantInvited})
+	}
+	if err := s.challengeRepo.Create(ctx, challenge, participants); err != nil {
+		return nil, err
+	}
+
+	// 挑战已保存，邀请通知失败时只记录日志，被邀请人仍可在挑战列表中看到邀请
+	nickname := "你的好友"
+	creator, err := s.userRepo.FindByID(ctx, creatorID)
+	if err != nil {
+		log.Printf("查询挑战%d的创建者失败: %v", challenge.ID, err)
+	} else if creator != nil && creator.Nickname != "" {
+		nickname = creator.Nickname
+	}
+	for _, id := range invitees {
+		notification := &entity.Notification{
+			UserID:  id,
+			ActorID: creatorID,
+			Type:    entity.NotificationTypeChallengeInvite,
+			Content: fmt.Sprintf("%s邀请你参加挑战「%s」", nickname, title),
+			RefID:   challenge.ID,
+		}
+		if err := s.notificationService.Send(ctx, notification); err != nil {
+			log.Printf("发送挑战%d的邀请通知失败: %v", challenge.ID, err)
+		}
+	}
+
+	challenge.MyStatus = entity.ChallengeParticipantJoined
+	return challenge, nil
+}
+
+// GetChallenges 分页获取用户的挑战
+func (s *challengeService) GetChallenges(ctx context.Context, userID uint64, history bool, page, size int) ([]*entity.Challenge, int64, error) {
+	return s.challengeRepo.FindByParticipant(ctx, userID, history, page, size)
+}
+
+// GetChallenge 获取挑战详情，参与者（含待回应和已拒绝的）均可查看
+func (s *challengeService) GetChallenge(ctx context.Context, viewerID, challengeID uint64) (*entity.ChallengeDetail, error) {
+	challenge, viewer, err := s.findAsParticipant(ctx, viewerID, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	challenge.MyStatus = viewer.Status
+	challenge.MyFinalRank = viewer.FinalRank
+
+	participants, err := s.challengeRepo.FindParticipants(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint64, len(participants))
+	for i, participant := range participants {
+		userIDs[i] = participant.UserID
+	}
+	users, err := s.userRepo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint64]*entity.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	for _, participant := range participants {
+		participant.User = userMap[participant.UserID]
+	}
+
+	var standings []*entity.RankingItem
+	if challenge.Status == entity.ChallengeStatusSettled {
+		standings = settledStandings(challenge, participants)
+	} else {
+		standings, err = s.liveStandings(ctx, challenge, participants)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range standings {
+		if user, exists := userMap[item.UserID]; exists {
+			item.Nickname = user.Nickname
+			item.AvatarURL = user.AvatarURL
+		}
+	}
+
+	return &entity.ChallengeDetail{
+		Challenge:    challenge,
+		Participants: participants,
+		Standings:    standings,
+	}, nil
+}
+
+// Respond 接受或拒绝挑战邀请
+func (s *challengeService) Respond(ctx context.Context, userID, challengeID uint64, accept bool, now time.Time) error {
+	challenge, participant, err := s.findAsParticipant(ctx, userID, challengeID)
+	if err != nil {
+		return err
+	}
+	if challenge.Status != entity.ChallengeStatusActive || challenge.Ended(now) {
+		return ErrChallengeEnded
+	}
+	if participant.Status != entity.ChallengeParticipantInvited {
+		return ErrChallengeNotInvited
+	}
+
+	status := entity.ChallengeParticipantDeclined
+	if accept {
+		status = entity.ChallengeParticipantJoined
+	}
+	updated, err := s.challengeRepo.Respond(ctx, challengeID, userID, status, now)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrChallengeNotInvited
+	}
+	return nil
+}
+
+// SettleDueChallenges 结算已结束的挑战
+// 已参加的用户按挑战目标排名，并列第一且成绩大于0的用户均为获胜者；未回应的邀请不参与结算
+func (s *challengeService) SettleDueChallenges(ctx context.Context, now time.Time) error {
+	due, err := s.challengeRepo.FindDueActive(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, challenge := range due {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.settle(ctx, challenge, now); err != nil {
+			log.Printf("结算挑战%d失败: %v", challenge.ID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d个挑战结算失败", failed)
+	}
+	return nil
+}
+
+// settle 计算最终排名并保存，结算成功后通知已参加的用户
+func (s *challengeService) settle(ctx context.Context, challenge *entity.Challenge, now time.Time) error {
+	participants, err := s.challengeRepo.FindParticipants(ctx, challenge.ID)
+	if err != nil {
+		return err
+	}
+	standings, err := s.liveStandings(ctx, challenge, participants)
+	if err != nil {
+		return err
+	}
+
+	byUser := make(map[uint64]*entity.ChallengeParticipant, len(participants))
+	for _, participant := range participants {
+		byUser[participant.UserID] = participant
+	}
+	var joined, winners []*entity.ChallengeParticipant
+	for _, item := range standings {
+		participant, ok := byUser[item.UserID]
+		if !ok {
+			continue
+		}
+		participant.FinalRank = item.Rank
+		participant.Score = item.MetricValue
+		participant.Winner = item.Rank == 1 && item.MetricValue > 0
+		joined = append(joined, participant)
+		if participant.Winner {
+			winners = append(winners, participant)
+		}
+	}
+
+	settledAt := now
+	challenge.Status = entity.ChallengeStatusSettled
+	challenge.SettledAt = &settledAt
+	settled, err := s.challengeRepo.Settle(ctx, challenge, joined)
+	if err != nil || !settled {
+		// 未更新时说明其他实例已完成结算并发送了通知
+		return err
+	}
+
+	content := fmt.Sprintf("挑战「%s」已结束，没有人获胜", challenge.Title)
+	if len(winners) > 0 {
+		winnerIDs := make([]uint64, len(winners))
+		for i, winner := range winners {
+			winnerIDs[i] = winner.UserID
+		}
+		users, err := s.userRepo.FindByIDs(ctx, winnerIDs)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(users))
+		for _, user := range users {
+			names = append(names, user.Nickname)
+		}
+		content = fmt.Sprintf("挑战「%s」已结束，获胜者：%s", challenge.Title, strings.Join(names, "、"))
+	}
+
+	for _, participant := range joined {
+		notification := &entity.Notification{
+			UserID:  participant.UserID,
+			Type:    entity.NotificationTypeChallengeResult,
+			Content: content,
+			RefID:   challenge.ID,
+		}
+		if err := s.notificationService.Send(ctx, notification); err != nil {
+			log.Printf("发送挑战%d的结果通知失败: %v", challenge.ID, err)
+		}
+	}
+	log.Printf("已结算挑战%d，共%d人参加，%d人获胜", challenge.ID, len(joined), len(winners))
+	return nil
+}
+
+// liveStandings 按挑战目标计算已参加用户在挑战期间的实时排名
+// 挑战由参与者主动参加，不受排行榜可见性设置影响；参与者之间不一定互为好友，只统计公开的记录，被反作弊标记的记录不计入成绩
+func (s *challengeService) liveStandings(ctx context.Context, challenge *entity.Challenge, participants []*entity.ChallengeParticipant) ([]*entity.RankingItem, error) {
+	var joinedIDs []uint64
+	for _, participant := range participants {
+		if participant.Status == entity.ChallengeParticipantJoined {
+			joinedIDs = append(joinedIDs, participant.UserID)
+		}
+	}
+	if len(joinedIDs) == 0 {
+		return []*entity.RankingItem{}, nil
+	}
+
+	metric := entity.ChallengeGoalMetric(challenge.Goal)
+	standings, _, err := s.recordRepo.GetFriendRanking(ctx, metric, joinedIDs, entity.RecordVisibilitiesPublic, challenge.StartAt, endOfRange(challenge.EndAt), 1, len(joinedIDs))
+	return standings, err
+}
+
+// settledStandings 用结算时保存的名次和成绩生成最终排名，结算后修改记录不影响结果
+func settledStandings(challenge *entity.Challenge, participants []*entity.ChallengeParticipant) []*entity.RankingItem {
+	metric := entity.ChallengeGoalMetric(challenge.Goal)
+	standings := make([]*entity.RankingItem, 0, len(participants))
+	for _, participant := range participants {
+		if participant.Status != entity.ChallengeParticipantJoined || participant.FinalRank == 0 {
+			continue
+		}
+		standings = append(standings, &entity.RankingItem{
+			Rank:        participant.FinalRank,
+			UserID:      participant.UserID,
+			Metric:      metric,
+			MetricValue: participant.Score,
+		})
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Rank < standings[j].Rank
+	})
+	return standings
+}
+
+// findAsParticipant 查找挑战以及用户的参与信息，不是参与者时按挑战不存在处理
+func (s *challengeService) findAsParticipant(ctx context.Context, userID, challengeID uint64) (*entity.Challenge, *entity.ChallengeParticipant, error) {
+	participant, err := s.challengeRepo.FindParticipant(ctx, challengeID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if participant == nil {
+		return nil, nil, ErrChallengeNotFound
+	}
+
+	challenge, err := s.challengeRepo.FindByID(ctx, challengeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge == nil {
+		return nil, nil, ErrChallengeNotFound
+	}
+	return challenge, participant, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrCircleNotFound 分组不存在或不属于当前用户
+	ErrCircleNotFound = errors.New("分组不存在")
+	// ErrCircleNameInvalid 分组名称为空或过长
+	ErrCircleNameInvalid = errors.New("分组名称不能为空且不能超过20个字")
+	// ErrCircleNameDuplicate 已有同名分组
+	ErrCircleNameDuplicate = errors.New("已有同名分组")
+	// ErrTooManyCircles 分组数量已达上限
+	ErrTooManyCircles = errors.New("分组数量已达上限")
+	// ErrCircleOrderInvalid 排序时给出的分组与用户现有分组不一致
+	ErrCircleOrderInvalid = errors.New("分组排序需要包含全部分组且不能重复")
+	// ErrCircleMemberNotFriend 只能把好友加入分组
+	ErrCircleMemberNotFriend = errors.New("只能把好友加入分组")
+)
+
+// CircleService 好友分组服务接口
+type CircleService interface {
+	// GetCircles 获取用户创建的分组
+	GetCircles(ctx context.Context, userID uint64) ([]*entity.Circle, error)
+
+	// GetCircle 获取用户创建的某个分组，不属于该用户时返回 ErrCircleNotFound
+	GetCircle(ctx context.Context, userID, circleID uint64) (*entity.Circle, error)
+
+	// CreateCircle 创建分组，新分组排在最后
+	CreateCircle(ctx context.Context, userID uint64, name string) (*entity.Circle, error)
+
+	// RenameCircle 修改分组名称
+	RenameCircle(ctx context.Context, userID, circleID uint64, name string) (*entity.Circle, error)
+
+	// DeleteCircle 删除分组，不影响好友关系
+	DeleteCircle(ctx context.Context, userID, circleID uint64) error
+
+	// ReorderCircles 按给定顺序重排用户的全部分组
+	ReorderCircles(ctx context.Context, userID uint64, circleIDs []uint64) error
+
+	// AddMembers 把好友加入分组
+	AddMembers(ctx context.Context, userID, circleID uint64, memberIDs []uint64) error
+
+	// RemoveMember 把成员移出分组
+	RemoveMember(ctx context.Context, userID, circleID, memberID uint64) error
+
+	// GetCircleFriendIDs 获取分组中仍是好友的成员ID
+	GetCircleFriendIDs(ctx context.Context, userID, circleID uint64) ([]uint64, error)
+}
+
+// circleService 好友分组服务实现
+type circleService struct {
+	circleRepo repository.CircleRepository
+	friendRepo repository.FriendRepository
+}
+
+// NewCircleService 创建好友分组服务
+func NewCircleService(circleRepo repository.CircleRepository, friendRepo repository.FriendRepository) CircleService {
+	return &circleService{
+		circleRepo: circleRepo,
+		friendRepo: friendRepo,
+	}
+}
+
+// GetCircles 获取用户创建的分组
+func (s *circleService) GetCircles(ctx context.Context, userID uint64) ([]*entity.Circle, error) {
+	return s.circleRepo.FindByUserID(ctx, userID)
+}
+
+// GetCircle 获取用户创建的某个分组
+func (s *circleService) GetCircle(ctx context.Context, userID, circleID uint64) (*entity.Circle, error) {
+	circle, err := s.circleRepo.FindByID(ctx, circleID)
+	if err != nil {
+		return nil, err
+	}
+	if circle == nil || circle.UserID != userID {
+		return nil, ErrCircleNotFound
+	}
+	return circle, nil
+}
+
+// CreateCircle 创建分组
+func (s *circleService) CreateCircle(ctx context.Context, userID uint64, name string) (*entity.Circle, error) {
+	name, err := s.validateName(ctx, userID, 0, name)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.circleRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= entity.MaxCirclesPerUser {
+		return nil, ErrTooManyCircles
+	}
+
+	circle := &entity.Circle{UserID: userID, Name: name}
+	if err := s.circleRepo.Save(ctx, circle); err != nil {
+		return nil, err
+	}
+	return circle, nil
+}
+
+// RenameCircle 修改分组名称
+func (s *circleService) RenameCircle(ctx context.Context, userID, circleID uint64, name string) (*entity.Circle, error) {
+	circle, err := s.GetCircle(ctx, userID, circleID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err = s.validateName(ctx, userID, circleID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	circle.Name = name
+	if err := s.circleRepo.Update(ctx, circle); err != nil {
+		return nil, err
+	}
+	return circle, nil
+}
+
+// DeleteCircle 删除分组
+func (s *circleService) DeleteCircle(ctx context.Context, userID, circleID uint64) error {
+	if _, err := s.GetCircle(ctx, userID, circleID); err != nil {
+		return err
+	}
+	return s.circleRepo.Delete(ctx, circleID)
+}
+
+// ReorderCircles 按给定顺序重排用户的全部分组
+func (s *circleService) ReorderCircles(ctx context.Context, userID uint64, circleIDs []uint64) error {
+	circles, err := s.circleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(circleIDs) != len(circles) {
+		return ErrCircleOrderInvalid
+	}
+
+	owned := make(map[uint64]bool, len(circles))
+	for _, circle := range circles {
+		owned[circle.ID] = true
+	}
+	for _, circleID := range circleIDs {
+		if !owned[circleID] {
+			return ErrCircleOrderInvalid
+		}
+		// 每个分组只能出现一次
+		delete(owned, circleID)
+	}
+
+	return s.circleRepo.Reorder(ctx, userID, circleIDs)
+}
+
+// AddMembers 把好友加入分组
+func (s *circleService) AddMembers(ctx context.Context, userID, circleID uint64, memberIDs []uint64) error {
+	if _, err := s.GetCircle(ctx, userID, circleID); err != nil {
+		return err
+	}
+
+	friendIDs, err := s.friendRepo.FindFriendIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	friendSet := make(map[uint64]bool, len(friendIDs))
+	for _, friendID := range friendIDs {
+		friendSet[friendID] = true
+	}
+	for _, memberID := range memberIDs {
+		if !friendSet[memberID] {
+			return ErrCircleMemberNotFriend
+		}
+	}
+
+	return s.circleRepo.AddMembers(ctx, circleID, memberIDs)
+}
+
+// RemoveMember 把成员移出分组，成员不在分组中时忽略
+func (s *circleService) RemoveMember(ctx context.Context, userID, circleID, memberID uint64) error {
+	if _, err := s.GetCircle(ctx, userID, circleID); err != nil {
+		return err
+	}
+	_, err := s.circleRepo.RemoveMember(ctx, circleID, memberID)
+	return err
+}
+
+// GetCircleFriendIDs 获取分组中仍是好友的成员ID
+func (s *circleService) GetCircleFriendIDs(ctx context.Context, userID, circleID uint64) ([]uint64, error) {
+	if _, err := s.GetCircle(ctx, userID, circleID); err != nil {
+		return nil, err
+	}
+	return s.friendRepo.FindCircleFriendIDs(ctx, userID, circleID)
+}
+
+// validateName 校验分组名称，返回去除首尾空白后的名称
+// excludeID 为正在修改的分组，重名检查时跳过
+func (s *circleService) validateName(ctx context.Context, userID, excludeID uint64, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > entity.CircleNameMaxLength {
+		return "", ErrCircleNameInvalid
+	}
+
+	circles, err := s.circleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, circle := range circles {
+		if circle.ID != excludeID && circle.Name == name {
+			return "", ErrCircleNameDuplicate
+		}
+	}
+	return name, nil
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 动态流每页条数
+const (
+	defaultFeedPageSize = 20
+	maxFeedPageSize     = 50
+)
+
+// 产生名次上升动态的榜单，只关注全局周榜的记录次数，避免同一周期刷出多条名次动态
+const (
+	feedRankPeriod = entity.RankingPeriodWeekly
+	feedRankMetric = entity.RankingMetricCount
+)
+
+// 动态相关错误
+var (
+	ErrFeedCursorInvalid = errors.New("无效的分页游标")
+)
+
+// FeedService 好友动态服务接口
+type FeedService interface {
+	// GetFeed 获取好友的动态（按发生时间倒序），cursor 为空时从最新开始
+	GetFeed(ctx context.Context, viewerID uint64, cursor string, limit int) (*entity.FeedPage, error)
+
+	// HandleRecordChanged 记录变更时生成、同步或删除对应的动态
+	HandleRecordChanged(ctx context.Context, e event.Event)
+
+	// HandleGoalMet 目标达成时生成动态
+	HandleGoalMet(ctx context.Context, e event.Event)
+
+	// HandleGoalRevoked 目标达成被撤销时删除对应的动态
+	HandleGoalRevoked(ctx context.Context, e event.Event)
+
+	// HandleRankingSnapshotTaken 全局排行榜快照生成后为名次上升的用户生成动态
+	HandleRankingSnapshotTaken(ctx context.Context, e event.Event)
+
+	// HandleBadgeAwarded 获得徽章时生成动态
+	HandleBadgeAwarded(ctx context.Context, e event.Event)
+}
+
+// feedService 好友动态服务实现
+type feedService struct {
+	activityRepo repository.ActivityRepository
+	recordRepo   repository.RecordRepository
+	friendRepo   repository.FriendRepository
+	circleRepo   repository.CircleRepository
+	userRepo     repository.UserRepository
+	snapshotRepo repository.RankingSnapshotRepository
+	settingRepo  repository.RankingSettingRepository
+	interactions RecordInteractionService
+}
+
+// NewFeedService 创建好友动态服务
+func NewFeedService(
+	activityRepo repository.ActivityRepository,
+	recordRepo repository.RecordRepository,
+	friendRepo repository.FriendRepository,
+	circleRepo repository.CircleRepository,
+	userRepo repository.UserRepository,
+	snapshotRepo repository.RankingSnapshotRepository,
+	settingRepo repository.RankingSettingRepository,
+	interactions RecordInteractionService,
+) FeedService {
+	return &feedService{
+		activityRepo: activityRepo,
+		recordRepo:   recordRepo,
+		friendRepo:   friendRepo,
+		circleRepo:   circleRepo,
+		userRepo:     userRepo,
+		snapshotRepo: snapshotRepo,
+		settingRepo:  settingRepo,
+		interactions: interactions,
+	}
+}
+
+// GetFeed 获取好友的动态
+// 按读扩散实现：每次读取时合并所有好友的动态，可见范围在查询时按好友关系和分组判断
+func (s *feedService) GetFeed(ctx context.Context, viewerID uint64, cursor string, limit int) (*entity.FeedPage, error) {
+	if limit < 1 || limit > maxFeedPageSize {
+		limit = defaultFeedPageSize
+	}
+	after, err := decodeFeedCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	friendIDs, err := s.friendRepo.FindFriendIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	actorIDs := make([]uint64, 0, len(friendIDs))
+	for _, friendID := range friendIDs {
+		if friendID != viewerID {
+			actorIDs = append(actorIDs, friendID)
+		}
+	}
+	if len(actorIDs) == 0 {
+		return &entity.FeedPage{Items: []*entity.Activity{}}, nil
+	}
+
+	circles, err := s.circleRepo.FindIDsContainingMember(ctx, actorIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	var circleIDs []uint64
+	for _, ids := range circles {
+		circleIDs = append(circleIDs, ids...)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	activities, err := s.activityRepo.FindFeed(ctx, actorIDs, circleIDs, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.FeedPage{Items: activities}
+	if len(activities) > limit {
+		page.Items = activities[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeFeedCursor(&entity.FeedCursor{OccurredAt: last.OccurredAt, ID: last.ID})
+	}
+
+	if err := s.attachRelations(ctx, viewerID, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// attachRelations 补充动态的用户信息、关联记录及其回应和评论汇总
+func (s *feedService) attachRelations(ctx context.Context, viewerID uint64, activities []*entity.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	actorIDs := make([]uint64, 0, len(activities))
+	recordIDs := make([]uint64, 0, len(activities))
+	seenActors := make(map[uint64]bool)
+	for _, activity := range activities {
+		if !seenActors[activity.ActorID] {
+			seenActors[activity.ActorID] = true
+			actorIDs = append(actorIDs, activity.ActorID)
+		}
+		if activity.Type == entity.ActivityTypeRecord && activity.RecordID > 0 {
+			recordIDs = append(recordIDs, activity.RecordID)
+		}
+	}
+
+	users, err := s.userRepo.FindByIDs(ctx, actorIDs)
+	if err != nil {
+		return err
+	}
+	userMap := make(map[uint64]*entity.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+
+	records, err := s.recordRepo.FindByIDs(ctx, recordIDs)
+	if err != nil {
+		return err
+	}
+	recordMap := make(map[uint64]*entity.Record, len(records))
+	for _, record := range records {
+		recordMap[record.ID] = record
+	}
+
+	interactionMap, err := s.interactions.GetInteractions(ctx, viewerID, recordIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, activity := range activities {
+		activity.Actor = userMap[activity.ActorID]
+		if activity.Type == entity.ActivityTypeRecord {
+			activity.Record = recordMap[activity.RecordID]
+			activity.Interactions = interactionMap[activity.RecordID]
+		}
+	}
+	return nil
+}
+
+// encodeFeedCursor 将游标编码为不透明的字符串
+func encodeFeedCursor(cursor *entity.FeedCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.OccurredAt.UnixNano(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor 解析分页游标，空字符串表示从最新开始
+func decodeFeedCursor(cursor string) (*entity.FeedCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrFeedCursorInvalid
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, ErrFeedCursorInvalid
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrFeedCursorInvalid
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrFeedCursorInvalid
+	}
+	return &entity.FeedCursor{OccurredAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// HandleRecordChanged 记录变更时生成、同步或删除对应的动态
+func (s *feedService) HandleRecordChanged(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RecordChanged)
+	if !ok || changed.Record == nil {
+		return
+	}
+	record := changed.Record
+
+	switch changed.Name {
+	case event.RecordCreated:
+		activity := &entity.Activity{
+			ActorID:    record.UserID,
+			Type:       entity.ActivityTypeRecord,
+			RefKey:     fmt.Sprintf("record:%d", record.ID),
+			RecordID:   record.ID,
+			Visibility: record.Visibility,
+			CircleID:   record.CircleID,
+			OccurredAt: record.RecordTime,
+		}
+		if err := s.activityRepo.Save(ctx, activity); err != nil {
+			log.Printf("保存记录%d的动态失败: %v", record.ID, err)
+		}
+		if err := s.saveStreakMilestone(ctx, record); err != nil {
+			log.Printf("生成用户%d的连续记录动态失败: %v", record.UserID, err)
+		}
+	case event.RecordUpdated:
+		if err := s.activityRepo.UpdateByRecord(ctx, record); err != nil {
+			log.Printf("同步记录%d的动态失败: %v", record.ID, err)
+		}
+	case event.RecordDeleted:
+		if err := s.activityRepo.DeleteByRecordID(ctx, record.ID); err != nil {
+			log.Printf("删除记录%d的动态失败: %v", record.ID, err)
+		}
+	}
+}
+
+// saveStreakMilestone 记录是当天第一条且连续天数恰好达到里程碑时生成动态
+// 里程碑动态挂在这条记录上，沿用记录的可见范围，记录删除时一并删除
+func (s *feedService) saveStreakMilestone(ctx context.Context, record *entity.Record) error {
+	day := startOfDay(record.RecordTime)
+	dayEnd := endOfRange(day.AddDate(0, 0, 1))
+
+	count, err := s.recordRepo.CountRecordsByDateRange(ctx, record.UserID, nil, day, dayEnd)
+	if err != nil {
+		return err
+	}
+	if count != 1 {
+		return nil
+	}
+
+	recordTimes, err := s.recordRepo.FindRecordTimes(ctx, record.UserID, time.Unix(0, 0), dayEnd)
+	if err != nil {
+		return err
+	}
+	streak := currentStreak(recordDays(recordTimes), day)
+	if !entity.IsStreakMilestone(streak) {
+		return nil
+	}
+
+	return s.activityRepo.Save(ctx, &entity.Activity{
+		ActorID:    record.UserID,
+		Type:       entity.ActivityTypeStreakMilestone,
+		RefKey:     fmt.Sprintf("streak:%d", record.ID),
+		RecordID:   record.ID,
+		Visibility: record.Visibility,
+		CircleID:   record.CircleID,
+		Data:       &entity.ActivityData{StreakDays: streak},
+		OccurredAt: record.RecordTime,
+	})
+}
+
+// HandleGoalMet 目标达成时生成好友可见的动态
+func (s *feedService) HandleGoalMet(ctx context.Context, e event.Event) {
+	met, ok := e.(*event.GoalMetEvent)
+	if !ok || met.Goal == nil || met.Completion == nil {
+		return
+	}
+
+	occurredAt := time.Now()
+	if met.Completion.MetAt != nil {
+		occurredAt = *met.Completion.MetAt
+	}
+
+	activity := &entity.Activity{
+		ActorID:    met.Goal.UserID,
+		Type:       entity.ActivityTypeGoalMet,
+		RefKey:     goalActivityRefKey(met.Completion),
+		Visibility: entity.RecordVisibilityFriends,
+		Data: &entity.ActivityData{
+			GoalID:     met.Goal.ID,
+			GoalName:   met.Goal.Name,
+			GoalWindow: met.Goal.Window,
+		},
+		OccurredAt: occurredAt,
+	}
+	if err := s.activityRepo.Save(ctx, activity); err != nil {
+		log.Printf("保存目标%d的达成动态失败: %v", met.Goal.ID, err)
+	}
+}
+
+// HandleGoalRevoked 目标达成被撤销时删除对应的动态，再次达成时重新生成
+func (s *feedService) HandleGoalRevoked(ctx context.Context, e event.Event) {
+	revoked, ok := e.(*event.GoalRevokedEvent)
+	if !ok || revoked.Completion == nil {
+		return
+	}
+
+	if err := s.activityRepo.DeleteByRefKey(ctx, revoked.Completion.UserID, goalActivityRefKey(revoked.Completion)); err != nil {
+		log.Printf("删除目标%d的达成动态失败: %v", revoked.Completion.GoalID, err)
+	}
+}
+
+// goalActivityRefKey 目标达成动态的去重键，每个目标每个周期一条
+func goalActivityRefKey(completion *entity.GoalCompletion) string {
+	return fmt.Sprintf("goal:%d:%d", completion.GoalID, completion.PeriodStart.Unix())
+}
+
+// HandleRankingSnapshotTaken 全局排行榜快照生成后为名次比上一周期上升的用户生成动态
+// 在全局排行榜中匿名的用户不生成，避免好友通过动态得知其全局名次
+func (s *feedService) HandleRankingSnapshotTaken(ctx context.Context, e event.Event) {
+	taken, ok := e.(*event.RankingSnapshotTakenEvent)
+	if !ok || taken.Period != feedRankPeriod || taken.Metric != feedRankMetric || len(taken.Snapshots) == 0 {
+		return
+	}
+
+	userIDs := make([]uint64, 0, len(taken.Snapshots))
+	for _, snapshot := range taken.Snapshots {
+		userIDs = append(userIDs, snapshot.UserID)
+	}
+
+	previousStart := windowStart(periodWindow(taken.Period), taken.PeriodStart.Add(-time.Nanosecond))
+	previous, err := s.snapshotRepo.FindRanks(ctx, entity.RankingScopeGlobal, 0, taken.Period, previousStart, taken.Metric, userIDs)
+	if err != nil {
+		log.Printf("查询上一周期的排行榜快照失败: %v", err)
+		return
+	}
+	settings, err := s.settingRepo.FindByUserIDs(ctx, userIDs)
+	if err != nil {
+		log.Printf("查询排行榜设置失败: %v", err)
+		return
+	}
+
+	var activities []*entity.Activity
+	for _, snapshot := range taken.Snapshots {
+		last, ok := previous[snapshot.UserID]
+		if !ok || last.Rank == 0 || snapshot.Rank == 0 || snapshot.Rank >= last.Rank {
+			continue
+		}
+		if setting, ok := settings[snapshot.UserID]; ok && setting.Visibility == entity.RankingVisibilityAnonymous {
+			continue
+		}
+
+		activities = append(activities, &entity.Activity{
+			ActorID:    snapshot.UserID,
+			Type:       entity.ActivityTypeRankChange,
+			RefKey:     fmt.Sprintf("rank:%s:%s:%d", taken.Period, taken.Metric, taken.PeriodStart.Unix()),
+			Visibility: entity.RecordVisibilityFriends,
+			Data: &entity.ActivityData{
+				Period:       taken.Period,
+				Metric:       taken.Metric,
+				Rank:         snapshot.Rank,
+				PreviousRank: last.Rank,
+				MetricValue:  snapshot.MetricValue,
+			},
+			OccurredAt: snapshot.PeriodEnd,
+		})
+	}
+
+	if err := s.activityRepo.SaveBatch(ctx, activities); err != nil {
+		log.Printf("保存排行榜名次动态失败: %v", err)
+	}
+}
+
+// HandleBadgeAwarded 获得徽章时生成好友可见的动态，补发的徽章不发布事件因此不会产生动态
+func (s *feedService) HandleBadgeAwarded(ctx context.Context, e event.Event) {
+	awarded, ok := e.(*event.BadgeAwardedEvent)
+	if !ok || awarded.Badge == nil || awarded.Badge.Badge == nil {
+		return
+	}
+
+	badge := awarded.Badge
+	activity := &entity.Activity{
+		ActorID:    badge.UserID,
+		Type:       entity.ActivityTypeBadge,
+		RefKey:     "badge:" + badge.BadgeCode,
+		Visibility: entity.RecordVisibilityFriends,
+		Data: &entity.ActivityData{
+			BadgeCode: badge.BadgeCode,
+			BadgeName: badge.Badge.Name,
+		},
+		OccurredAt: badge.AwardedAt,
+	}
+	if err := s.activityRepo.Save(ctx, activity); err != nil {
+		log.Printf("保存徽章%d的动态失败: %v", badge.ID, err)
+	}
+}
@@ -3,8 +3,27 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"record-project/domain/entity"
+	"record-project/domain/event"
 	"record-project/domain/repository"
+	"time"
+)
+
+var (
+	// ErrFriendRequestNotFound 好友申请不存在、不是当前用户发出的或已处理
+	ErrFriendRequestNotFound = errors.New("好友申请不存在")
+	// ErrTooManyPendingRequests 待确认的好友申请数量已达上限
+	ErrTooManyPendingRequests = errors.New("待确认的好友申请过多，请等待对方处理或撤回部分申请")
+	// ErrFriendRelationNotFound 好友关系不存在或与当前用户无关
+	ErrFriendRelationNotFound = errors.New("好友关系不存在")
+	// ErrAlreadyFriends 双方已经是好友
+	ErrAlreadyFriends = errors.New("已经是好友关系")
+	// ErrFriendRequestAlreadySent 已发送过好友申请，对方尚未处理
+	ErrFriendRequestAlreadySent = errors.New("已发送过好友申请，等待对方确认")
+	// ErrFriendStatusConflict 好友关系在读取之后已被其他请求更新
+	ErrFriendStatusConflict = errors.New("好友关系已被更新，请刷新后重试")
 )
 
 // FriendService 好友服务接口
@@ -18,8 +37,8 @@ type FriendService interface {
 	// AddFriend 添加好友
 	AddFriend(ctx context.Context, userID, friendID uint64) error
 
-	// UpdateFriendStatus 更新好友状态
-	UpdateFriendStatus(ctx context.Context, id uint64, status int8) error
+	// UpdateFriendStatus 由关系一方按状态机变更好友关系状态
+	UpdateFriendStatus(ctx context.Context, relationID, actorID uint64, status entity.FriendStatus) error
 
 	// DeleteFriend 删除好友
 	DeleteFriend(ctx context.Context, friendId uint64, userID uint64) error
@@ -27,8 +46,8 @@ type FriendService interface {
 	// GetFriendRelation 获取好友关系
 	GetFriendRelation(ctx context.Context, userID, friendID uint64) (*entity.Friend, error)
 
-	// GetFriendsByUserIDWithPagination 分页获取用户的好友列表
-	GetFriendsByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string) ([]*entity.Friend, int64, error)
+	// GetFriendsByUserIDWithPagination 分页获取用户的好友列表，circleID 大于0时只获取该分组中的好友
+	GetFriendsByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string, circleID uint64) ([]*entity.Friend, int64, error)
 
 	// GetFriendRequests 获取发送给用户的好友申请
 	GetFriendRequests(ctx context.Context, userID uint64, page, size int) ([]*entity.Friend, int64, error)
@@ -36,19 +55,35 @@ type FriendService interface {
 	// GetFriendRelationByID 通过ID获取好友关系
 	GetFriendRelationByID(ctx context.Context, relationID uint64) (*entity.Friend, error)
 
-	// UpdateFriendStatusWithVerification 更新好友状态（带验证）
-	UpdateFriendStatusWithVerification(ctx context.Context, relationID, userID, friendID uint64, status int8) error
+	// GetSentRequests 获取用户发出的待确认好友申请
+	GetSentRequests(ctx context.Context, userID uint64, page, size int) ([]*entity.Friend, int64, error)
+
+	// WithdrawRequest 撤回用户发出的待确认好友申请
+	WithdrawRequest(ctx context.Context, userID, requestID uint64) error
+
+	// ExpireRequests 删除超过有效期仍未处理的好友申请
+	ExpireRequests(ctx context.Context, now time.Time) error
 }
 
 // friendService 好友服务实现
 type friendService struct {
 	friendRepo repository.FriendRepository
+
+	// maxPendingRequests 每个用户最多同时存在的待确认申请数，0表示不限制
+	maxPendingRequests int
+	// requestExpiry 好友申请的有效期，0表示永不过期
+	requestExpiry time.Duration
+
+	publisher event.Publisher
 }
 
 // NewFriendService 创建好友服务
-func NewFriendService(friendRepo repository.FriendRepository) FriendService {
+func NewFriendService(friendRepo repository.FriendRepository, maxPendingRequests int, requestExpiry time.Duration, publisher event.Publisher) FriendService {
 	return &friendService{
-		friendRepo: friendRepo,
+		friendRepo:         friendRepo,
+		maxPendingRequests: maxPendingRequests,
+		requestExpiry:      requestExpiry,
+		publisher:          publisher,
 	}
 }
 
@@ -69,18 +104,58 @@ func (s *friendService) GetFriendIDs(ctx context.Context, userID uint64) ([]uint
 }
 
 // AddFriend 添加好友
+// 对方已向自己发出申请时直接成为好友，不受待确认申请数量的限制；
+// 之前被拒绝或已删除的关系由自己重新发起申请，被拒绝的发起方需等待冷却期结束
 func (s *friendService) AddFriend(ctx context.Context, userID, friendID uint64) error {
-	friend := &entity.Friend{
-		UserID:   userID,
-		FriendID: friendID,
-		Status:   0, // 待确认状态
+	now := time.Now()
+	relation, err := s.friendRepo.FindByUserIDAndFriendID(ctx, userID, friendID)
+	if err != nil {
+		return err
+	}
+
+	if relation != nil {
+		switch relation.Status {
+		case entity.FriendStatusAccepted:
+			return ErrAlreadyFriends
+		case entity.FriendStatusPending:
+			if relation.UserID == userID {
+				return ErrFriendRequestAlreadySent
+			}
+			from := relation.Status
+			if err := relation.Transition(userID, entity.FriendStatusAccepted, now); err != nil {
+				return err
+			}
+			if err := s.updateRelation(ctx, relation, from); err != nil {
+				return err
+			}
+			s.publisher.Publish(ctx, &event.FriendAcceptedEvent{Relation: relation})
+			return nil
+		}
 	}
-	return s.friendRepo.Save(ctx, friend)
+
+	if s.maxPendingRequests > 0 {
+		pending, err := s.friendRepo.CountPendingSent(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if pending >= int64(s.maxPendingRequests) {
+			return ErrTooManyPendingRequests
+		}
+	}
+
+	if relation == nil {
+		return s.friendRepo.Save(ctx, entity.NewFriendRequest(userID, friendID, now))
+	}
+	from := relation.Status
+	if err := relation.Transition(userID, entity.FriendStatusPending, now); err != nil {
+		return err
+	}
+	return s.updateRelation(ctx, relation, from)
 }
 
 // GetFriendsByUserIDWithPagination 分页获取用户的好友列表
-func (s *friendService) GetFriendsByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string) ([]*entity.Friend, int64, error) {
-	friends, total, err := s.friendRepo.FindByUserIDWithPagination(ctx, userID, page, size, keyword)
+func (s *friendService) GetFriendsByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string, circleID uint64) ([]*entity.Friend, int64, error) {
+	friends, total, err := s.friendRepo.FindByUserIDWithPagination(ctx, userID, page, size, keyword, circleID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -98,33 +173,33 @@ func (s *friendService) GetFriendRequests(ctx context.Context, userID uint64, pa
 	return friends, total, nil
 }
 
-// UpdateFriendStatus 更新好友状态
-func (s *friendService) UpdateFriendStatus(ctx context.Context, id uint64, status int8) error {
-	// 查找好友关系
-	var friend *entity.Friend
-	var err error
-
-	// 先尝试通过ID查找
-	friends, err := s.friendRepo.FindByUserID(ctx, id)
+// UpdateFriendStatus 由关系一方按状态机变更好友关系状态
+func (s *friendService) UpdateFriendStatus(ctx context.Context, relationID, actorID uint64, status entity.FriendStatus) error {
+	relation, err := s.friendRepo.FindByID(ctx, relationID)
 	if err != nil {
 		return err
 	}
-
-	if len(friends) > 0 {
-		friend = friends[0]
+	if relation == nil || !relation.Involves(actorID) {
+		return ErrFriendRelationNotFound
 	}
 
-	if friend == nil {
-		return errors.New("好友关系不存在")
+	from := relation.Status
+	if err := relation.Transition(actorID, status, time.Now()); err != nil {
+		return err
 	}
-
-	friend.Status = status
-	return s.friendRepo.Update(ctx, friend)
+	if err := s.updateRelation(ctx, relation, from); err != nil {
+		return err
+	}
+	if status == entity.FriendStatusAccepted {
+		s.publisher.Publish(ctx, &event.FriendAcceptedEvent{Relation: relation})
+	}
+	return nil
 }
 
 // DeleteFriend 删除好友
+// 关系记录保留为已删除状态，之后任意一方都可以重新发起申请
 func (s *friendService) DeleteFriend(ctx context.Context, id uint64, userID uint64) error {
-	return s.friendRepo.Delete(ctx, id, userID)
+	return s.UpdateFriendStatus(ctx, id, userID, entity.FriendStatusRemoved)
 }
 
 // GetFriendRelation 获取好友关系
@@ -137,30 +212,51 @@ func (s *friendService) GetFriendRelationByID(ctx context.Context, relationID ui
 	return s.friendRepo.FindByID(ctx, relationID)
 }
 
-// UpdateFriendStatusWithVerification 更新好友状态（带验证）
-func (s *friendService) UpdateFriendStatusWithVerification(ctx context.Context, relationID, userID, friendID uint64, status int8) error {
-	// 获取好友关系
-	relation, err := s.friendRepo.FindByID(ctx, relationID)
+// GetSentRequests 获取用户发出的待确认好友申请
+func (s *friendService) GetSentRequests(ctx context.Context, userID uint64, page, size int) ([]*entity.Friend, int64, error) {
+	return s.friendRepo.FindSentRequestsByUserID(ctx, userID, page, size)
+}
+
+// WithdrawRequest 撤回用户发出的待确认好友申请
+func (s *friendService) WithdrawRequest(ctx context.Context, userID, requestID uint64) error {
+	relation, err := s.friendRepo.FindByID(ctx, requestID)
 	if err != nil {
 		return err
 	}
+	if relation == nil || relation.UserID != userID || relation.Status != entity.FriendStatusPending {
+		return ErrFriendRequestNotFound
+	}
 
-	if relation == nil {
-		return errors.New("好友关系不存在")
+	if err := relation.Transition(userID, entity.FriendStatusRemoved, time.Now()); err != nil {
+		return err
+	}
+	return s.updateRelation(ctx, relation, entity.FriendStatusPending)
+}
+
+// updateRelation 以变更前的状态为条件写回好友关系，期间关系已被其他请求更新时返回ErrFriendStatusConflict
+func (s *friendService) updateRelation(ctx context.Context, relation *entity.Friend, from entity.FriendStatus) error {
+	updated, err := s.friendRepo.Update(ctx, relation, from)
+	if err != nil {
+		return err
 	}
-	
-	// 验证用户权限
-	if relation.UserID != userID && relation.FriendID != userID {
-		return errors.New("无权更新此好友关系")
+	if !updated {
+		return ErrFriendStatusConflict
 	}
+	return nil
+}
 
-	// 验证好友ID
-	if (relation.UserID == userID && relation.FriendID != friendID) ||
-		(relation.FriendID == userID && relation.UserID != friendID) {
-		return errors.New("好友ID不匹配")
+// ExpireRequests 删除超过有效期仍未处理的好友申请
+func (s *friendService) ExpireRequests(ctx context.Context, now time.Time) error {
+	if s.requestExpiry <= 0 {
+		return nil
 	}
 
-	// 更新状态
-	relation.Status = status
-	return s.friendRepo.Update(ctx, relation)
+	deleted, err := s.friendRepo.DeleteExpiredRequests(ctx, now.Add(-s.requestExpiry))
+	if err != nil {
+		return fmt.Errorf("删除过期好友申请失败: %w", err)
+	}
+	if deleted > 0 {
+		log.Printf("已删除%d条过期的好友申请", deleted)
+	}
+	return nil
 }
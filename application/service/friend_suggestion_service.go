@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"sort"
+)
+
+// 好友推荐规则
+const (
+	suggestionCandidateLimit = 200 // 按共同好友数取候选人的数量上限
+	suggestionMutualPreview  = 3   // 每个推荐展示的共同好友数
+	suggestionMutualWeight   = 1.0 // 每个共同好友的得分
+	suggestionGroupWeight    = 2.0 // 每个共同分组的得分
+)
+
+// FriendSuggestionService 好友推荐服务接口
+type FriendSuggestionService interface {
+	// GetSuggestions 分页获取好友推荐，按共同好友数和共同分组综合排序
+	// 已是好友、存在待确认或已拒绝的申请、存在屏蔽关系的用户不会被推荐
+	GetSuggestions(ctx context.Context, userID uint64, page, size int) ([]*entity.FriendSuggestion, int, error)
+
+	// GetMutualFriends 获取查看者与另一用户的共同好友
+	GetMutualFriends(ctx context.Context, viewerID, otherID uint64) ([]*entity.User, error)
+}
+
+// friendSuggestionService 好友推荐服务实现
+type friendSuggestionService struct {
+	friendRepo     repository.FriendRepository
+	userRepo       repository.UserRepository
+	blockRepo      repository.BlockRepository
+	membershipRepo repository.LeagueMembershipRepository
+}
+
+// NewFriendSuggestionService 创建好友推荐服务
+func NewFriendSuggestionService(
+	friendRepo repository.FriendRepository,
+	userRepo repository.UserRepository,
+	blockRepo repository.BlockRepository,
+	membershipRepo repository.LeagueMembershipRepository,
+) FriendSuggestionService {
+	return &friendSuggestionService{
+		friendRepo:     friendRepo,
+		userRepo:       userRepo,
+		blockRepo:      blockRepo,
+		membershipRepo: membershipRepo,
+	}
+}
+
+// GetSuggestions 分页获取好友推荐
+func (s *friendSuggestionService) GetSuggestions(ctx context.Context, userID uint64, page, size int) ([]*entity.FriendSuggestion, int, error) {
+	candidates, err := s.friendRepo.FindSuggestionCandidates(ctx, userID, suggestionCandidateLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byUser := make(map[uint64]*entity.FriendSuggestion, len(candidates))
+	for _, candidate := range candidates {
+		byUser[candidate.UserID] = &entity.FriendSuggestion{
+			UserID:      candidate.UserID,
+			MutualCount: candidate.MutualCount,
+		}
+	}
+
+	if err := s.addSharedGroups(ctx, userID, byUser); err != nil {
+		return nil, 0, err
+	}
+
+	// 补充用户信息，已注销的用户不再推荐
+	ids := make([]uint64, 0, len(byUser))
+	for id := range byUser {
+		ids = append(ids, id)
+	}
+	users, err := s.userRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suggestions := make([]*entity.FriendSuggestion, 0, len(users))
+	for _, user := range users {
+		suggestion, ok := byUser[user.ID]
+		if !ok || user.Status != 1 {
+			continue
+		}
+		suggestion.User = user
+		suggestion.Score = float64(suggestion.MutualCount)*suggestionMutualWeight + float64(suggestion.SharedGroups)*suggestionGroupWeight
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.MutualCount != b.MutualCount {
+			return a.MutualCount > b.MutualCount
+		}
+		return a.UserID < b.UserID
+	})
+
+	total := len(suggestions)
+	offset := (page - 1) * size
+	if offset >= total {
+		return []*entity.FriendSuggestion{}, total, nil
+	}
+	end := offset + size
+	if end > total {
+		end = total
+	}
+	suggestions = suggestions[offset:end]
+
+	for _, suggestion := range suggestions {
+		suggestion.MutualFriends = []*entity.User{}
+		if suggestion.MutualCount == 0 {
+			continue
+		}
+		mutual, err := s.mutualFriends(ctx, userID, suggestion.UserID, suggestionMutualPreview)
+		if err != nil {
+			return nil, 0, err
+		}
+		suggestion.MutualFriends = mutual
+	}
+	return suggestions, total, nil
+}
+
+// addSharedGroups 将最近一次参加联赛时同分组的用户加入推荐，已在候选人中的增加共同分组数
+func (s *friendSuggestionService) addSharedGroups(ctx context.Context, userID uint64, byUser map[uint64]*entity.FriendSuggestion) error {
+	memberships, _, err := s.membershipRepo.FindByUserID(ctx, userID, 1, 1)
+	if err != nil || len(memberships) == 0 {
+		return err
+	}
+
+	members, err := s.membershipRepo.FindByDivisionID(ctx, memberships[0].DivisionID)
+	if err != nil {
+		return err
+	}
+
+	excluded, err := s.excludedUserIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if excluded[member.UserID] {
+			continue
+		}
+		suggestion, ok := byUser[member.UserID]
+		if !ok {
+			suggestion = &entity.FriendSuggestion{UserID: member.UserID}
+			byUser[member.UserID] = suggestion
+		}
+		suggestion.SharedGroups++
+	}
+	return nil
+}
+
+// excludedUserIDs 不能推荐给用户的人：自己、存在任意好友关系或屏蔽关系的用户
+func (s *friendSuggestionService) excludedUserIDs(ctx context.Context, userID uint64) (map[uint64]bool, error) {
+	related, err := s.friendRepo.FindRelatedUserIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockRepo.FindBlockedIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[uint64]bool{userID: true}
+	for _, id := range related {
+		excluded[id] = true
+	}
+	for _, id := range blocked {
+		excluded[id] = true
+	}
+	return excluded, nil
+}
+
+// GetMutualFriends 获取查看者与另一用户的共同好友
+func (s *friendSuggestionService) GetMutualFriends(ctx context.Context, viewerID, otherID uint64) ([]*entity.User, error) {
+	return s.mutualFriends(ctx, viewerID, otherID, 0)
+}
+
+// mutualFriends 获取共同好友的用户信息，limit大于0时最多返回limit个
+func (s *friendSuggestionService) mutualFriends(ctx context.Context, userID, otherID uint64, limit int) ([]*entity.User, error) {
+	ids, err := s.friendRepo.FindMutualFriendIDs(ctx, userID, otherID)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	if len(ids) == 0 {
+		return []*entity.User{}, nil
+	}
+	return s.userRepo.FindByIDs(ctx, ids)
+}
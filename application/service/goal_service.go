@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrGoalNotFound 目标不存在或不属于当前用户
+	ErrGoalNotFound = errors.New("目标不存在")
+)
+
+// GoalService 个人目标服务接口
+type GoalService interface {
+	// CreateGoal 创建目标
+	CreateGoal(ctx context.Context, goal *entity.Goal) error
+
+	// UpdateGoal 更新目标名称、目标值或状态
+	UpdateGoal(ctx context.Context, userID uint64, goal *entity.Goal) error
+
+	// DeleteGoal 删除目标
+	DeleteGoal(ctx context.Context, userID, goalID uint64) error
+
+	// GetGoals 获取用户的目标及当前周期进度
+	GetGoals(ctx context.Context, userID uint64, includeArchived bool, now time.Time) ([]*entity.GoalProgress, error)
+
+	// GetGoalProgress 获取单个目标的当前周期进度
+	GetGoalProgress(ctx context.Context, userID, goalID uint64, now time.Time) (*entity.GoalProgress, error)
+
+	// GetGoalHistory 分页获取目标的完成记录
+	GetGoalHistory(ctx context.Context, userID, goalID uint64, page, size int) ([]*entity.GoalCompletion, int64, error)
+
+	// HandleRecordChanged 记录变更时检查次数类目标是否达成
+	HandleRecordChanged(ctx context.Context, e event.Event)
+
+	// SettleGoals 结算所有已结束周期的目标
+	SettleGoals(ctx context.Context, now time.Time) error
+}
+
+// goalService 个人目标服务实现
+type goalService struct {
+	goalRepo       repository.GoalRepository
+	completionRepo repository.GoalCompletionRepository
+	recordRepo     repository.RecordRepository
+	publisher      event.Publisher
+}
+
+// NewGoalService 创建个人目标服务
+func NewGoalService(
+	goalRepo repository.GoalRepository,
+	completionRepo repository.GoalCompletionRepository,
+	recordRepo repository.RecordRepository,
+	publisher event.Publisher,
+) GoalService {
+	return &goalService{
+		goalRepo:       goalRepo,
+		completionRepo: completionRepo,
+		recordRepo:     recordRepo,
+		publisher:      publisher,
+	}
+}
+
+// CreateGoal 创建目标
+func (s *goalService) CreateGoal(ctx context.Context, goal *entity.Goal) error {
+	goal.Name = strings.TrimSpace(goal.Name)
+	if err := validateGoal(goal); err != nil {
+		return err
+	}
+	goal.Status = entity.GoalStatusActive
+	return s.goalRepo.Save(ctx, goal)
+}
+
+// UpdateGoal 更新目标名称、目标值或状态，指标和周期创建后不可修改
+func (s *goalService) UpdateGoal(ctx context.Context, userID uint64, goal *entity.Goal) error {
+	existing, err := s.findOwnGoal(ctx, userID, goal.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = strings.TrimSpace(goal.Name)
+	existing.Target = goal.Target
+	existing.Status = goal.Status
+	if err := validateGoal(existing); err != nil {
+		return err
+	}
+	if existing.Status != entity.GoalStatusActive && existing.Status != entity.GoalStatusArchived {
+		return errors.New("无效的目标状态")
+	}
+
+	if err := s.goalRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	*goal = *existing
+	return nil
+}
+
+// DeleteGoal 删除目标
+func (s *goalService) DeleteGoal(ctx context.Context, userID, goalID uint64) error {
+	if _, err := s.findOwnGoal(ctx, userID, goalID); err != nil {
+		return err
+	}
+	return s.goalRepo.Delete(ctx, goalID)
+}
+
+// GetGoals 获取用户的目标及当前周期进度
+func (s *goalService) GetGoals(ctx context.Context, userID uint64, includeArchived bool, now time.Time) ([]*entity.GoalProgress, error) {
+	goals, err := s.goalRepo.FindByUserID(ctx, userID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	progresses := make([]*entity.GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		progress, err := s.evaluate(ctx, goal, windowStart(goal.Window, now))
+		if err != nil {
+			return nil, err
+		}
+		progresses = append(progresses, progress)
+	}
+	return progresses, nil
+}
+
+// GetGoalProgress 获取单个目标的当前周期进度
+func (s *goalService) GetGoalProgress(ctx context.Context, userID, goalID uint64, now time.Time) (*entity.GoalProgress, error) {
+	goal, err := s.findOwnGoal(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, goal, windowStart(goal.Window, now))
+}
+
+// GetGoalHistory 分页获取目标的完成记录
+func (s *goalService) GetGoalHistory(ctx context.Context, userID, goalID uint64, page, size int) ([]*entity.GoalCompletion, int64, error) {
+	if _, err := s.findOwnGoal(ctx, userID, goalID); err != nil {
+		return nil, 0, err
+	}
+	return s.completionRepo.FindByGoalID(ctx, goalID, page, size)
+}
+
+// HandleRecordChanged 记录变更时重新计算次数类目标在相关周期的达成情况
+// 记录删除或时间修改后次数可能不再满足，此时撤销未结算周期的达成状态
+// 平均时长和健康占比在周期内会上下波动，只在周期结束时结算
+func (s *goalService) HandleRecordChanged(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RecordChanged)
+	if !ok || changed.Record == nil {
+		return
+	}
+
+	recordTimes := []time.Time{changed.Record.RecordTime}
+	if changed.Previous != nil && !changed.Previous.RecordTime.Equal(changed.Record.RecordTime) {
+		recordTimes = append(recordTimes, changed.Previous.RecordTime)
+	}
+
+	goals, err := s.goalRepo.FindByUserID(ctx, changed.Record.UserID, false)
+	if err != nil {
+		log.Printf("检查用户%d的目标失败: %v", changed.Record.UserID, err)
+		return
+	}
+
+	for _, goal := range goals {
+		if goal.Metric != entity.GoalMetricRecordCountMin {
+			continue
+		}
+
+		periods := make(map[time.Time]bool, len(recordTimes))
+		for _, recordTime := range recordTimes {
+			periodStart := windowStart(goal.Window, recordTime)
+			if periods[periodStart] {
+				continue
+			}
+			periods[periodStart] = true
+
+			progress, err := s.evaluate(ctx, goal, periodStart)
+			if err != nil {
+				log.Printf("计算目标%d进度失败: %v", goal.ID, err)
+				continue
+			}
+			if _, err := s.saveCompletion(ctx, goal, progress, false, time.Now()); err != nil {
+				log.Printf("保存目标%d达成记录失败: %v", goal.ID, err)
+			}
+		}
+	}
+}
+
+// SettleGoals 结算所有已结束周期的目标
+func (s *goalService) SettleGoals(ctx context.Context, now time.Time) error {
+	goals, err := s.goalRepo.FindAllActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, goal := range goals {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.settleGoal(ctx, goal, now); err != nil {
+			log.Printf("结算目标%d失败: %v", goal.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d个目标结算失败", failed)
+	}
+	return nil
+}
+
+// settleGoal 从上次结算之后的周期开始，依次结算所有已结束的周期
+func (s *goalService) settleGoal(ctx context.Context, goal *entity.Goal, now time.Time) error {
+	periodStart := windowStart(goal.Window, goal.CreatedAt)
+
+	latest, err := s.completionRepo.FindLatestSettled(ctx, goal.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		periodStart = windowEnd(goal.Window, latest.PeriodStart)
+	}
+
+	for !windowEnd(goal.Window, periodStart).After(now) {
+		progress, err := s.evaluate(ctx, goal, periodStart)
+		if err != nil {
+			return err
+		}
+		if _, err := s.saveCompletion(ctx, goal, progress, true, progress.PeriodEnd); err != nil {
+			return err
+		}
+		periodStart = progress.PeriodEnd
+	}
+	return nil
+}
+
+// saveCompletion 保存周期完成情况，达成状态始终以本次计算的结果为准
+// 首次达成时发布目标达成事件，未结算周期的达成被撤销时发布撤销事件；未达成且未结算的周期不创建记录
+func (s *goalService) saveCompletion(ctx context.Context, goal *entity.Goal, progress *entity.GoalProgress, settle bool, metAt time.Time) (*entity.GoalCompletion, error) {
+	completion, err := s.completionRepo.FindByGoalAndPeriod(ctx, goal.ID, progress.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	isNew := completion == nil
+	if isNew {
+		if !progress.Met && !settle {
+			return nil, nil
+		}
+		completion = &entity.GoalCompletion{
+			GoalID:      goal.ID,
+			UserID:      goal.UserID,
+			PeriodStart: progress.PeriodStart,
+			PeriodEnd:   progress.PeriodEnd,
+		}
+	} else if completion.Settled {
+		// 已结算的周期不再变化
+		return completion, nil
+	}
+
+	firstMet := progress.Met && !completion.Met
+	revoked := !progress.Met && completion.Met
+	completion.Value = progress.Value
+	completion.Settled = settle
+	completion.Met = progress.Met
+	if firstMet {
+		completion.MetAt = &metAt
+	} else if revoked {
+		completion.MetAt = nil
+	}
+
+	if isNew {
+		err = s.completionRepo.Save(ctx, completion)
+	} else {
+		err = s.completionRepo.Update(ctx, completion)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if firstMet {
+		s.publisher.Publish(ctx, &event.GoalMetEvent{Goal: goal, Completion: completion})
+	} else if revoked {
+		s.publisher.Publish(ctx, &event.GoalRevokedEvent{Goal: goal, Completion: completion})
+	}
+	return completion, nil
+}
+
+// evaluate 计算目标在指定周期内的进度
+func (s *goalService) evaluate(ctx context.Context, goal *entity.Goal, periodStart time.Time) (*entity.GoalProgress, error) {
+	periodEnd := windowEnd(goal.Window, periodStart)
+
+	stats, err := s.recordRepo.GetUserRecordStats(ctx, []uint64{goal.UserID}, nil, periodStart, endOfRange(periodEnd))
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &entity.UserRecordStats{UserID: goal.UserID}
+	if len(stats) > 0 {
+		stat = stats[0]
+	}
+
+	progress := &entity.GoalProgress{
+		Goal:        goal,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		RecordCount: stat.RecordCount,
+	}
+
+	var value float64
+	switch goal.Metric {
+	case entity.GoalMetricRecordCountMin:
+		value = float64(stat.RecordCount)
+		progress.Met = value >= goal.Target
+	case entity.GoalMetricAvgDurationMax:
+		if stat.RecordCount == 0 {
+			return progress, nil
+		}
+		value = roundTo(float64(stat.TotalDuration)/float64(stat.RecordCount), 2)
+		progress.Met = value <= goal.Target
+	case entity.GoalMetricHealthyShareMin:
+		if stat.RecordCount == 0 {
+			return progress, nil
+		}
+		value = roundTo(float64(stat.HealthyCount)/float64(stat.RecordCount)*100, 2)
+		progress.Met = value >= goal.Target
+	}
+	progress.Value = &value
+
+	return progress, nil
+}
+
+// findOwnGoal 查找属于当前用户的目标
+func (s *goalService) findOwnGoal(ctx context.Context, userID, goalID uint64) (*entity.Goal, error) {
+	goal, err := s.goalRepo.FindByID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal == nil || goal.UserID != userID {
+		return nil, ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+// validateGoal 校验目标参数
+func validateGoal(goal *entity.Goal) error {
+	if goal.Name == "" || len([]rune(goal.Name)) > 50 {
+		return errors.New("目标名称不能为空且不能超过50个字符")
+	}
+
+	switch goal.Window {
+	case entity.GoalWindowDay, entity.GoalWindowWeek, entity.GoalWindowMonth:
+	default:
+		return errors.New("无效的目标周期，可选值为day、week、month")
+	}
+
+	switch goal.Metric {
+	case entity.GoalMetricRecordCountMin:
+		if goal.Target < 1 || goal.Target != float64(int64(goal.Target)) {
+			return errors.New("记录次数目标必须为正整数")
+		}
+	case entity.GoalMetricAvgDurationMax:
+		if goal.Target <= 0 {
+			return errors.New("平均时长目标必须大于0秒")
+		}
+	case entity.GoalMetricHealthyShareMin:
+		if goal.Target <= 0 || goal.Target > 100 {
+			return errors.New("健康占比目标的取值范围为(0, 100]")
+		}
+	default:
+		return errors.New("无效的目标指标")
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"record-project/infrastructure/wechat"
+	"strings"
+	"time"
+)
+
+// 邀请码规则
+const (
+	inviteCodeLength   = 8
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 去掉了容易混淆的0、O、1、I
+	inviteCodeAttempts = 3                                  // 邀请码重复时的最多重试次数
+	inviteQRCodeWidth  = 430
+)
+
+var (
+	// ErrInviteCodeInvalid 邀请码不存在或已失效
+	ErrInviteCodeInvalid = errors.New("邀请码无效")
+)
+
+// InviteService 好友邀请服务接口
+type InviteService interface {
+	// GetSummary 获取用户的邀请码和邀请人数，没有邀请码时自动生成
+	GetSummary(ctx context.Context, userID uint64) (*entity.InviteSummary, error)
+
+	// RotateCode 更换邀请码，旧的邀请码和小程序码随即失效
+	RotateCode(ctx context.Context, userID uint64) (*entity.InviteSummary, error)
+
+	// GetQRCode 生成用户当前邀请码对应的小程序码，返回图片内容和图片类型
+	GetQRCode(ctx context.Context, userID uint64) ([]byte, string, error)
+
+	// RedeemScene 被邀请人通过小程序码进入时，与邀请人成为好友并为邀请人计数
+	RedeemScene(ctx context.Context, inviteeID uint64, scene string, newUser bool) error
+}
+
+// inviteService 好友邀请服务实现
+type inviteService struct {
+	codeRepo       repository.InviteCodeRepository
+	redemptionRepo repository.InviteRedemptionRepository
+	friendRepo     repository.FriendRepository
+	wechatService  wechat.WechatService
+	invitePage     string
+	publisher      event.Publisher
+}
+
+// NewInviteService 创建好友邀请服务，invitePage为小程序码打开的页面
+func NewInviteService(
+	codeRepo repository.InviteCodeRepository,
+	redemptionRepo repository.InviteRedemptionRepository,
+	friendRepo repository.FriendRepository,
+	wechatService wechat.WechatService,
+	invitePage string,
+	publisher event.Publisher,
+) InviteService {
+	return &inviteService{
+		codeRepo:       codeRepo,
+		redemptionRepo: redemptionRepo,
+		friendRepo:     friendRepo,
+		wechatService:  wechatService,
+		invitePage:     invitePage,
+		publisher:      publisher,
+	}
+}
+
+// GetSummary 获取用户的邀请码和邀请人数
+func (s *inviteService) GetSummary(ctx context.Context, userID uint64) (*entity.InviteSummary, error) {
+	code, err := s.activeCode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(ctx, code)
+}
+
+// RotateCode 更换邀请码
+func (s *inviteService) RotateCode(ctx context.Context, userID uint64) (*entity.InviteSummary, error) {
+	code, err := s.newCode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(ctx, code)
+}
+
+// summary 组装邀请信息
+func (s *inviteService) summary(ctx context.Context, code *entity.InviteCode) (*entity.InviteSummary, error) {
+	total, newUsers, err := s.redemptionRepo.CountByInviterID(ctx, code.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.InviteSummary{
+		Code:         code.Code,
+		Scene:        code.Scene(),
+		InvitedCount: total,
+		NewUserCount: newUsers,
+	}, nil
+}
+
+// activeCode 获取用户当前有效的邀请码，没有时生成
+func (s *inviteService) activeCode(ctx context.Context, userID uint64) (*entity.InviteCode, error) {
+	code, err := s.codeRepo.FindActiveByUserID(ctx, userID)
+	if err != nil || code != nil {
+		return code, err
+	}
+	return s.newCode(ctx, userID)
+}
+
+// newCode 生成新的邀请码，与已有邀请码重复时重试
+func (s *inviteService) newCode(ctx context.Context, userID uint64) (*entity.InviteCode, error) {
+	var lastErr error
+	for i := 0; i < inviteCodeAttempts; i++ {
+		value, err := randomInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		code := &entity.InviteCode{UserID: userID, Code: value}
+		if lastErr = s.codeRepo.Rotate(ctx, code); lastErr == nil {
+			return code, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// randomInviteCode 生成随机邀请码
+func randomInviteCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(inviteCodeAlphabet)))
+	var b strings.Builder
+	for i := 0; i < inviteCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(inviteCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// GetQRCode 生成用户当前邀请码对应的小程序码
+func (s *inviteService) GetQRCode(ctx context.Context, userID uint64) ([]byte, string, error) {
+	code, err := s.activeCode(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.wechatService.GetUnlimitedQRCode(code.Scene(), s.invitePage, inviteQRCodeWidth)
+}
+
+// RedeemScene 被邀请人通过小程序码进入时，与邀请人成为好友并为邀请人计数
+// 已经是好友时不重复计数；双方存在屏蔽关系时无法成为好友
+func (s *inviteService) RedeemScene(ctx context.Context, inviteeID uint64, scene string, newUser bool) error {
+	value, ok := strings.CutPrefix(scene, entity.InviteScenePrefix)
+	if !ok || value == "" {
+		return ErrInviteCodeInvalid
+	}
+
+	code, err := s.codeRepo.FindActiveByCode(ctx, strings.ToUpper(value))
+	if err != nil {
+		return err
+	}
+	if code == nil {
+		return ErrInviteCodeInvalid
+	}
+	if code.UserID == inviteeID {
+		return nil
+	}
+
+	relation, err := s.friendRepo.FindByUserIDAndFriendID(ctx, code.UserID, inviteeID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	switch {
+	case relation == nil:
+		relation = entity.NewFriendRequest(code.UserID, inviteeID, now)
+		relation.AcceptByInvite(now)
+		if err := s.friendRepo.Save(ctx, relation); err != nil {
+			return err
+		}
+	case relation.Status == entity.FriendStatusAccepted:
+		return nil
+	default:
+		// 之前的申请未通过、已被拒绝或已删除，通过邀请直接成为好友
+		// 同一邀请被并发兑换或对方同时处理了申请时只有一个请求能写入，其余请求不再重复发布事件和记录兑换
+		from := relation.Status
+		relation.AcceptByInvite(now)
+		updated, err := s.friendRepo.Update(ctx, relation, from)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrFriendStatusConflict
+		}
+	}
+
+	s.publisher.Publish(ctx, &event.FriendAcceptedEvent{Relation: relation})
+
+	_, err = s.redemptionRepo.Save(ctx, &entity.InviteRedemption{
+		InviterID: code.UserID,
+		InviteeID: inviteeID,
+		Code:      code.Code,
+		NewUser:   newUser,
+	})
+	return err
+}
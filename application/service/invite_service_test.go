@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"record-project/infrastructure/wechat"
+)
+
+// fakeQRCodeImage 模拟微信返回的小程序码图片
+var fakeQRCodeImage = []byte("\x89PNG\r\n\x1a\nfake-qrcode")
+
+// fakeWechatServer 模拟微信的登录、access_token和小程序码接口
+type fakeWechatServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	tokenCalls int
+	qrRequests []map[string]interface{}
+	qrError    string // 不为空时小程序码接口返回该JSON错误
+}
+
+func newFakeWechatServer(t *testing.T) *fakeWechatServer {
+	f := &fakeWechatServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sns/jscode2session", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"openid":      "openid-" + r.URL.Query().Get("js_code"),
+			"session_key": "session",
+		})
+	})
+	mux.HandleFunc("/cgi-bin/token", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.tokenCalls++
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token-1",
+			"expires_in":   7200,
+		})
+	})
+	mux.HandleFunc("/wxa/getwxacodeunlimit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("access_token") != "token-1" {
+			t.Errorf("小程序码请求无效: %s %s", r.Method, r.URL)
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("解析小程序码请求失败: %v", err)
+		}
+
+		f.mu.Lock()
+		f.qrRequests = append(f.qrRequests, payload)
+		qrError := f.qrError
+		f.mu.Unlock()
+
+		if qrError != "" {
+			w.Header().Set("Content-Type", "application/json; encoding=utf-8")
+			w.Write([]byte(qrError))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(fakeQRCodeImage)
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// fakeUserRepo 内存中的用户仓储，只实现登录用到的方法
+type fakeUserRepo struct {
+	repository.UserRepository
+	users []*entity.User
+}
+
+func (r *fakeUserRepo) FindByID(ctx context.Context, id uint64) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) FindByOpenID(ctx context.Context, openID string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.OpenID == openID {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) Save(ctx context.Context, user *entity.User) error {
+	user.ID = uint64(len(r.users) + 1)
+	r.users = append(r.users, user)
+	return nil
+}
+
+// fakeInviteCodeRepo 内存中的邀请码仓储
+type fakeInviteCodeRepo struct {
+	codes []*entity.InviteCode
+}
+
+func (r *fakeInviteCodeRepo) FindActiveByUserID(ctx context.Context, userID uint64) (*entity.InviteCode, error) {
+	for _, code := range r.codes {
+		if code.UserID == userID && code.RevokedAt == nil {
+			return code, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeInviteCodeRepo) FindActiveByCode(ctx context.Context, value string) (*entity.InviteCode, error) {
+	for _, code := range r.codes {
+		if code.Code == value && code.RevokedAt == nil {
+			return code, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeInviteCodeRepo) Rotate(ctx context.Context, code *entity.InviteCode) error {
+	now := time.Now()
+	for _, existing := range r.codes {
+		if existing.UserID == code.UserID && existing.RevokedAt == nil {
+			existing.RevokedAt = &now
+		}
+	}
+	code.ID = uint64(len(r.codes) + 1)
+	r.codes = append(r.codes, code)
+	return nil
+}
+
+// fakeInviteRedemptionRepo 内存中的邀请记录仓储，同一邀请人和被邀请人只记录一次
+type fakeInviteRedemptionRepo struct {
+	redemptions []*entity.InviteRedemption
+}
+
+func (r *fakeInviteRedemptionRepo) Save(ctx context.Context, redemption *entity.InviteRedemption) (bool, error) {
+	for _, existing := range r.redemptions {
+		if existing.InviterID == redemption.InviterID && existing.InviteeID == redemption.InviteeID {
+			return false, nil
+		}
+	}
+	redemption.ID = uint64(len(r.redemptions) + 1)
+	r.redemptions = append(r.redemptions, redemption)
+	return true, nil
+}
+
+func (r *fakeInviteRedemptionRepo) CountByInviterID(ctx context.Context, inviterID uint64) (int64, int64, error) {
+	var total, newUsers int64
+	for _, redemption := range r.redemptions {
+		if redemption.InviterID == inviterID {
+			total++
+			if redemption.NewUser {
+				newUsers++
+			}
+		}
+	}
+	return total, newUsers, nil
+}
+
+// fakeFriendRepo 内存中的好友关系仓储，只实现邀请用到的方法
+type fakeFriendRepo struct {
+	repository.FriendRepository
+	relations []*entity.Friend
+}
+
+func (r *fakeFriendRepo) FindByUserIDAndFriendID(ctx context.Context, userID, friendID uint64) (*entity.Friend, error) {
+	for _, relation := range r.relations {
+		if relation.Involves(userID) && relation.Involves(friendID) {
+			copied := *relation
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeFriendRepo) Save(ctx context.Context, friend *entity.Friend) error {
+	friend.ID = uint64(len(r.relations) + 1)
+	copied := *friend
+	r.relations = append(r.relations, &copied)
+	return nil
+}
+
+func (r *fakeFriendRepo) Update(ctx context.Context, friend *entity.Friend, from entity.FriendStatus) (bool, error) {
+	for i, relation := range r.relations {
+		if relation.ID == friend.ID && relation.Status == from {
+			copied := *friend
+			r.relations[i] = &copied
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordingPublisher 记录发布的事件
+type recordingPublisher struct {
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, e event.Event) {
+	p.events = append(p.events, e)
+}
+
+// inviteFixture 组装邀请和登录所需的服务
+type inviteFixture struct {
+	server      *fakeWechatServer
+	users       *fakeUserRepo
+	codes       *fakeInviteCodeRepo
+	redemptions *fakeInviteRedemptionRepo
+	friends     *fakeFriendRepo
+	publisher   *recordingPublisher
+	invite      InviteService
+	auth        AuthService
+}
+
+func newInviteFixture(t *testing.T) *inviteFixture {
+	f := &inviteFixture{
+		server:      newFakeWechatServer(t),
+		users:       &fakeUserRepo{},
+		codes:       &fakeInviteCodeRepo{},
+		redemptions: &fakeInviteRedemptionRepo{},
+		friends:     &fakeFriendRepo{},
+		publisher:   &recordingPublisher{},
+	}
+	wechatService := wechat.NewWechatService("app-id", "app-secret", f.server.URL)
+	f.invite = NewInviteService(f.codes, f.redemptions, f.friends, wechatService, "pages/invite/index", f.publisher)
+	f.auth = NewAuthService(NewUserService(f.users), wechatService, f.invite)
+	return f
+}
+
+func TestInviteServiceGetQRCode(t *testing.T) {
+	f := newInviteFixture(t)
+	ctx := context.Background()
+
+	summary, err := f.invite.GetSummary(ctx, 1)
+	if err != nil {
+		t.Fatalf("获取邀请信息失败: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		image, contentType, err := f.invite.GetQRCode(ctx, 1)
+		if err != nil {
+			t.Fatalf("生成小程序码失败: %v", err)
+		}
+		if string(image) != string(fakeQRCodeImage) {
+			t.Errorf("图片内容 = %q, 期望 %q", image, fakeQRCodeImage)
+		}
+		if contentType != "image/png" {
+			t.Errorf("图片类型 = %q, 期望 image/png", contentType)
+		}
+	}
+
+	if len(f.server.qrRequests) != 2 {
+		t.Fatalf("小程序码请求次数 = %d, 期望 2", len(f.server.qrRequests))
+	}
+	request := f.server.qrRequests[0]
+	if request["scene"] != "i="+summary.Code {
+		t.Errorf("scene = %v, 期望 %q", request["scene"], "i="+summary.Code)
+	}
+	if request["page"] != "pages/invite/index" {
+		t.Errorf("page = %v, 期望 pages/invite/index", request["page"])
+	}
+	if request["width"] != float64(inviteQRCodeWidth) {
+		t.Errorf("width = %v, 期望 %d", request["width"], inviteQRCodeWidth)
+	}
+	if f.server.tokenCalls != 1 {
+		t.Errorf("access_token请求次数 = %d, 期望 1（有效期内复用）", f.server.tokenCalls)
+	}
+}
+
+func TestInviteServiceGetQRCodeWechatError(t *testing.T) {
+	f := newInviteFixture(t)
+	f.server.qrError = `{"errcode":40001,"errmsg":"invalid credential"}`
+	ctx := context.Background()
+
+	image, contentType, err := f.invite.GetQRCode(ctx, 1)
+	if err == nil {
+		t.Fatalf("期望返回错误，实际返回 %d 字节的 %q", len(image), contentType)
+	}
+	if !strings.Contains(err.Error(), "40001") {
+		t.Errorf("错误信息 = %q, 期望包含微信错误码", err)
+	}
+
+	// access_token失效后下次调用重新获取
+	f.server.qrError = ""
+	if _, _, err := f.invite.GetQRCode(ctx, 1); err != nil {
+		t.Fatalf("重新生成小程序码失败: %v", err)
+	}
+	if f.server.tokenCalls != 2 {
+		t.Errorf("access_token请求次数 = %d, 期望 2", f.server.tokenCalls)
+	}
+}
+
+func TestAuthServiceWechatLoginWithScene(t *testing.T) {
+	f := newInviteFixture(t)
+	ctx := context.Background()
+
+	inviter, _, err := f.auth.WechatLogin(ctx, "inviter", "")
+	if err != nil {
+		t.Fatalf("邀请人登录失败: %v", err)
+	}
+	summary, err := f.invite.GetSummary(ctx, inviter.ID)
+	if err != nil {
+		t.Fatalf("获取邀请信息失败: %v", err)
+	}
+
+	// 重复登录不重复添加好友和计数
+	var invitee *entity.User
+	for i := 0; i < 3; i++ {
+		user, token, err := f.auth.WechatLogin(ctx, "invitee", summary.Scene)
+		if err != nil {
+			t.Fatalf("第%d次登录失败: %v", i+1, err)
+		}
+		if token == "" {
+			t.Fatalf("第%d次登录没有返回token", i+1)
+		}
+		if invitee != nil && user.ID != invitee.ID {
+			t.Fatalf("重复登录创建了新用户: %d != %d", user.ID, invitee.ID)
+		}
+		invitee = user
+	}
+
+	if len(f.friends.relations) != 1 {
+		t.Fatalf("好友关系数 = %d, 期望 1", len(f.friends.relations))
+	}
+	relation := f.friends.relations[0]
+	if relation.UserID != inviter.ID || relation.FriendID != invitee.ID || relation.Status != entity.FriendStatusAccepted {
+		t.Errorf("好友关系 = %d->%d(%d), 期望 %d->%d 已接受", relation.UserID, relation.FriendID, relation.Status, inviter.ID, invitee.ID)
+	}
+
+	if len(f.redemptions.redemptions) != 1 {
+		t.Fatalf("邀请记录数 = %d, 期望 1", len(f.redemptions.redemptions))
+	}
+	redemption := f.redemptions.redemptions[0]
+	if redemption.InviterID != inviter.ID || redemption.InviteeID != invitee.ID || !redemption.NewUser || redemption.Code != summary.Code {
+		t.Errorf("邀请记录 = %+v", redemption)
+	}
+
+	accepted := 0
+	for _, e := range f.publisher.events {
+		if e.EventName() == event.FriendAccepted {
+			accepted++
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("好友通过事件数 = %d, 期望 1", accepted)
+	}
+
+	after, err := f.invite.GetSummary(ctx, inviter.ID)
+	if err != nil {
+		t.Fatalf("获取邀请信息失败: %v", err)
+	}
+	if after.InvitedCount != 1 || after.NewUserCount != 1 {
+		t.Errorf("邀请人数 = %d/%d, 期望 1/1", after.InvitedCount, after.NewUserCount)
+	}
+}
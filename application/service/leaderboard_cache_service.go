@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"record-project/infrastructure/cache"
+	"sync"
+	"time"
+)
+
+// leaderboardBuildPageSize 重建缓存时每次从数据库读取的人数
+const leaderboardBuildPageSize = 1000
+
+// leaderboardBuildLockTTL 重建锁的有效期，重建异常中断时锁到期后自动释放
+const leaderboardBuildLockTTL = time.Minute
+
+// errLeaderboardBuilding 其他实例正在重建榜单，本次查询直接读取数据库
+var errLeaderboardBuilding = errors.New("排行榜缓存正在重建")
+
+// LeaderboardCacheService 排行榜缓存服务接口
+// 缓存当前日/周/月周期的全局排行榜，分页和名次查询在缓存中完成
+// 榜单只统计公开的记录，好友排行榜还要统计好友可见的记录，因此不使用缓存
+type LeaderboardCacheService interface {
+	// IsCurrentPeriod 判断查询区间是否为当前周期至今，只有这类查询可以使用缓存
+	IsCurrentPeriod(period string, start, end, now time.Time) bool
+
+	// GetGlobalRanking 分页获取当前周期的全局排行榜
+	GetGlobalRanking(ctx context.Context, period, metric string, now time.Time, page, pageSize int) ([]*entity.RankingItem, int, error)
+
+	// GetUserGlobalRank 获取用户在当前周期全局排行榜中的名次以及前后相邻的用户
+	GetUserGlobalRank(ctx context.Context, period, metric string, userID uint64, now time.Time) (*entity.RankingPosition, error)
+
+	// HandleRecordChanged 记录变更时增量更新该用户在已缓存榜单中的条目
+	HandleRecordChanged(ctx context.Context, e event.Event)
+
+	// HandleRankingSettingChanged 排行榜设置变更时将用户加入或移出已缓存的榜单
+	HandleRankingSettingChanged(ctx context.Context, e event.Event)
+
+	// HandleRecordFlagChanged 记录被标记或审核后刷新该用户在已缓存榜单中的条目
+	HandleRecordFlagChanged(ctx context.Context, e event.Event)
+}
+
+// leaderboardCacheService 排行榜缓存服务实现
+type leaderboardCacheService struct {
+	store       cache.LeaderboardStore
+	recordRepo  repository.RecordRepository
+	settingRepo repository.RankingSettingRepository
+
+	// buildMu 同一实例内的请求排队等待重建完成后读取缓存，实例之间由存储中的重建锁互斥
+	buildMu sync.Mutex
+}
+
+// NewLeaderboardCacheService 创建排行榜缓存服务
+func NewLeaderboardCacheService(store cache.LeaderboardStore, recordRepo repository.RecordRepository, settingRepo repository.RankingSettingRepository) LeaderboardCacheService {
+	return &leaderboardCacheService{
+		store:       store,
+		recordRepo:  recordRepo,
+		settingRepo: settingRepo,
+	}
+}
+
+// leaderboardKey 榜单缓存键，包含周期开始日期，跨日/周/月后自然切换到新的榜单
+func leaderboardKey(period, metric string, periodStart time.Time) string {
+	return fmt.Sprintf("leaderboard:%s:%s:%s", period, metric, periodStart.In(shanghaiLocation).Format("20060102"))
+}
+
+// IsCurrentPeriod 判断查询区间是否为当前周期至今
+func (s *leaderboardCacheService) IsCurrentPeriod(period string, start, end, now time.Time) bool {
+	if !entity.IsValidRankingPeriod(period) {
+		return false
+	}
+	return start.Equal(windowStart(periodWindow(period), now)) && !end.Before(now)
+}
+
+// GetGlobalRanking 分页获取当前周期的全局排行榜
+func (s *leaderboardCacheService) GetGlobalRanking(ctx context.Context, period, metric string, now time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
+	var entries []cache.RankedEntry
+	var total int
+	err := s.read(ctx, period, metric, now, func(key string) (bool, error) {
+		var ok bool
+		var err error
+		entries, ok, err = s.store.Range(ctx, key, (page-1)*pageSize, pageSize)
+		if err != nil || !ok {
+			return ok, err
+		}
+		total, ok, err = s.store.Count(ctx, key)
+		return ok, err
+	})
+	if errors.Is(err, errLeaderboardBuilding) {
+		start, end := periodRange(period, now)
+		return s.recordRepo.GetGlobalRanking(ctx, metric, start, endOfRange(end), page, pageSize)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return cachedRankingItems(entries, metric), total, nil
+}
+
+// GetUserGlobalRank 获取用户在当前周期全局排行榜中的名次以及前后相邻的用户
+func (s *leaderboardCacheService) GetUserGlobalRank(ctx context.Context, period, metric string, userID uint64, now time.Time) (*entity.RankingPosition, error) {
+	var entries []cache.RankedEntry
+	err := s.read(ctx, period, metric, now, func(key string) (bool, error) {
+		position, ok, err := s.store.Position(ctx, key, userID)
+		if err != nil || !ok || position < 0 {
+			entries = nil
+			return ok, err
+		}
+
+		// 读取用户以及前后各一个用户
+		offset, limit := position-1, 3
+		if position == 0 {
+			offset, limit = 0, 2
+		}
+		entries, ok, err = s.store.Range(ctx, key, offset, limit)
+		return ok, err
+	})
+	if errors.Is(err, errLeaderboardBuilding) {
+		start, end := periodRange(period, now)
+		return s.recordRepo.GetUserGlobalRank(ctx, metric, userID, start, endOfRange(end))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rankingPositionOf(cachedRankingItems(entries, metric), metric, userID), nil
+}
+
+// HandleRecordChanged 记录变更时增量更新该用户在已缓存榜单中的条目
+// 更新记录时新旧记录时间任意一个落在当前周期内都需要刷新
+func (s *leaderboardCacheService) HandleRecordChanged(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RecordChanged)
+	if !ok || changed.Record == nil {
+		return
+	}
+
+	recordTimes := []time.Time{changed.Record.RecordTime}
+	if changed.Previous != nil {
+		recordTimes = append(recordTimes, changed.Previous.RecordTime)
+	}
+	s.refreshRecordTimes(ctx, changed.Record.UserID, recordTimes)
+}
+
+// HandleRecordFlagChanged 记录被标记或审核后刷新该用户在已缓存榜单中的条目
+func (s *leaderboardCacheService) HandleRecordFlagChanged(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RecordFlagChanged)
+	if !ok || changed.Record == nil {
+		return
+	}
+	s.refreshRecordTimes(ctx, changed.Record.UserID, []time.Time{changed.Record.RecordTime})
+}
+
+// refreshRecordTimes 刷新用户在包含任意记录时间的当前周期榜单中的条目
+func (s *leaderboardCacheService) refreshRecordTimes(ctx context.Context, userID uint64, recordTimes []time.Time) {
+	now := time.Now()
+	for _, period := range entity.RankingPeriods {
+		window := periodWindow(period)
+		start := windowStart(window, now)
+		end := windowEnd(window, start)
+
+		for _, t := range recordTimes {
+			if inRange(t, start, end) {
+				s.refreshUserBoards(ctx, period, userID, start, end)
+				break
+			}
+		}
+	}
+}
+
+// HandleRankingSettingChanged 排行榜设置变更时将用户加入或移出所有已缓存的榜单
+func (s *leaderboardCacheService) HandleRankingSettingChanged(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RankingSettingChangedEvent)
+	if !ok || changed.Setting == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, period := range entity.RankingPeriods {
+		window := periodWindow(period)
+		start := windowStart(window, now)
+		s.refreshUserBoards(ctx, period, changed.Setting.UserID, start, windowEnd(window, start))
+	}
+}
+
+// refreshUserBoards 刷新用户在某个周期所有指标榜单中的条目
+func (s *leaderboardCacheService) refreshUserBoards(ctx context.Context, period string, userID uint64, start, end time.Time) {
+	for _, metric := range entity.RankingMetrics {
+		if err := s.refreshUser(ctx, period, metric, userID, start, end); err != nil {
+			// 增量更新失败时删除榜单，下次读取时重建
+			log.Printf("更新用户%d的排行榜缓存(%s/%s)失败: %v", userID, period, metric, err)
+			if err := s.store.Delete(ctx, leaderboardKey(period, metric, start)); err != nil {
+				log.Printf("删除排行榜缓存(%s/%s)失败: %v", period, metric, err)
+			}
+		}
+	}
+}
+
+// refreshUser 重新计算单个用户在榜单中的指标值，没有记录或不出现在全局排行榜时从榜单中移除
+// 榜单不存在且正在重建时标记该用户，由重建方在替换榜单后重新计算，避免重建读取的旧数据覆盖本次变更
+func (s *leaderboardCacheService) refreshUser(ctx context.Context, period, metric string, userID uint64, start, end time.Time) error {
+	key := leaderboardKey(period, metric, start)
+	exists, err := s.store.Exists(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		marked, err := s.store.MarkDirty(ctx, key, userID)
+		if err != nil || !marked {
+			// 没有在重建时，之后的重建会从数据库读取到本次变更
+			return err
+		}
+		// 标记之前重建可能已经完成并取走了标记，此时榜单已存在，由自己更新
+		exists, err = s.store.Exists(ctx, key)
+		if err != nil || !exists {
+			return err
+		}
+	}
+
+	setting, err := s.settingRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if setting != nil && !setting.VisibleInGlobal() {
+		return s.store.Remove(ctx, key, userID)
+	}
+
+	items, _, err := s.recordRepo.GetFriendRanking(ctx, metric, []uint64{userID}, entity.RecordVisibilitiesPublic, start, endOfRange(end), 1, 1)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 || items[0].RecordCount == 0 {
+		return s.store.Remove(ctx, key, userID)
+	}
+	return s.store.Upsert(ctx, key, cache.LeaderboardEntry{
+		UserID:        userID,
+		MetricValue:   items[0].MetricValue,
+		RecordCount:   items[0].RecordCount,
+		TotalDuration: items[0].TotalDuration,
+	})
+}
+
+// periodRange 当前周期的开始和结束时间
+func periodRange(period string, now time.Time) (time.Time, time.Time) {
+	window := periodWindow(period)
+	start := windowStart(window, now)
+	return start, windowEnd(window, start)
+}
+
+// read 读取当前周期的榜单，fn返回ok=false表示榜单不存在，此时从数据库重建后再读取一次
+// 其他实例正在重建时返回errLeaderboardBuilding
+func (s *leaderboardCacheService) read(ctx context.Context, period, metric string, now time.Time, fn func(key string) (bool, error)) error {
+	start, end := periodRange(period, now)
+	key := leaderboardKey(period, metric, start)
+
+	ok, err := fn(key)
+	if err != nil || ok {
+		return err
+	}
+	if err := s.build(ctx, key, period, metric, start, end); err != nil {
+		return err
+	}
+	_, err = fn(key)
+	return err
+}
+
+// build 从数据库重建榜单，替换后重新计算重建期间有变更的用户
+func (s *leaderboardCacheService) build(ctx context.Context, key, period, metric string, start, end time.Time) error {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	// 等待锁期间可能已被其他请求重建
+	exists, err := s.store.Exists(ctx, key)
+	if err != nil || exists {
+		return err
+	}
+
+	token, locked, err := s.store.LockBuild(ctx, key, leaderboardBuildLockTTL)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return errLeaderboardBuilding
+	}
+
+	err = s.replace(ctx, key, metric, start, end)
+	dirty, unlockErr := s.store.UnlockBuild(ctx, key, token)
+	if err != nil {
+		return err
+	}
+	if unlockErr != nil {
+		return unlockErr
+	}
+
+	for _, userID := range dirty {
+		if err := s.refreshUser(ctx, period, metric, userID, start, end); err != nil {
+			// 无法保证榜单包含该用户的变更，删除后由下次读取重新构建
+			if err := s.store.Delete(ctx, key); err != nil {
+				log.Printf("删除排行榜缓存(%s/%s)失败: %v", period, metric, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// replace 从数据库分页读取榜单并整体替换缓存
+func (s *leaderboardCacheService) replace(ctx context.Context, key, metric string, start, end time.Time) error {
+	var entries []cache.LeaderboardEntry
+	for page := 1; ; page++ {
+		items, total, err := s.recordRepo.GetGlobalRanking(ctx, metric, start, endOfRange(end), page, leaderboardBuildPageSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			entries = append(entries, cache.LeaderboardEntry{
+				UserID:        item.UserID,
+				MetricValue:   item.MetricValue,
+				RecordCount:   item.RecordCount,
+				TotalDuration: item.TotalDuration,
+			})
+		}
+		if len(items) < leaderboardBuildPageSize || len(entries) >= total {
+			break
+		}
+	}
+	return s.store.Replace(ctx, key, entries, end)
+}
+
+// cachedRankingItems 将缓存中带名次的条目转换为排行榜项目
+func cachedRankingItems(entries []cache.RankedEntry, metric string) []*entity.RankingItem {
+	items := make([]*entity.RankingItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, newCachedRankingItem(entry.LeaderboardEntry, metric, entry.Rank))
+	}
+	return items
+}
+
+// rankingPositionOf 在排行榜中查找用户的名次以及前后相邻的用户
+func rankingPositionOf(items []*entity.RankingItem, metric string, userID uint64) *entity.RankingPosition {
+	position := &entity.RankingPosition{
+		Me: &entity.RankingItem{UserID: userID, Metric: metric},
+	}
+	for i, item := range items {
+		if item.UserID != userID {
+			continue
+		}
+		position.Me = item
+		if i > 0 {
+			position.Above = items[i-1]
+		}
+		if i+1 < len(items) {
+			position.Below = items[i+1]
+		}
+		break
+	}
+	return position
+}
+
+// newCachedRankingItem 根据缓存条目创建排行榜项目
+func newCachedRankingItem(entry cache.LeaderboardEntry, metric string, rank uint64) *entity.RankingItem {
+	return &entity.RankingItem{
+		UserID:        entry.UserID,
+		Rank:          rank,
+		RecordCount:   entry.RecordCount,
+		TotalDuration: entry.TotalDuration,
+		Metric:        metric,
+		MetricValue:   entry.MetricValue,
+	}
+}
+
+// inRange 判断时间是否在半开区间[start, end)内
+func inRange(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
@@ -0,0 +1,560 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"time"
+)
+
+// 联赛分组规则
+const (
+	leagueDivisionSize  = 20 // 每个分组的目标人数
+	leagueMovementCount = 3  // 每个分组升级、降级的最多人数
+)
+
+var (
+	// ErrLeagueExcluded 用户设置了不参与排行，无法加入联赛
+	ErrLeagueExcluded = errors.New("已设置不参与排行，无法加入联赛")
+)
+
+// LeagueService 赛季联赛服务接口
+type LeagueService interface {
+	// JoinCurrentSeason 加入当前赛季，已参赛时直接返回参赛信息
+	JoinCurrentSeason(ctx context.Context, userID uint64, now time.Time) (*entity.LeagueMembership, error)
+
+	// GetOverview 获取用户当前赛季的段位和所在分组的积分榜
+	GetOverview(ctx context.Context, userID uint64, now time.Time) (*entity.LeagueOverview, error)
+
+	// GetHistory 分页获取用户的参赛历史
+	GetHistory(ctx context.Context, userID uint64, page, size int) ([]*entity.LeagueMembership, int64, error)
+
+	// RolloverSeasons 结算已结束的赛季并开启新赛季，上赛季的参赛用户按升降级结果分组
+	RolloverSeasons(ctx context.Context, now time.Time) error
+}
+
+// leagueService 赛季联赛服务实现
+type leagueService struct {
+	leagueRepo     repository.LeagueRepository
+	divisionRepo   repository.LeagueDivisionRepository
+	membershipRepo repository.LeagueMembershipRepository
+	recordRepo     repository.RecordRepository
+	friendRepo     repository.FriendRepository
+	settingRepo    repository.RankingSettingRepository
+	period         string
+	metric         string
+}
+
+// NewLeagueService 创建赛季联赛服务，period为weekly或monthly，metric为排行榜指标
+// 配置无效时使用按周、按记录次数的赛季
+func NewLeagueService(
+	leagueRepo repository.LeagueRepository,
+	divisionRepo repository.LeagueDivisionRepository,
+	membershipRepo repository.LeagueMembershipRepository,
+	recordRepo repository.RecordRepository,
+	friendRepo repository.FriendRepository,
+	settingRepo repository.RankingSettingRepository,
+	period, metric string,
+) LeagueService {
+	if period != entity.RankingPeriodWeekly && period != entity.RankingPeriodMonthly {
+		period = entity.RankingPeriodWeekly
+	}
+	if !entity.IsValidRankingMetric(metric) {
+		metric = entity.RankingMetricCount
+	}
+	return &leagueService{
+		leagueRepo:     leagueRepo,
+		divisionRepo:   divisionRepo,
+		membershipRepo: membershipRepo,
+		recordRepo:     recordRepo,
+		friendRepo:     friendRepo,
+		settingRepo:    settingRepo,
+		period:         period,
+		metric:         metric,
+	}
+}
+
+// JoinCurrentSeason 加入当前赛季
+// 优先加入同段位中好友最多且未满的分组，都已满时新建分组
+func (s *leagueService) JoinCurrentSeason(ctx context.Context, userID uint64, now time.Time) (*entity.LeagueMembership, error) {
+	setting, err := s.settingRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if setting != nil && !setting.VisibleInFriends() {
+		return nil, ErrLeagueExcluded
+	}
+
+	league, err := s.ensureCurrentSeason(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := s.membershipRepo.FindByLeagueAndUser(ctx, league.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership != nil {
+		return membership, nil
+	}
+
+	// 沿用上一次参赛的结果确定段位，首次参赛从最低段位开始
+	tier := entity.LeagueMinTier
+	previous, err := s.membershipRepo.FindLatestSettledByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		tier = previous.NextTier()
+	}
+
+	division, err := s.pickDivision(ctx, league.ID, tier, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	membership = &entity.LeagueMembership{
+		LeagueID:   league.ID,
+		DivisionID: division.ID,
+		UserID:     userID,
+		Tier:       tier,
+	}
+	if err := s.membershipRepo.Save(ctx, membership); err != nil {
+		// 并发加入时以先保存的为准
+		if existing, findErr := s.membershipRepo.FindByLeagueAndUser(ctx, league.ID, userID); findErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return membership, nil
+}
+
+// pickDivision 为中途加入的用户选择分组
+func (s *leagueService) pickDivision(ctx context.Context, leagueID uint64, tier int, userID uint64) (*entity.LeagueDivision, error) {
+	divisions, err := s.divisionRepo.FindByLeagueID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*entity.LeagueDivision
+	var candidateIDs []uint64
+	for _, division := range divisions {
+		if division.Tier == tier {
+			candidates = append(candidates, division)
+			candidateIDs = append(candidateIDs, division.ID)
+		}
+	}
+
+	counts, err := s.membershipRepo.CountByDivisionIDs(ctx, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	friendIDs, err := s.friendRepo.FindFriendIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	friends := make(map[uint64]bool, len(friendIDs))
+	for _, id := range friendIDs {
+		friends[id] = true
+	}
+
+	var best *entity.LeagueDivision
+	bestFriends := -1
+	for _, division := range candidates {
+		if counts[division.ID] >= leagueDivisionSize {
+			continue
+		}
+
+		friendCount := 0
+		if len(friends) > 0 {
+			members, err := s.membershipRepo.FindByDivisionID(ctx, division.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				if friends[member.UserID] {
+					friendCount++
+				}
+			}
+		}
+
+		// 好友数相同时选人数较少的分组
+		if friendCount > bestFriends || (friendCount == bestFriends && counts[division.ID] < counts[best.ID]) {
+			best, bestFriends = division, friendCount
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	division := &entity.LeagueDivision{LeagueID: leagueID, Tier: tier}
+	if err := s.divisionRepo.Save(ctx, division); err != nil {
+		return nil, err
+	}
+	return division, nil
+}
+
+// GetOverview 获取用户当前赛季的段位和所在分组的积分榜
+func (s *leagueService) GetOverview(ctx context.Context, userID uint64, now time.Time) (*entity.LeagueOverview, error) {
+	league, err := s.ensureCurrentSeason(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &entity.LeagueOverview{
+		League:    league,
+		Standings: []*entity.LeagueStanding{},
+	}
+
+	membership, err := s.membershipRepo.FindByLeagueAndUser(ctx, league.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return overview, nil
+	}
+	overview.Membership = membership
+
+	division, err := s.divisionRepo.FindByID(ctx, membership.DivisionID)
+	if err != nil {
+		return nil, err
+	}
+	if division == nil {
+		return nil, fmt.Errorf("分组%d不存在", membership.DivisionID)
+	}
+	overview.Division = division
+
+	members, err := s.membershipRepo.FindByDivisionID(ctx, division.ID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.divisionStandings(ctx, league, members)
+	if err != nil {
+		return nil, err
+	}
+
+	promote, relegate := leagueMovement(division.Tier, len(items))
+	overview.PromoteCount, overview.RelegateCount = promote, relegate
+	for i, item := range items {
+		standing := &entity.LeagueStanding{RankingItem: item}
+		switch leagueOutcome(i, len(items), promote, relegate, item.MetricValue) {
+		case entity.LeagueOutcomePromoted:
+			standing.Zone = entity.LeagueZonePromotion
+		case entity.LeagueOutcomeRelegated:
+			standing.Zone = entity.LeagueZoneRelegation
+		}
+		overview.Standings = append(overview.Standings, standing)
+	}
+	return overview, nil
+}
+
+// divisionStandings 使用好友排行榜的统计查询计算分组成员在赛季内的排名，分组成员之间不一定互为好友，只统计公开的记录
+func (s *leagueService) divisionStandings(ctx context.Context, league *entity.League, members []*entity.LeagueMembership) ([]*entity.RankingItem, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]uint64, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+
+	items, _, err := s.recordRepo.GetFriendRanking(ctx, league.Metric, userIDs, entity.RecordVisibilitiesPublic, league.StartAt, endOfRange(league.EndAt), 1, len(userIDs))
+	return items, err
+}
+
+// leagueMovement 计算分组的升级和降级人数，最高段位不再升级，最低段位不再降级
+// 分组人数较少时按比例减少，避免大部分人都在升降级区
+func leagueMovement(tier, size int) (promote, relegate int) {
+	count := size / 4
+	if count > leagueMovementCount {
+		count = leagueMovementCount
+	}
+	if tier < entity.LeagueMaxTier {
+		promote = count
+	}
+	if tier > entity.LeagueMinTier {
+		relegate = count
+	}
+	return promote, relegate
+}
+
+// leagueOutcome 根据排序位置计算升降级结果，指标为0的用户不能升级
+func leagueOutcome(index, size, promote, relegate int, metricValue float64) string {
+	if index < promote && metricValue > 0 {
+		return entity.LeagueOutcomePromoted
+	}
+	if index >= size-relegate {
+		return entity.LeagueOutcomeRelegated
+	}
+	return entity.LeagueOutcomeStayed
+}
+
+// GetHistory 分页获取用户的参赛历史
+func (s *leagueService) GetHistory(ctx context.Context, userID uint64, page, size int) ([]*entity.LeagueMembership, int64, error) {
+	memberships, total, err := s.membershipRepo.FindByUserID(ctx, userID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	leagueIDs := make([]uint64, len(memberships))
+	for i, membership := range memberships {
+		leagueIDs[i] = membership.LeagueID
+	}
+	leagues, err := s.leagueRepo.FindByIDs(ctx, leagueIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, membership := range memberships {
+		membership.League = leagues[membership.LeagueID]
+	}
+	return memberships, total, nil
+}
+
+// RolloverSeasons 结算已结束的赛季并开启新赛季
+func (s *leagueService) RolloverSeasons(ctx context.Context, now time.Time) error {
+	if err := s.closeDueSeasons(ctx, now); err != nil {
+		return err
+	}
+
+	_, err := s.ensureCurrentSeason(ctx, now)
+	return err
+}
+
+// closeDueSeasons 结算所有已到结束时间的赛季
+func (s *leagueService) closeDueSeasons(ctx context.Context, now time.Time) error {
+	due, err := s.leagueRepo.FindDueActive(ctx, s.period, now)
+	if err != nil {
+		return err
+	}
+	for _, league := range due {
+		if err := s.closeSeason(ctx, league, now); err != nil {
+			return fmt.Errorf("结算赛季%d失败: %w", league.ID, err)
+		}
+	}
+	return nil
+}
+
+// closeSeason 计算各分组的最终排名和升降级结果，全部保存后再将赛季标记为已结算
+func (s *leagueService) closeSeason(ctx context.Context, league *entity.League, now time.Time) error {
+	divisions, err := s.divisionRepo.FindByLeagueID(ctx, league.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, division := range divisions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		members, err := s.membershipRepo.FindByDivisionID(ctx, division.ID)
+		if err != nil {
+			return err
+		}
+		items, err := s.divisionStandings(ctx, league, members)
+		if err != nil {
+			return err
+		}
+
+		byUser := make(map[uint64]*entity.LeagueMembership, len(members))
+		for _, member := range members {
+			member.Outcome = entity.LeagueOutcomeStayed
+			byUser[member.UserID] = member
+		}
+
+		promote, relegate := leagueMovement(division.Tier, len(items))
+		for i, item := range items {
+			member, ok := byUser[item.UserID]
+			if !ok {
+				continue
+			}
+			member.FinalRank = item.Rank
+			member.MetricValue = item.MetricValue
+			member.Outcome = leagueOutcome(i, len(items), promote, relegate, item.MetricValue)
+		}
+
+		if err := s.membershipRepo.UpdateResults(ctx, members); err != nil {
+			return err
+		}
+	}
+
+	closedAt := now
+	league.Status = entity.LeagueStatusClosed
+	league.ClosedAt = &closedAt
+	return s.leagueRepo.Update(ctx, league)
+}
+
+// ensureCurrentSeason 获取当前赛季，不存在时创建并将上赛季的参赛用户分组
+// 请求可能早于定时任务到达新赛季，创建前先结算已结束的赛季，保证升降级结果能带入新赛季
+func (s *leagueService) ensureCurrentSeason(ctx context.Context, now time.Time) (*entity.League, error) {
+	window := periodWindow(s.period)
+	start := windowStart(window, now)
+
+	league, err := s.leagueRepo.FindByPeriodStart(ctx, s.period, start)
+	if err != nil || league != nil {
+		return league, err
+	}
+
+	if err := s.closeDueSeasons(ctx, now); err != nil {
+		return nil, err
+	}
+
+	league = &entity.League{
+		Period:  s.period,
+		Metric:  s.metric,
+		StartAt: start,
+		EndAt:   windowEnd(window, start),
+		Status:  entity.LeagueStatusActive,
+	}
+
+	divisions, err := s.carryOverDivisions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.leagueRepo.CreateSeason(ctx, league, divisions); err != nil {
+		// 其他实例已创建同一赛季
+		if existing, findErr := s.leagueRepo.FindByPeriodStart(ctx, s.period, start); findErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	log.Printf("已开启%s联赛赛季%s，共%d个分组", s.period, start.Format("2006-01-02"), len(divisions))
+	return league, nil
+}
+
+// carryOverDivisions 按上一个已结算赛季的升降级结果为参赛用户重新分组
+// 已设置不参与排行的用户不再参赛；上赛季仍未结算时不带入任何用户
+func (s *leagueService) carryOverDivisions(ctx context.Context) ([]*entity.LeagueDivision, error) {
+	previous, err := s.leagueRepo.FindLatestClosed(ctx, s.period)
+	if err != nil || previous == nil {
+		return nil, err
+	}
+
+	memberships, err := s.membershipRepo.FindByLeagueID(ctx, previous.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint64, len(memberships))
+	for i, membership := range memberships {
+		userIDs[i] = membership.UserID
+	}
+	members, err := filterFriendRankingMembers(ctx, s.settingRepo, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[uint64]bool, len(members))
+	for _, id := range members {
+		active[id] = true
+	}
+
+	byTier := make(map[int][]uint64)
+	for _, membership := range memberships {
+		if active[membership.UserID] {
+			tier := membership.NextTier()
+			byTier[tier] = append(byTier[tier], membership.UserID)
+		}
+	}
+
+	grouper := &leagueGrouper{friendRepo: s.friendRepo, friends: make(map[uint64][]uint64)}
+	var divisions []*entity.LeagueDivision
+	for tier := entity.LeagueMaxTier; tier >= entity.LeagueMinTier; tier-- {
+		groups, err := grouper.group(ctx, byTier[tier])
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			division := &entity.LeagueDivision{Tier: tier, TierName: entity.LeagueTierName(tier)}
+			for _, userID := range group {
+				division.Members = append(division.Members, &entity.LeagueMembership{UserID: userID, Tier: tier})
+			}
+			divisions = append(divisions, division)
+		}
+	}
+	return divisions, nil
+}
+
+// leagueGrouper 将同一段位的用户分成人数接近的分组
+type leagueGrouper struct {
+	friendRepo repository.FriendRepository
+	friends    map[uint64][]uint64 // 已查询过的好友列表
+}
+
+// group 先从一个用户出发把同段位的好友（及好友的好友）拉入同一分组，人数不足时随机补齐
+func (g *leagueGrouper) group(ctx context.Context, userIDs []uint64) ([][]uint64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	// 分组数按目标人数向上取整，再平均分配，使各分组人数最多相差1
+	count := (len(userIDs) + leagueDivisionSize - 1) / leagueDivisionSize
+	size := (len(userIDs) + count - 1) / count
+
+	shuffled := make([]uint64, len(userIDs))
+	copy(shuffled, userIDs)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	pool := make(map[uint64]bool, len(shuffled))
+	for _, id := range shuffled {
+		pool[id] = true
+	}
+
+	var groups [][]uint64
+	for _, seed := range shuffled {
+		if !pool[seed] {
+			continue
+		}
+		delete(pool, seed)
+		group := []uint64{seed}
+
+		for queue := []uint64{seed}; len(queue) > 0 && len(group) < size; queue = queue[1:] {
+			friendIDs, err := g.friendIDs(ctx, queue[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, friendID := range friendIDs {
+				if len(group) >= size {
+					break
+				}
+				if pool[friendID] {
+					delete(pool, friendID)
+					group = append(group, friendID)
+					queue = append(queue, friendID)
+				}
+			}
+		}
+
+		for _, id := range shuffled {
+			if len(group) >= size {
+				break
+			}
+			if pool[id] {
+				delete(pool, id)
+				group = append(group, id)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// friendIDs 获取用户的好友列表，同一次分组中只查询一次
+func (g *leagueGrouper) friendIDs(ctx context.Context, userID uint64) ([]uint64, error) {
+	if ids, ok := g.friends[userID]; ok {
+		return ids, nil
+	}
+	ids, err := g.friendRepo.FindFriendIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	g.friends[userID] = ids
+	return ids, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/notify"
+	"time"
+)
+
+// NotificationService 站内通知服务接口
+type NotificationService interface {
+	// Send 将通知保存到接收者的收件箱并推送
+	Send(ctx context.Context, notification *entity.Notification) error
+
+	// GetInbox 分页获取用户收件箱中的通知及未读数
+	GetInbox(ctx context.Context, userID uint64, page, size int) ([]*entity.Notification, int64, int64, error)
+
+	// CountUnread 获取用户的未读通知数
+	CountUnread(ctx context.Context, userID uint64) (int64, error)
+
+	// MarkRead 将通知标记为已读，ids 为空时标记全部
+	MarkRead(ctx context.Context, userID uint64, ids []uint64) error
+}
+
+// notificationService 站内通知服务实现
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	notifier         notify.Notifier
+}
+
+// NewNotificationService 创建站内通知服务
+func NewNotificationService(notificationRepo repository.NotificationRepository, userRepo repository.UserRepository, notifier notify.Notifier) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		notifier:         notifier,
+	}
+}
+
+// Send 将通知保存到接收者的收件箱并推送，推送失败只记录日志
+func (s *notificationService) Send(ctx context.Context, notification *entity.Notification) error {
+	if err := s.notificationRepo.Save(ctx, notification); err != nil {
+		return err
+	}
+
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		log.Printf("推送通知%d失败: %v", notification.ID, err)
+	}
+	return nil
+}
+
+// GetInbox 分页获取用户收件箱中的通知及未读数
+func (s *notificationService) GetInbox(ctx context.Context, userID uint64, page, size int) ([]*entity.Notification, int64, int64, error) {
+	notifications, total, err := s.notificationRepo.FindByUserID(ctx, userID, page, size)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// 补充触发通知的用户信息
+	var actorIDs []uint64
+	seen := make(map[uint64]bool)
+	for _, notification := range notifications {
+		if notification.ActorID > 0 && !seen[notification.ActorID] {
+			seen[notification.ActorID] = true
+			actorIDs = append(actorIDs, notification.ActorID)
+		}
+	}
+	if len(actorIDs) > 0 {
+		users, err := s.userRepo.FindByIDs(ctx, actorIDs)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		userMap := make(map[uint64]*entity.User, len(users))
+		for _, user := range users {
+			userMap[user.ID] = user
+		}
+		for _, notification := range notifications {
+			notification.Actor = userMap[notification.ActorID]
+		}
+	}
+
+	return notifications, total, unread, nil
+}
+
+// CountUnread 获取用户的未读通知数
+func (s *notificationService) CountUnread(ctx context.Context, userID uint64) (int64, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+// MarkRead 将通知标记为已读，只会更新属于该用户的通知
+func (s *notificationService) MarkRead(ctx context.Context, userID uint64, ids []uint64) error {
+	return s.notificationRepo.MarkRead(ctx, userID, ids, time.Now())
+}
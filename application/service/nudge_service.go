@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"time"
+)
+
+var (
+	// ErrNudgeSelf 不能戳自己
+	ErrNudgeSelf = errors.New("不能戳自己")
+	// ErrNudgeNotFriend 只能戳已确认的好友
+	ErrNudgeNotFriend = errors.New("只能戳已确认的好友")
+	// ErrNudgeMuted 对方已关闭戳一戳
+	ErrNudgeMuted = errors.New("对方已关闭戳一戳")
+	// ErrNudgeTargetLogged 对方今天已经有记录
+	ErrNudgeTargetLogged = errors.New("对方今天已经记录过了")
+	// ErrNudgeAlreadySent 今天已经戳过对方
+	ErrNudgeAlreadySent = errors.New("今天已经戳过对方了")
+)
+
+// NudgeService 戳一戳服务接口
+type NudgeService interface {
+	// Nudge 提醒今天还没有记录的好友，每个发送者每天对同一好友只能戳一次
+	Nudge(ctx context.Context, senderID, targetID uint64, now time.Time) (*entity.Nudge, error)
+
+	// GetSetting 获取用户的戳一戳设置
+	GetSetting(ctx context.Context, userID uint64) (*entity.NudgeSetting, error)
+
+	// UpdateSetting 更新用户是否接收戳一戳
+	UpdateSetting(ctx context.Context, userID uint64, muted bool) (*entity.NudgeSetting, error)
+}
+
+// nudgeService 戳一戳服务实现
+type nudgeService struct {
+	nudgeRepo           repository.NudgeRepository
+	settingRepo         repository.NudgeSettingRepository
+	friendRepo          repository.FriendRepository
+	userRepo            repository.UserRepository
+	recordService       RecordService
+	notificationService NotificationService
+}
+
+// NewNudgeService 创建戳一戳服务
+func NewNudgeService(
+	nudgeRepo repository.NudgeRepository,
+	settingRepo repository.NudgeSettingRepository,
+	friendRepo repository.FriendRepository,
+	userRepo repository.UserRepository,
+	recordService RecordService,
+	notificationService NotificationService,
+) NudgeService {
+	return &nudgeService{
+		nudgeRepo:           nudgeRepo,
+		settingRepo:         settingRepo,
+		friendRepo:          friendRepo,
+		userRepo:            userRepo,
+		recordService:       recordService,
+		notificationService: notificationService,
+	}
+}
+
+// Nudge 提醒今天还没有记录的好友
+// 是否已记录按发送者可见的记录判断，避免通过戳一戳的结果推断出对方仅自己可见的记录
+func (s *nudgeService) Nudge(ctx context.Context, senderID, targetID uint64, now time.Time) (*entity.Nudge, error) {
+	if senderID == targetID {
+		return nil, ErrNudgeSelf
+	}
+
+	relation, err := s.friendRepo.FindByUserIDAndFriendID(ctx, senderID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if relation == nil || relation.Status != entity.FriendStatusAccepted {
+		return nil, ErrNudgeNotFriend
+	}
+
+	setting, err := s.GetSetting(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if setting.Muted {
+		return nil, ErrNudgeMuted
+	}
+
+	day := startOfDay(now)
+	stats, err := s.recordService.GetUsersDailyRecordStats(ctx, senderID, []uint64{targetID}, day)
+	if err != nil {
+		return nil, err
+	}
+	if stat, ok := stats[targetID]; ok && stat.Count > 0 {
+		return nil, ErrNudgeTargetLogged
+	}
+
+	nudge := &entity.Nudge{SenderID: senderID, TargetID: targetID, Day: day}
+	created, err := s.nudgeRepo.Save(ctx, nudge)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrNudgeAlreadySent
+	}
+
+	sender, err := s.userRepo.FindByID(ctx, senderID)
+	if err != nil {
+		return nil, err
+	}
+	nickname := "你的好友"
+	if sender != nil && sender.Nickname != "" {
+		nickname = sender.Nickname
+	}
+
+	// 戳一戳已保存，通知失败时只记录日志，避免发送者重试时被判定为重复
+	notification := &entity.Notification{
+		UserID:  targetID,
+		ActorID: senderID,
+		Type:    entity.NotificationTypeNudge,
+		Content: fmt.Sprintf("%s戳了戳你，今天还没有记录哦", nickname),
+		RefID:   nudge.ID,
+	}
+	if err := s.notificationService.Send(ctx, notification); err != nil {
+		log.Printf("发送戳一戳%d的通知失败: %v", nudge.ID, err)
+	}
+
+	return nudge, nil
+}
+
+// GetSetting 获取用户的戳一戳设置，没有设置时返回默认的接收
+func (s *nudgeService) GetSetting(ctx context.Context, userID uint64) (*entity.NudgeSetting, error) {
+	setting, err := s.settingRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		return entity.DefaultNudgeSetting(userID), nil
+	}
+	return setting, nil
+}
+
+// UpdateSetting 更新用户是否接收戳一戳
+func (s *nudgeService) UpdateSetting(ctx context.Context, userID uint64, muted bool) (*entity.NudgeSetting, error) {
+	setting := &entity.NudgeSetting{UserID: userID, Muted: muted}
+	if err := s.settingRepo.Save(ctx, setting); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
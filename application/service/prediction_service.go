@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"math"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"time"
+)
+
+const (
+	// predictionLookbackDays 建模使用的历史天数
+	predictionLookbackDays = 90
+	// predictionHalfLifeDays 历史数据权重的半衰期（天），越旧的数据权重越低
+	predictionHalfLifeDays = 14.0
+	// predictionSmoothing 平滑系数（等效天数），数据较少时概率向整体平均值收敛
+	predictionSmoothing = 1.0
+)
+
+// PredictionService 如厕时间预测服务接口
+type PredictionService interface {
+	// PredictNextDay 预测用户未来24小时每个整点时段的如厕概率
+	PredictNextDay(ctx context.Context, userID uint64, now time.Time) (*entity.VisitPrediction, error)
+}
+
+// predictionService 如厕时间预测服务实现
+type predictionService struct {
+	recordRepo repository.RecordRepository
+}
+
+// NewPredictionService 创建如厕时间预测服务
+func NewPredictionService(recordRepo repository.RecordRepository) PredictionService {
+	return &predictionService{
+		recordRepo: recordRepo,
+	}
+}
+
+// circadianProfile 某类日期（工作日/周末）的作息画像
+type circadianProfile struct {
+	hits   [24]float64 // 各小时有记录的加权天数
+	weight float64     // 参与统计的加权天数
+}
+
+// rate 计算某小时有记录的平滑概率，prior为先验概率
+func (p *circadianProfile) rate(hour int, prior float64) float64 {
+	return (p.hits[hour] + predictionSmoothing*prior) / (p.weight + predictionSmoothing)
+}
+
+// PredictNextDay 预测用户未来24小时每个整点时段的如厕概率
+func (s *predictionService) PredictNextDay(ctx context.Context, userID uint64, now time.Time) (*entity.VisitPrediction, error) {
+	today := startOfDay(now)
+	lookbackStart := today.AddDate(0, 0, -predictionLookbackDays)
+
+	// 只使用完整的自然日建模，今天的数据不参与
+	recordTimes, err := s.recordRepo.FindRecordTimes(ctx, userID, lookbackStart, endOfRange(today))
+	if err != nil {
+		return nil, err
+	}
+
+	weekday, weekend, sampleDays := buildCircadianProfiles(recordTimes, today)
+
+	prediction := &entity.VisitPrediction{
+		UserID:      userID,
+		GeneratedAt: now,
+		Hours:       make([]*entity.HourlyVisitProbability, 0, 24),
+		SampleDays:  sampleDays,
+	}
+
+	// 先验概率取所有时段的整体平均值
+	combined := circadianProfile{weight: weekday.weight + weekend.weight}
+	for h := 0; h < 24; h++ {
+		combined.hits[h] = weekday.hits[h] + weekend.hits[h]
+	}
+	prior := 0.0
+	if combined.weight > 0 {
+		for h := 0; h < 24; h++ {
+			prior += combined.hits[h] / combined.weight
+		}
+		prior /= 24
+	}
+
+	slotStart := now.In(shanghaiLocation).Truncate(time.Hour).Add(time.Hour)
+	for i := 0; i < 24; i++ {
+		start := slotStart.Add(time.Duration(i) * time.Hour)
+		hour := start.Hour()
+
+		profileName := entity.ProfileWeekday
+		profile := &weekday
+		if isWeekend(start) {
+			profileName = entity.ProfileWeekend
+			profile = &weekend
+		}
+		// 某类日期还没有样本时，退回使用全部数据
+		if profile.weight == 0 {
+			profile = &combined
+		}
+
+		probability := 0.0
+		if sampleDays > 0 {
+			probability = math.Round(profile.rate(hour, prior)*10000) / 10000
+		}
+
+		slot := &entity.HourlyVisitProbability{
+			Start:       start,
+			Hour:        hour,
+			Profile:     profileName,
+			Probability: probability,
+		}
+		prediction.Hours = append(prediction.Hours, slot)
+		prediction.ExpectedVisits += probability
+
+		if probability > 0 && (prediction.MostLikely == nil || probability > prediction.MostLikely.Probability) {
+			prediction.MostLikely = slot
+		}
+	}
+	prediction.ExpectedVisits = math.Round(prediction.ExpectedVisits*100) / 100
+
+	return prediction, nil
+}
+
+// buildCircadianProfiles 根据历史记录时间构建工作日和周末的作息画像
+// 观察期从第一条记录当天开始到昨天为止，没有记录的日子同样计入分母
+func buildCircadianProfiles(recordTimes []time.Time, today time.Time) (weekday, weekend circadianProfile, sampleDays int) {
+	if len(recordTimes) == 0 {
+		return weekday, weekend, 0
+	}
+
+	// 每天每小时是否有记录
+	visited := make(map[time.Time]*[24]bool)
+	for _, t := range recordTimes {
+		day := startOfDay(t)
+		hours, ok := visited[day]
+		if !ok {
+			hours = &[24]bool{}
+			visited[day] = hours
+		}
+		hours[t.In(shanghaiLocation).Hour()] = true
+	}
+
+	for day := startOfDay(recordTimes[0]); day.Before(today); day = day.AddDate(0, 0, 1) {
+		age := today.Sub(day).Hours() / 24
+		weight := math.Pow(0.5, age/predictionHalfLifeDays)
+
+		profile := &weekday
+		if isWeekend(day) {
+			profile = &weekend
+		}
+		profile.weight += weight
+
+		if hours, ok := visited[day]; ok {
+			for h := 0; h < 24; h++ {
+				if hours[h] {
+					profile.hits[h] += weight
+				}
+			}
+		}
+		sampleDays++
+	}
+
+	return weekday, weekend, sampleDays
+}
+
+// isWeekend 判断东八区日期是否为周末
+func isWeekend(t time.Time) bool {
+	weekday := t.In(shanghaiLocation).Weekday()
+	return weekday == time.Saturday || weekday == time.Sunday
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+)
+
+// RankingSettingService 排行榜隐私设置服务接口
+type RankingSettingService interface {
+	// GetSetting 获取用户的排行榜设置，没有设置时返回默认的公开设置
+	GetSetting(ctx context.Context, userID uint64) (*entity.RankingSetting, error)
+
+	// UpdateSetting 更新用户的排行榜可见性
+	UpdateSetting(ctx context.Context, userID uint64, visibility string) (*entity.RankingSetting, error)
+
+	// FilterFriendMembers 过滤掉不参与排行的用户，用于好友排行榜
+	FilterFriendMembers(ctx context.Context, userIDs []uint64) ([]uint64, error)
+
+	// AnonymizeGlobal 将全局排行榜中设置为匿名的用户替换为匿名信息，查看者自己除外
+	AnonymizeGlobal(ctx context.Context, viewerID uint64, items []*entity.RankingItem) error
+}
+
+// rankingSettingService 排行榜隐私设置服务实现
+type rankingSettingService struct {
+	settingRepo repository.RankingSettingRepository
+	publisher   event.Publisher
+}
+
+// NewRankingSettingService 创建排行榜隐私设置服务
+func NewRankingSettingService(settingRepo repository.RankingSettingRepository, publisher event.Publisher) RankingSettingService {
+	return &rankingSettingService{
+		settingRepo: settingRepo,
+		publisher:   publisher,
+	}
+}
+
+// GetSetting 获取用户的排行榜设置
+func (s *rankingSettingService) GetSetting(ctx context.Context, userID uint64) (*entity.RankingSetting, error) {
+	setting, err := s.settingRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		return entity.DefaultRankingSetting(userID), nil
+	}
+	return setting, nil
+}
+
+// UpdateSetting 更新用户的排行榜可见性，变更后发布事件以便刷新排行榜缓存
+func (s *rankingSettingService) UpdateSetting(ctx context.Context, userID uint64, visibility string) (*entity.RankingSetting, error) {
+	if !entity.IsValidRankingVisibility(visibility) {
+		return nil, errors.New("无效的排行榜可见性，可选值为visible、anonymous、friends_only、excluded")
+	}
+
+	previous, err := s.GetSetting(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := &entity.RankingSetting{UserID: userID, Visibility: visibility}
+	if err := s.settingRepo.Save(ctx, setting); err != nil {
+		return nil, err
+	}
+
+	if previous.Visibility != setting.Visibility {
+		s.publisher.Publish(ctx, &event.RankingSettingChangedEvent{Setting: setting, Previous: previous})
+	}
+	return setting, nil
+}
+
+// FilterFriendMembers 过滤掉不参与排行的用户
+func (s *rankingSettingService) FilterFriendMembers(ctx context.Context, userIDs []uint64) ([]uint64, error) {
+	return filterFriendRankingMembers(ctx, s.settingRepo, userIDs)
+}
+
+// AnonymizeGlobal 将全局排行榜中设置为匿名的用户替换为匿名信息
+// 用户ID同样隐藏，避免通过ID查到真实身份；头像置空，由客户端显示通用头像
+func (s *rankingSettingService) AnonymizeGlobal(ctx context.Context, viewerID uint64, items []*entity.RankingItem) error {
+	userIDs := make([]uint64, 0, len(items))
+	for _, item := range items {
+		if item.UserID != viewerID {
+			userIDs = append(userIDs, item.UserID)
+		}
+	}
+
+	settings, err := s.settingRepo.FindByUserIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.UserID == viewerID {
+			continue
+		}
+		if setting, ok := settings[item.UserID]; ok && setting.Visibility == entity.RankingVisibilityAnonymous {
+			item.UserID = 0
+			item.Nickname = entity.AnonymousNickname
+			item.AvatarURL = ""
+			item.Anonymous = true
+		}
+	}
+	return nil
+}
+
+// filterFriendRankingMembers 过滤掉设置为不参与排行的用户，好友排行榜、快照和回顾共用
+func filterFriendRankingMembers(ctx context.Context, settingRepo repository.RankingSettingRepository, userIDs []uint64) ([]uint64, error) {
+	settings, err := settingRepo.FindByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]uint64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if setting, ok := settings[id]; ok && !setting.VisibleInFriends() {
+			continue
+		}
+		members = append(members, id)
+	}
+	return members, nil
+}
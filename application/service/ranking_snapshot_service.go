@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"sort"
+	"time"
+)
+
+// snapshotPageSize 生成全局排行榜快照时每次读取的人数
+const snapshotPageSize = 500
+
+// maxRankingHistoryLimit 名次历史最多返回的周期数
+const maxRankingHistoryLimit = 100
+
+// RankingSnapshotService 排行榜快照服务接口
+type RankingSnapshotService interface {
+	// TakeDueSnapshots 为所有已结束但尚未生成快照的最近一个日/周/月周期生成全局和好友排行榜快照
+	TakeDueSnapshots(ctx context.Context, now time.Time) error
+
+	// CurrentPeriodRange 获取当前周期开始到周期结束的查询区间（闭区间）
+	CurrentPeriodRange(period string, now time.Time) (start, end time.Time)
+
+	// ResolvePeriod 根据查询区间推断对应的快照周期，区间与任何周期都对不上时返回false
+	ResolvePeriod(start, end time.Time) (period string, periodStart time.Time, ok bool)
+
+	// AttachPreviousRanks 为排行榜项目补充上一个周期的名次和名次变化
+	AttachPreviousRanks(ctx context.Context, scope string, ownerID uint64, period string, periodStart time.Time, metric string, items []*entity.RankingItem) error
+
+	// GetHistory 获取用户最近limit个周期的名次历史（按周期升序）
+	GetHistory(ctx context.Context, scope string, userID uint64, period, metric string, limit int) ([]*entity.RankingSnapshot, error)
+}
+
+// rankingSnapshotService 排行榜快照服务实现
+type rankingSnapshotService struct {
+	snapshotRepo repository.RankingSnapshotRepository
+	recordRepo   repository.RecordRepository
+	friendRepo   repository.FriendRepository
+	userRepo     repository.UserRepository
+	settingRepo  repository.RankingSettingRepository
+	publisher    event.Publisher
+}
+
+// NewRankingSnapshotService 创建排行榜快照服务
+func NewRankingSnapshotService(
+	snapshotRepo repository.RankingSnapshotRepository,
+	recordRepo repository.RecordRepository,
+	friendRepo repository.FriendRepository,
+	userRepo repository.UserRepository,
+	settingRepo repository.RankingSettingRepository,
+	publisher event.Publisher,
+) RankingSnapshotService {
+	return &rankingSnapshotService{
+		snapshotRepo: snapshotRepo,
+		recordRepo:   recordRepo,
+		friendRepo:   friendRepo,
+		userRepo:     userRepo,
+		settingRepo:  settingRepo,
+		publisher:    publisher,
+	}
+}
+
+// periodWindow 将快照周期转换为统计周期粒度
+func periodWindow(period string) string {
+	switch period {
+	case entity.RankingPeriodWeekly:
+		return windowWeek
+	case entity.RankingPeriodMonthly:
+		return windowMonth
+	default:
+		return windowDay
+	}
+}
+
+// TakeDueSnapshots 为最近一个已结束的日/周/月周期生成快照
+// 全局榜最后生成，作为该周期所有好友榜都已生成的标记，中途失败时下次会继续补齐
+// 每个周期和指标只查询一次所有用户的指标值，好友榜在内存中排名后批量写入
+func (s *rankingSnapshotService) TakeDueSnapshots(ctx context.Context, now time.Time) error {
+	// 所有用户的好友榜成员，只在有待生成的榜单时加载一次
+	var members map[uint64][]uint64
+
+	failed := 0
+	for _, period := range entity.RankingPeriods {
+		window := periodWindow(period)
+		end := windowStart(window, now)
+		start := windowStart(window, end.Add(-time.Nanosecond))
+
+		for _, metric := range entity.RankingMetrics {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			done, err := s.snapshotRepo.Exists(ctx, entity.RankingScopeGlobal, 0, period, start, metric)
+			if err != nil {
+				return err
+			}
+			if done {
+				continue
+			}
+
+			if members == nil {
+				if members, err = s.friendBoardMembers(ctx); err != nil {
+					return err
+				}
+			}
+			if err := s.snapshotFriendBoards(ctx, members, period, metric, start, end); err != nil {
+				log.Printf("生成%s好友排行榜快照(%s)失败: %v", period, metric, err)
+				failed++
+				continue
+			}
+			if err := s.snapshotGlobalBoard(ctx, period, metric, start, end); err != nil {
+				log.Printf("生成%s全局排行榜快照(%s)失败: %v", period, metric, err)
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d个排行榜快照生成失败", failed)
+	}
+	return nil
+}
+
+// snapshotGlobalBoard 分批读取全局排行榜并保存快照，不出现在全局排行榜中的用户已在查询时排除
+func (s *rankingSnapshotService) snapshotGlobalBoard(ctx context.Context, period, metric string, start, end time.Time) error {
+	var snapshots []*entity.RankingSnapshot
+	for page := 1; ; page++ {
+		items, total, err := s.recordRepo.GetGlobalRanking(ctx, metric, start, endOfRange(end), page, snapshotPageSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			snapshots = append(snapshots, newRankingSnapshot(entity.RankingScopeGlobal, 0, period, metric, start, end, item, total))
+		}
+		if len(items) < snapshotPageSize || len(snapshots) >= total {
+			break
+		}
+	}
+
+	// 周期内没有任何记录时也写入一条占位快照，避免每次都重新计算
+	if len(snapshots) == 0 {
+		return s.snapshotRepo.SaveBatch(ctx, []*entity.RankingSnapshot{
+			newRankingSnapshot(entity.RankingScopeGlobal, 0, period, metric, start, end, &entity.RankingItem{}, 0),
+		})
+	}
+	if err := s.snapshotRepo.SaveBatch(ctx, snapshots); err != nil {
+		return err
+	}
+
+	s.publisher.Publish(ctx, &event.RankingSnapshotTakenEvent{Period: period, Metric: metric, PeriodStart: start, Snapshots: snapshots})
+	return nil
+}
+
+// friendBoardMembers 获取每个用户的好友榜成员（含自己），已排除设置为不参与好友排行的用户
+func (s *rankingSnapshotService) friendBoardMembers(ctx context.Context) (map[uint64][]uint64, error) {
+	userIDs, err := s.userRepo.FindAllIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	friendIDs, err := s.friendRepo.FindAllFriendIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := filterFriendRankingMembers(ctx, s.settingRepo, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	visibleSet := make(map[uint64]bool, len(visible))
+	for _, id := range visible {
+		visibleSet[id] = true
+	}
+
+	members := make(map[uint64][]uint64, len(userIDs))
+	for _, userID := range userIDs {
+		seen := map[uint64]bool{userID: true}
+		boardIDs := make([]uint64, 0, len(friendIDs[userID])+1)
+		if visibleSet[userID] {
+			boardIDs = append(boardIDs, userID)
+		}
+		for _, friendID := range friendIDs[userID] {
+			if !seen[friendID] && visibleSet[friendID] {
+				seen[friendID] = true
+				boardIDs = append(boardIDs, friendID)
+			}
+		}
+		members[userID] = boardIDs
+	}
+	return members, nil
+}
+
+// snapshotFriendBoards 为每个用户保存好友排行榜（含自己）的快照
+// 所有用户的指标值只查询一次，各好友榜在内存中排名，排名规则与好友排行榜查询一致
+func (s *rankingSnapshotService) snapshotFriendBoards(ctx context.Context, members map[uint64][]uint64, period, metric string, start, end time.Time) error {
+	done, err := s.snapshotRepo.FindOwnerIDs(ctx, entity.RankingScopeFriends, period, start, metric)
+	if err != nil {
+		return err
+	}
+
+	values, err := s.recordRepo.GetRankingValues(ctx, metric, start, endOfRange(end))
+	if err != nil {
+		return err
+	}
+	valueMap := make(map[uint64]*entity.RankingItem, len(values))
+	for _, value := range values {
+		valueMap[value.UserID] = value
+	}
+
+	var snapshots []*entity.RankingSnapshot
+	for ownerID, memberIDs := range members {
+		if done[ownerID] || len(memberIDs) == 0 {
+			continue
+		}
+		items := rankFriendBoard(metric, memberIDs, valueMap)
+		for _, item := range items {
+			snapshots = append(snapshots, newRankingSnapshot(entity.RankingScopeFriends, ownerID, period, metric, start, end, item, len(items)))
+		}
+	}
+	return s.snapshotRepo.SaveBatch(ctx, snapshots)
+}
+
+// rankFriendBoard 对好友榜成员排名，没有记录的成员指标为0
+// 与好友排行榜查询一致：按指标值、记录次数降序和用户ID升序排列，名次为DENSE_RANK
+func rankFriendBoard(metric string, memberIDs []uint64, values map[uint64]*entity.RankingItem) []*entity.RankingItem {
+	items := make([]*entity.RankingItem, len(memberIDs))
+	for i, id := range memberIDs {
+		item := &entity.RankingItem{UserID: id, Metric: metric}
+		if value, ok := values[id]; ok {
+			item.RecordCount = value.RecordCount
+			item.TotalDuration = value.TotalDuration
+			item.MetricValue = value.MetricValue
+		}
+		items[i] = item
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].MetricValue != items[j].MetricValue {
+			return items[i].MetricValue > items[j].MetricValue
+		}
+		if items[i].RecordCount != items[j].RecordCount {
+			return items[i].RecordCount > items[j].RecordCount
+		}
+		return items[i].UserID < items[j].UserID
+	})
+
+	var rank uint64
+	for i, item := range items {
+		if i == 0 || item.MetricValue != items[i-1].MetricValue {
+			rank++
+		}
+		item.Rank = rank
+	}
+	return items
+}
+
+// newRankingSnapshot 根据排行榜项目创建快照
+func newRankingSnapshot(scope string, ownerID uint64, period, metric string, start, end time.Time, item *entity.RankingItem, total int) *entity.RankingSnapshot {
+	return &entity.RankingSnapshot{
+		Scope:       scope,
+		OwnerID:     ownerID,
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Metric:      metric,
+		UserID:      item.UserID,
+		Rank:        item.Rank,
+		MetricValue: item.MetricValue,
+		RecordCount: item.RecordCount,
+		Total:       total,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// CurrentPeriodRange 获取当前周期开始到周期结束的查询区间（闭区间）
+func (s *rankingSnapshotService) CurrentPeriodRange(period string, now time.Time) (time.Time, time.Time) {
+	window := periodWindow(period)
+	start := windowStart(window, now)
+	return start, endOfRange(windowEnd(window, start))
+}
+
+// ResolvePeriod 根据查询区间推断对应的快照周期
+// 区间必须从周期开始时间起算且不跨周期，按日、周、月的顺序匹配最小的周期
+func (s *rankingSnapshotService) ResolvePeriod(start, end time.Time) (string, time.Time, bool) {
+	for _, period := range entity.RankingPeriods {
+		window := periodWindow(period)
+		if !windowStart(window, start).Equal(start) {
+			continue
+		}
+		if end.Before(windowEnd(window, start)) {
+			return period, start, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+// AttachPreviousRanks 为排行榜项目补充上一个周期的名次和名次变化，名次上升时变化为正数
+func (s *rankingSnapshotService) AttachPreviousRanks(ctx context.Context, scope string, ownerID uint64, period string, periodStart time.Time, metric string, items []*entity.RankingItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	previousStart := windowStart(periodWindow(period), periodStart.Add(-time.Nanosecond))
+
+	userIDs := make([]uint64, 0, len(items))
+	for _, item := range items {
+		userIDs = append(userIDs, item.UserID)
+	}
+
+	previous, err := s.snapshotRepo.FindRanks(ctx, scope, ownerID, period, previousStart, metric, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		snapshot, ok := previous[item.UserID]
+		if !ok || snapshot.Rank == 0 {
+			continue
+		}
+		previousRank := snapshot.Rank
+		item.PreviousRank = &previousRank
+		if item.Rank > 0 {
+			delta := int64(previousRank) - int64(item.Rank)
+			item.RankDelta = &delta
+		}
+	}
+	return nil
+}
+
+// GetHistory 获取用户最近limit个周期的名次历史
+func (s *rankingSnapshotService) GetHistory(ctx context.Context, scope string, userID uint64, period, metric string, limit int) ([]*entity.RankingSnapshot, error) {
+	var ownerID uint64
+	switch scope {
+	case entity.RankingScopeGlobal:
+	case entity.RankingScopeFriends:
+		ownerID = userID
+	default:
+		return nil, errors.New("无效的排行榜范围，可选值为global、friends")
+	}
+	if !entity.IsValidRankingPeriod(period) {
+		return nil, errors.New("无效的快照周期，可选值为daily、weekly、monthly")
+	}
+	if !entity.IsValidRankingMetric(metric) {
+		return nil, errors.New("无效的排行指标，可选值为count、total_duration、avg_duration、streak、health_score")
+	}
+	if limit < 1 || limit > maxRankingHistoryLimit {
+		limit = maxRankingHistoryLimit
+	}
+
+	return s.snapshotRepo.FindUserHistory(ctx, scope, ownerID, period, metric, userID, limit)
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"sync"
+	"time"
+)
+
+// RecapService 月度/年度回顾服务接口
+type RecapService interface {
+	// GetRecaps 分页获取用户的回顾列表
+	GetRecaps(ctx context.Context, userID uint64, periodType string, page, size int) ([]*entity.Recap, int64, error)
+
+	// GetRecapByID 通过ID获取回顾
+	GetRecapByID(ctx context.Context, id uint64) (*entity.Recap, error)
+
+	// GenerateRecap 生成用户某个周期的回顾，已生成过则直接返回已有回顾
+	GenerateRecap(ctx context.Context, userID uint64, periodType string, periodStart time.Time) (*entity.Recap, error)
+
+	// GenerateDueRecaps 为周期结束前注册的用户生成上一个自然月和上一个自然年的回顾
+	GenerateDueRecaps(ctx context.Context, now time.Time) error
+}
+
+// recapService 回顾服务实现
+type recapService struct {
+	recapRepo   repository.RecapRepository
+	recordRepo  repository.RecordRepository
+	friendRepo  repository.FriendRepository
+	settingRepo repository.RankingSettingRepository
+
+	// generatedMonth 已为所有用户生成完回顾的月份（当月第一天），新的月份开始前定时任务不再查询
+	generatedMonth time.Time
+	mu             sync.Mutex
+}
+
+// NewRecapService 创建回顾服务
+func NewRecapService(
+	recapRepo repository.RecapRepository,
+	recordRepo repository.RecordRepository,
+	friendRepo repository.FriendRepository,
+	settingRepo repository.RankingSettingRepository,
+) RecapService {
+	return &recapService{
+		recapRepo:   recapRepo,
+		recordRepo:  recordRepo,
+		friendRepo:  friendRepo,
+		settingRepo: settingRepo,
+	}
+}
+
+// GetRecaps 分页获取用户的回顾列表
+func (s *recapService) GetRecaps(ctx context.Context, userID uint64, periodType string, page, size int) ([]*entity.Recap, int64, error) {
+	return s.recapRepo.FindByUserID(ctx, userID, periodType, page, size)
+}
+
+// GetRecapByID 通过ID获取回顾
+func (s *recapService) GetRecapByID(ctx context.Context, id uint64) (*entity.Recap, error) {
+	return s.recapRepo.FindByID(ctx, id)
+}
+
+// GenerateDueRecaps 为周期结束前注册的用户生成上一个自然月和上一个自然年的回顾
+// 只处理还没有回顾的用户；全部生成成功后记下当前月份，直到下个月开始前都不再执行
+func (s *recapService) GenerateDueRecaps(ctx context.Context, now time.Time) error {
+	month := startOfMonth(now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generatedMonth.Equal(month) {
+		return nil
+	}
+
+	lastMonth := month.AddDate(0, -1, 0)
+	lastYear := startOfYear(now).AddDate(-1, 0, 0)
+
+	failed := 0
+	periods := []struct {
+		periodType string
+		name       string
+		start      time.Time
+		end        time.Time
+	}{
+		{entity.RecapPeriodMonth, "月度", lastMonth, month},
+		{entity.RecapPeriodYear, "年度", lastYear, startOfYear(now)},
+	}
+	for _, period := range periods {
+		userIDs, err := s.recapRepo.FindUserIDsWithoutRecap(ctx, period.periodType, period.start, period.end)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, err := s.GenerateRecap(ctx, userID, period.periodType, period.start); err != nil {
+				log.Printf("生成用户%d的%s回顾失败: %v", userID, period.name, err)
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d个回顾生成失败", failed)
+	}
+	s.generatedMonth = month
+	return nil
+}
+
+// GenerateRecap 生成用户某个周期的回顾，已生成过则直接返回已有回顾
+func (s *recapService) GenerateRecap(ctx context.Context, userID uint64, periodType string, periodStart time.Time) (*entity.Recap, error) {
+	var start, end time.Time
+	switch periodType {
+	case entity.RecapPeriodMonth:
+		start = startOfMonth(periodStart)
+		end = start.AddDate(0, 1, 0)
+	case entity.RecapPeriodYear:
+		start = startOfYear(periodStart)
+		end = start.AddDate(1, 0, 0)
+	default:
+		return nil, errors.New("无效的回顾周期类型")
+	}
+
+	if end.After(time.Now()) {
+		return nil, errors.New("周期尚未结束，无法生成回顾")
+	}
+
+	// 回顾生成后不可修改，已存在则直接返回
+	existing, err := s.recapRepo.FindByUserAndPeriod(ctx, userID, periodType, start)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	recap, err := s.buildRecap(ctx, userID, periodType, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recapRepo.Save(ctx, recap); err != nil {
+		return nil, err
+	}
+	return recap, nil
+}
+
+// buildRecap 根据周期内的记录计算回顾内容
+func (s *recapService) buildRecap(ctx context.Context, userID uint64, periodType string, start, end time.Time) (*entity.Recap, error) {
+	records, err := s.recordRepo.FindAllByDateRange(ctx, userID, start, endOfRange(end))
+	if err != nil {
+		return nil, err
+	}
+
+	recap := &entity.Recap{
+		UserID:            userID,
+		PeriodType:        periodType,
+		PeriodStart:       start,
+		PeriodEnd:         end,
+		FavoriteHour:      -1,
+		TypeDistribution:  make(map[uint64]int64),
+		FriendRankHistory: []*entity.RecapRankPoint{},
+		CreatedAt:         time.Now(),
+	}
+
+	dayCounts := make(map[time.Time]int)
+	var hourCounts [24]int
+	for _, record := range records {
+		recap.TotalCount++
+		recap.TotalDuration += int64(record.Duration)
+		dayCounts[startOfDay(record.RecordTime)]++
+		hourCounts[record.RecordTime.In(shanghaiLocation).Hour()]++
+		if record.PoopTypeID > 0 {
+			recap.TypeDistribution[record.PoopTypeID]++
+		}
+		if record.Duration > recap.LongestSession {
+			recap.LongestSession = record.Duration
+			recap.LongestSessionID = record.ID
+		}
+	}
+
+	// 最忙的一天，次数相同时取较早的一天
+	for day, count := range dayCounts {
+		if count > recap.BusiestDayCount || (count == recap.BusiestDayCount && day.Before(*recap.BusiestDay)) {
+			busiestDay := day
+			recap.BusiestDay = &busiestDay
+			recap.BusiestDayCount = count
+		}
+	}
+
+	// 最常记录的小时，次数相同时取较早的小时
+	favoriteCount := 0
+	for hour, count := range hourCounts {
+		if count > favoriteCount {
+			favoriteCount = count
+			recap.FavoriteHour = hour
+		}
+	}
+
+	if err := s.fillStreak(ctx, recap, start, end); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillFriendRankHistory(ctx, recap, start, end); err != nil {
+		return nil, err
+	}
+
+	return recap, nil
+}
+
+// fillStreak 计算周期内达到的最长连续天数，以及是否刷新了历史记录
+func (s *recapService) fillStreak(ctx context.Context, recap *entity.Recap, start, end time.Time) error {
+	recordTimes, err := s.recordRepo.FindRecordTimes(ctx, recap.UserID, time.Unix(0, 0), endOfRange(end))
+	if err != nil {
+		return err
+	}
+
+	// 连续天数可能从周期开始前延续进来，因此按完整历史逐天计算
+	current := 0
+	days := recordDays(recordTimes)
+	for i, day := range days {
+		if i > 0 && isNextDay(days[i-1], day) {
+			current++
+		} else {
+			current = 1
+		}
+
+		if day.Before(start) {
+			if current > recap.PreviousBestStreak {
+				recap.PreviousBestStreak = current
+			}
+		} else if current > recap.LongestStreak {
+			recap.LongestStreak = current
+		}
+	}
+
+	recap.NewStreakRecord = recap.LongestStreak > recap.PreviousBestStreak
+	return nil
+}
+
+// fillFriendRankHistory 计算好友排名变化：月度回顾按周、年度回顾按月统计
+// 好友关系没有历史版本，因此使用生成回顾时的好友列表
+func (s *recapService) fillFriendRankHistory(ctx context.Context, recap *entity.Recap, start, end time.Time) error {
+	friendIDs, err := s.friendRepo.FindFriendIDs(ctx, recap.UserID)
+	if err != nil {
+		return err
+	}
+	if len(friendIDs) == 0 {
+		return nil
+	}
+
+	// 不参与排行的好友不计入名次，自己不参与排行时不生成排名变化
+	userIDs, err := filterFriendRankingMembers(ctx, s.settingRepo, append([]uint64{recap.UserID}, friendIDs...))
+	if err != nil {
+		return err
+	}
+	if len(userIDs) == 0 || userIDs[0] != recap.UserID {
+		return nil
+	}
+
+	for segmentStart := start; segmentStart.Before(end); {
+		var segmentEnd time.Time
+		if recap.PeriodType == entity.RecapPeriodYear {
+			segmentEnd = segmentStart.AddDate(0, 1, 0)
+		} else {
+			segmentEnd = segmentStart.AddDate(0, 0, 7)
+		}
+		if segmentEnd.After(end) {
+			segmentEnd = end
+		}
+
+		items, total, err := s.recordRepo.GetFriendRanking(ctx, entity.RankingMetricCount, userIDs, entity.RecordVisibilitiesFriends, segmentStart, endOfRange(segmentEnd), 1, len(userIDs))
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if item.UserID == recap.UserID {
+				recap.FriendRankHistory = append(recap.FriendRankHistory, &entity.RecapRankPoint{
+					Start:       segmentStart,
+					End:         segmentEnd,
+					Rank:        item.Rank,
+					Total:       total,
+					RecordCount: item.RecordCount,
+				})
+				break
+			}
+		}
+
+		segmentStart = segmentEnd
+	}
+
+	return nil
+}
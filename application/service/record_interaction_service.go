@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"record-project/domain/entity"
+	"record-project/domain/event"
+	"record-project/domain/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// interactionRateWindow 回应和评论的限流窗口
+	interactionRateWindow = time.Minute
+	// maxReactionsPerWindow 限流窗口内每个用户最多添加的回应数
+	maxReactionsPerWindow = 30
+	// maxCommentsPerWindow 限流窗口内每个用户最多发表的评论数
+	maxCommentsPerWindow = 10
+)
+
+var (
+	// ErrInteractionRecordNotFound 记录不存在或查看者不可见
+	ErrInteractionRecordNotFound = errors.New("记录不存在")
+	// ErrInteractionNotFriend 只有记录主人和好友可以回应和评论
+	ErrInteractionNotFriend = errors.New("只有好友可以回应和评论")
+	// ErrReactionEmojiInvalid 不支持的表情
+	ErrReactionEmojiInvalid = errors.New("不支持的表情，可选值为💩、👍、😂")
+	// ErrCommentContentInvalid 评论内容为空或过长
+	ErrCommentContentInvalid = errors.New("评论内容不能为空且不能超过200字")
+	// ErrCommentNotFound 评论不存在或不属于该记录
+	ErrCommentNotFound = errors.New("评论不存在")
+	// ErrCommentDeleteForbidden 只有评论者和记录主人可以删除评论
+	ErrCommentDeleteForbidden = errors.New("无权删除此评论")
+	// ErrInteractionRateLimited 操作过于频繁
+	ErrInteractionRateLimited = errors.New("操作过于频繁，请稍后再试")
+)
+
+// RecordInteractionService 记录回应和评论服务接口
+type RecordInteractionService interface {
+	// AddReaction 对记录添加表情回应，已回应过同一表情时不重复添加
+	AddReaction(ctx context.Context, viewerID, recordID uint64, emoji string) (*entity.RecordInteractions, error)
+
+	// RemoveReaction 取消对记录的表情回应
+	RemoveReaction(ctx context.Context, viewerID, recordID uint64, emoji string) (*entity.RecordInteractions, error)
+
+	// GetReactions 获取记录的全部回应（含回应的用户）
+	GetReactions(ctx context.Context, viewerID, recordID uint64) ([]*entity.RecordReaction, error)
+
+	// GetComments 分页获取记录的一级评论及其回复
+	GetComments(ctx context.Context, viewerID, recordID uint64, page, size int) ([]*entity.RecordComment, int64, error)
+
+	// AddComment 发表评论，parentID 不为0时回复该评论
+	AddComment(ctx context.Context, viewerID, recordID uint64, content string, parentID uint64) (*entity.RecordComment, error)
+
+	// DeleteComment 删除评论，评论者和记录主人可以删除
+	DeleteComment(ctx context.Context, viewerID, recordID, commentID uint64) error
+
+	// GetInteractions 批量获取记录的回应数、评论数和查看者自己的回应
+	GetInteractions(ctx context.Context, viewerID uint64, recordIDs []uint64) (map[uint64]*entity.RecordInteractions, error)
+
+	// HandleRecordDeleted 记录删除后清理其回应和评论
+	HandleRecordDeleted(ctx context.Context, e event.Event)
+}
+
+// recordInteractionService 记录回应和评论服务实现
+type recordInteractionService struct {
+	reactionRepo repository.RecordReactionRepository
+	commentRepo  repository.RecordCommentRepository
+	recordRepo   repository.RecordRepository
+	userRepo     repository.UserRepository
+	visibility   RecordVisibilityService
+}
+
+// NewRecordInteractionService 创建记录回应和评论服务
+func NewRecordInteractionService(
+	reactionRepo repository.RecordReactionRepository,
+	commentRepo repository.RecordCommentRepository,
+	recordRepo repository.RecordRepository,
+	userRepo repository.UserRepository,
+	visibility RecordVisibilityService,
+) RecordInteractionService {
+	return &recordInteractionService{
+		reactionRepo: reactionRepo,
+		commentRepo:  commentRepo,
+		recordRepo:   recordRepo,
+		userRepo:     userRepo,
+		visibility:   visibility,
+	}
+}
+
+// findVisibleRecord 查找查看者可见的记录，interact 为true时还要求查看者是记录主人或好友
+func (s *recordInteractionService) findVisibleRecord(ctx context.Context, viewerID, recordID uint64, interact bool) (*entity.Record, error) {
+	record, err := s.recordRepo.FindByID(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrInteractionRecordNotFound
+	}
+
+	accesses, err := s.visibility.ResolveAccess(ctx, viewerID, []uint64{record.UserID})
+	if err != nil {
+		return nil, err
+	}
+	access := accesses[record.UserID]
+	if access == nil || !access.CanView(record) {
+		return nil, ErrInteractionRecordNotFound
+	}
+	if interact && !access.Self && !access.Friend {
+		return nil, ErrInteractionNotFriend
+	}
+	return record, nil
+}
+
+// AddReaction 对记录添加表情回应
+func (s *recordInteractionService) AddReaction(ctx context.Context, viewerID, recordID uint64, emoji string) (*entity.RecordInteractions, error) {
+	if !entity.IsValidReactionEmoji(emoji) {
+		return nil, ErrReactionEmojiInvalid
+	}
+	if _, err := s.findVisibleRecord(ctx, viewerID, recordID, true); err != nil {
+		return nil, err
+	}
+
+	recent, err := s.reactionRepo.CountByUserSince(ctx, viewerID, time.Now().Add(-interactionRateWindow))
+	if err != nil {
+		return nil, err
+	}
+	if recent >= maxReactionsPerWindow {
+		return nil, ErrInteractionRateLimited
+	}
+
+	if _, err := s.reactionRepo.Add(ctx, &entity.RecordReaction{RecordID: recordID, UserID: viewerID, Emoji: emoji}); err != nil {
+		return nil, err
+	}
+	return s.interactionsOf(ctx, viewerID, recordID)
+}
+
+// RemoveReaction 取消对记录的表情回应，没有回应过时直接返回当前汇总
+func (s *recordInteractionService) RemoveReaction(ctx context.Context, viewerID, recordID uint64, emoji string) (*entity.RecordInteractions, error) {
+	if !entity.IsValidReactionEmoji(emoji) {
+		return nil, ErrReactionEmojiInvalid
+	}
+	if _, err := s.findVisibleRecord(ctx, viewerID, recordID, false); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.reactionRepo.Remove(ctx, recordID, viewerID, emoji); err != nil {
+		return nil, err
+	}
+	return s.interactionsOf(ctx, viewerID, recordID)
+}
+
+// interactionsOf 获取单条记录的回应和评论汇总
+func (s *recordInteractionService) interactionsOf(ctx context.Context, viewerID, recordID uint64) (*entity.RecordInteractions, error) {
+	interactions, err := s.GetInteractions(ctx, viewerID, []uint64{recordID})
+	if err != nil {
+		return nil, err
+	}
+	return interactions[recordID], nil
+}
+
+// GetReactions 获取记录的全部回应
+func (s *recordInteractionService) GetReactions(ctx context.Context, viewerID, recordID uint64) ([]*entity.RecordReaction, error) {
+	if _, err := s.findVisibleRecord(ctx, viewerID, recordID, false); err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.reactionRepo.FindByRecordID(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint64, 0, len(reactions))
+	for _, reaction := range reactions {
+		userIDs = append(userIDs, reaction.UserID)
+	}
+	userMap, err := s.findUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, reaction := range reactions {
+		reaction.User = userMap[reaction.UserID]
+	}
+	return reactions, nil
+}
+
+// GetComments 分页获取记录的一级评论及其回复
+func (s *recordInteractionService) GetComments(ctx context.Context, viewerID, recordID uint64, page, size int) ([]*entity.RecordComment, int64, error) {
+	if _, err := s.findVisibleRecord(ctx, viewerID, recordID, false); err != nil {
+		return nil, 0, err
+	}
+
+	roots, total, err := s.commentRepo.FindRootsByRecordID(ctx, recordID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rootIDs := make([]uint64, 0, len(roots))
+	for _, root := range roots {
+		rootIDs = append(rootIDs, root.ID)
+	}
+	replies, err := s.commentRepo.FindRepliesByParentIDs(ctx, rootIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 补充评论者和被回复者的用户信息
+	var userIDs []uint64
+	for _, root := range roots {
+		root.Replies = replies[root.ID]
+		userIDs = append(userIDs, root.UserID)
+		for _, reply := range root.Replies {
+			userIDs = append(userIDs, reply.UserID, reply.ReplyToUserID)
+		}
+	}
+	userMap, err := s.findUsers(ctx, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, root := range roots {
+		root.User = userMap[root.UserID]
+		for _, reply := range root.Replies {
+			reply.User = userMap[reply.UserID]
+			reply.ReplyToUser = userMap[reply.ReplyToUserID]
+		}
+	}
+
+	return roots, total, nil
+}
+
+// AddComment 发表评论
+// 回复统一挂在一级评论下，回复其他回复时记录被回复的用户
+func (s *recordInteractionService) AddComment(ctx context.Context, viewerID, recordID uint64, content string, parentID uint64) (*entity.RecordComment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > entity.RecordCommentMaxLength {
+		return nil, ErrCommentContentInvalid
+	}
+	if _, err := s.findVisibleRecord(ctx, viewerID, recordID, true); err != nil {
+		return nil, err
+	}
+
+	comment := &entity.RecordComment{RecordID: recordID, UserID: viewerID, Content: content}
+	if parentID > 0 {
+		parent, err := s.commentRepo.FindByID(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil || parent.RecordID != recordID {
+			return nil, ErrCommentNotFound
+		}
+		comment.ParentID = parent.ID
+		if parent.ParentID > 0 {
+			comment.ParentID = parent.ParentID
+		}
+		comment.ReplyToUserID = parent.UserID
+	}
+
+	recent, err := s.commentRepo.CountByUserSince(ctx, viewerID, time.Now().Add(-interactionRateWindow))
+	if err != nil {
+		return nil, err
+	}
+	if recent >= maxCommentsPerWindow {
+		return nil, ErrInteractionRateLimited
+	}
+
+	if err := s.commentRepo.Save(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	userMap, err := s.findUsers(ctx, []uint64{comment.UserID, comment.ReplyToUserID})
+	if err != nil {
+		return nil, err
+	}
+	comment.User = userMap[comment.UserID]
+	comment.ReplyToUser = userMap[comment.ReplyToUserID]
+	return comment, nil
+}
+
+// DeleteComment 删除评论，评论者和记录主人可以删除
+// 删除只看身份，不再要求记录对查看者可见，以便失去访问权限后仍能删除自己的评论
+func (s *recordInteractionService) DeleteComment(ctx context.Context, viewerID, recordID, commentID uint64) error {
+	comment, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment == nil || comment.RecordID != recordID {
+		return ErrCommentNotFound
+	}
+
+	if comment.UserID != viewerID {
+		record, err := s.recordRepo.FindByID(ctx, recordID)
+		if err != nil {
+			return err
+		}
+		if record == nil || record.UserID != viewerID {
+			return ErrCommentDeleteForbidden
+		}
+	}
+
+	return s.commentRepo.Delete(ctx, commentID)
+}
+
+// GetInteractions 批量获取记录的回应数、评论数和查看者自己的回应
+func (s *recordInteractionService) GetInteractions(ctx context.Context, viewerID uint64, recordIDs []uint64) (map[uint64]*entity.RecordInteractions, error) {
+	result := make(map[uint64]*entity.RecordInteractions, len(recordIDs))
+	if len(recordIDs) == 0 {
+		return result, nil
+	}
+
+	reactionCounts, err := s.reactionRepo.CountByRecordIDs(ctx, recordIDs)
+	if err != nil {
+		return nil, err
+	}
+	myReactions, err := s.reactionRepo.FindEmojisByUser(ctx, recordIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	commentCounts, err := s.commentRepo.CountByRecordIDs(ctx, recordIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, recordID := range recordIDs {
+		interactions := entity.NewRecordInteractions()
+		for emoji, count := range reactionCounts[recordID] {
+			interactions.Reactions[emoji] = count
+		}
+		if emojis, ok := myReactions[recordID]; ok {
+			interactions.MyReactions = emojis
+		}
+		interactions.CommentCount = commentCounts[recordID]
+		result[recordID] = interactions
+	}
+	return result, nil
+}
+
+// findUsers 批量查询用户，忽略为0的ID
+func (s *recordInteractionService) findUsers(ctx context.Context, userIDs []uint64) (map[uint64]*entity.User, error) {
+	ids := make([]uint64, 0, len(userIDs))
+	seen := make(map[uint64]bool, len(userIDs))
+	for _, id := range userIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	userMap := make(map[uint64]*entity.User, len(ids))
+	if len(ids) == 0 {
+		return userMap, nil
+	}
+	users, err := s.userRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	return userMap, nil
+}
+
+// HandleRecordDeleted 记录删除后清理其回应和评论
+func (s *recordInteractionService) HandleRecordDeleted(ctx context.Context, e event.Event) {
+	changed, ok := e.(*event.RecordChanged)
+	if !ok || changed.Record == nil || changed.Name != event.RecordDeleted {
+		return
+	}
+
+	if err := s.reactionRepo.DeleteByRecordID(ctx, changed.Record.ID); err != nil {
+		log.Printf("删除记录%d的回应失败: %v", changed.Record.ID, err)
+	}
+	if err := s.commentRepo.DeleteByRecordID(ctx, changed.Record.ID); err != nil {
+		log.Printf("删除记录%d的评论失败: %v", changed.Record.ID, err)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"record-project/domain/entity"
+	"record-project/domain/event"
 	"record-project/domain/repository"
 	"time"
 )
@@ -10,8 +11,16 @@ import (
 // RecordService 记录服务接口
 type RecordService interface {
 	GetRecordByID(ctx context.Context, id uint64) (*entity.Record, error)
-	GetRecordsByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Record, int64, error)
-	GetRecordsByDateRange(ctx context.Context, userID uint64, start, end time.Time, page, size int) ([]*entity.Record, error)
+
+	// GetVisibleRecord 获取查看者可见的记录，不存在或不可见时返回nil
+	GetVisibleRecord(ctx context.Context, viewerID, id uint64) (*entity.Record, error)
+
+	// GetRecordsByUserID 分页获取用户的记录，只返回查看者可见的记录
+	GetRecordsByUserID(ctx context.Context, viewerID, userID uint64, page, size int) ([]*entity.Record, int64, error)
+
+	// GetRecordsByDateRange 分页获取用户在日期范围内的记录，只返回查看者可见的记录
+	GetRecordsByDateRange(ctx context.Context, viewerID, userID uint64, start, end time.Time, page, size int) ([]*entity.Record, error)
+
 	CreateRecord(ctx context.Context, record *entity.Record) error
 	UpdateRecord(ctx context.Context, record *entity.Record) error
 	DeleteRecord(ctx context.Context, id uint64) error
@@ -19,26 +28,39 @@ type RecordService interface {
 	GetRecordTags(ctx context.Context, recordID uint64) ([]*entity.Tag, error)
 	CreateRecordWithTags(ctx context.Context, record *entity.Record, tagIDs []uint64) error
 	UpdateRecordWithTags(ctx context.Context, record *entity.Record, tagIDs []uint64) error
-	CountRecordsByDateRange(ctx context.Context, userID uint64, start, end time.Time) (int64, error)
-	GetGlobalRanking(ctx context.Context, start, end time.Time, limit int) ([]*entity.RankingItem, error)
-	GetFriendRanking(ctx context.Context, userIDs []uint64, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error)
-	GetUsersDailyRecordStats(ctx context.Context, userIDs []uint64, date time.Time) (map[uint64]*entity.DailyRecordStats, error)
+
+	// CountRecordsByDateRange 统计用户在日期范围内查看者可见的记录数
+	CountRecordsByDateRange(ctx context.Context, viewerID, userID uint64, start, end time.Time) (int64, error)
+
+	GetGlobalRanking(ctx context.Context, metric string, start, end time.Time, page, pageSize int) ([]*entity.RankingItem, int, error)
+	GetUserGlobalRank(ctx context.Context, metric string, userID uint64, start, end time.Time) (*entity.RankingPosition, error)
+	GetFriendRanking(ctx context.Context, metric string, userIDs []uint64, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error)
+	GetUserFriendRank(ctx context.Context, metric string, userID uint64, userIDs []uint64, start, end time.Time) (*entity.RankingPosition, error)
+
+	// GetUsersDailyRecordStats 批量获取用户当天的记录统计，只统计查看者可见的记录
+	GetUsersDailyRecordStats(ctx context.Context, viewerID uint64, userIDs []uint64, date time.Time) (map[uint64]*entity.DailyRecordStats, error)
 }
 
 // recordService 记录服务实现
 type recordService struct {
 	recordRepo    repository.RecordRepository
 	recordTagRepo repository.RecordTagRepository
+	publisher     event.Publisher
+	visibility    RecordVisibilityService
 }
 
 // NewRecordService 创建记录服务
 func NewRecordService(
 	recordRepo repository.RecordRepository,
 	recordTagRepo repository.RecordTagRepository,
+	publisher event.Publisher,
+	visibility RecordVisibilityService,
 ) RecordService {
 	return &recordService{
 		recordRepo:    recordRepo,
 		recordTagRepo: recordTagRepo,
+		publisher:     publisher,
+		visibility:    visibility,
 	}
 }
 
@@ -47,29 +69,91 @@ func (s *recordService) GetRecordByID(ctx context.Context, id uint64) (*entity.R
 	return s.recordRepo.FindByID(ctx, id)
 }
 
+// GetVisibleRecord 获取查看者可见的记录
+func (s *recordService) GetVisibleRecord(ctx context.Context, viewerID, id uint64) (*entity.Record, error) {
+	record, err := s.recordRepo.FindByID(ctx, id)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	access, err := s.accessTo(ctx, viewerID, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.CanView(record) {
+		return nil, nil
+	}
+	return record, nil
+}
+
 // GetRecordsByUserID 根据用户ID获取记录列表
-func (s *recordService) GetRecordsByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Record, int64, error) {
-	return s.recordRepo.FindByUserID(ctx, userID, page, size)
+func (s *recordService) GetRecordsByUserID(ctx context.Context, viewerID, userID uint64, page, size int) ([]*entity.Record, int64, error) {
+	access, err := s.accessTo(ctx, viewerID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.recordRepo.FindByUserID(ctx, userID, access, page, size)
 }
 
 // GetRecordsByDateRange 根据日期范围获取记录
-func (s *recordService) GetRecordsByDateRange(ctx context.Context, userID uint64, start, end time.Time, page, size int) ([]*entity.Record, error) {
-	return s.recordRepo.FindByDateRange(ctx, userID, start, end, page, size)
+func (s *recordService) GetRecordsByDateRange(ctx context.Context, viewerID, userID uint64, start, end time.Time, page, size int) ([]*entity.Record, error) {
+	access, err := s.accessTo(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.recordRepo.FindByDateRange(ctx, userID, access, start, end, page, size)
+}
+
+// accessTo 计算查看者对某个用户记录的访问权限
+func (s *recordService) accessTo(ctx context.Context, viewerID, ownerID uint64) (*entity.RecordAccess, error) {
+	accesses, err := s.visibility.ResolveAccess(ctx, viewerID, []uint64{ownerID})
+	if err != nil {
+		return nil, err
+	}
+	return accesses[ownerID], nil
 }
 
 // CreateRecord 创建记录
 func (s *recordService) CreateRecord(ctx context.Context, record *entity.Record) error {
-	return s.recordRepo.Save(ctx, record)
+	if err := s.visibility.ApplyVisibility(ctx, record, nil); err != nil {
+		return err
+	}
+	if err := s.recordRepo.Save(ctx, record); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, &event.RecordChanged{Name: event.RecordCreated, Record: record})
+	return nil
 }
 
 // UpdateRecord 更新记录
 func (s *recordService) UpdateRecord(ctx context.Context, record *entity.Record) error {
-	return s.recordRepo.Update(ctx, record)
+	previous, err := s.recordRepo.FindByID(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.visibility.ApplyVisibility(ctx, record, previous); err != nil {
+		return err
+	}
+	if err := s.recordRepo.Update(ctx, record); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, &event.RecordChanged{Name: event.RecordUpdated, Record: record, Previous: previous})
+	return nil
 }
 
 // DeleteRecord 删除记录
 func (s *recordService) DeleteRecord(ctx context.Context, id uint64) error {
-	return s.recordRepo.Delete(ctx, id)
+	previous, err := s.recordRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.recordRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if previous != nil {
+		s.publisher.Publish(ctx, &event.RecordChanged{Name: event.RecordDeleted, Record: previous, Previous: previous})
+	}
+	return nil
 }
 
 // SaveRecordTags 保存记录标签关联
@@ -83,28 +167,65 @@ func (s *recordService) GetRecordTags(ctx context.Context, recordID uint64) ([]*
 }
 
 func (s *recordService) CreateRecordWithTags(ctx context.Context, record *entity.Record, tagIDs []uint64) error {
-	return s.recordRepo.CreateWithTags(ctx, record, tagIDs, s.recordTagRepo)
+	if err := s.visibility.ApplyVisibility(ctx, record, nil); err != nil {
+		return err
+	}
+	if err := s.recordRepo.CreateWithTags(ctx, record, tagIDs, s.recordTagRepo); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, &event.RecordChanged{Name: event.RecordCreated, Record: record})
+	return nil
 }
 
 func (s *recordService) UpdateRecordWithTags(ctx context.Context, record *entity.Record, tagIDs []uint64) error {
-	return s.recordRepo.UpdateWithTags(ctx, record, tagIDs, s.recordTagRepo)
+	previous, err := s.recordRepo.FindByID(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.visibility.ApplyVisibility(ctx, record, previous); err != nil {
+		return err
+	}
+	if err := s.recordRepo.UpdateWithTags(ctx, record, tagIDs, s.recordTagRepo); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, &event.RecordChanged{Name: event.RecordUpdated, Record: record, Previous: previous})
+	return nil
 }
 
-func (s *recordService) CountRecordsByDateRange(ctx context.Context, userID uint64, start, end time.Time) (int64, error) {
-	return s.recordRepo.CountRecordsByDateRange(ctx, userID, start, end)
+func (s *recordService) CountRecordsByDateRange(ctx context.Context, viewerID, userID uint64, start, end time.Time) (int64, error) {
+	access, err := s.accessTo(ctx, viewerID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return s.recordRepo.CountRecordsByDateRange(ctx, userID, access, start, end)
 }
 
 // GetGlobalRanking 获取全局排行榜
-func (s *recordService) GetGlobalRanking(ctx context.Context, start, end time.Time, limit int) ([]*entity.RankingItem, error) {
-	return s.recordRepo.GetGlobalRanking(ctx, start, end, limit)
+func (s *recordService) GetGlobalRanking(ctx context.Context, metric string, start, end time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
+	return s.recordRepo.GetGlobalRanking(ctx, metric, start, end, page, pageSize)
+}
+
+// GetUserGlobalRank 获取用户在全局排行榜中的名次及相邻用户
+func (s *recordService) GetUserGlobalRank(ctx context.Context, metric string, userID uint64, start, end time.Time) (*entity.RankingPosition, error) {
+	return s.recordRepo.GetUserGlobalRank(ctx, metric, userID, start, end)
 }
 
 // GetFriendRanking 获取好友排行榜数据
-func (s *recordService) GetFriendRanking(ctx context.Context, userIDs []uint64, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
-	return s.recordRepo.GetFriendRanking(ctx, userIDs, startDate, endDate, page, pageSize)
+func (s *recordService) GetFriendRanking(ctx context.Context, metric string, userIDs []uint64, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
+	return s.recordRepo.GetFriendRanking(ctx, metric, userIDs, entity.RecordVisibilitiesFriends, startDate, endDate, page, pageSize)
+}
+
+// GetUserFriendRank 获取用户在好友排行榜中的名次以及前后相邻的用户
+func (s *recordService) GetUserFriendRank(ctx context.Context, metric string, userID uint64, userIDs []uint64, start, end time.Time) (*entity.RankingPosition, error) {
+	return s.recordRepo.GetUserFriendRank(ctx, metric, userID, userIDs, entity.RecordVisibilitiesFriends, start, end)
 }
 
 // GetUsersDailyRecordStats 批量获取指定用户当天的拉屎记录统计
-func (s *recordService) GetUsersDailyRecordStats(ctx context.Context, userIDs []uint64, date time.Time) (map[uint64]*entity.DailyRecordStats, error) {
-	return s.recordRepo.GetUsersDailyRecordStats(ctx, userIDs, date)
+// 查看者不可见的记录不计入统计，也不返回其记录时间
+func (s *recordService) GetUsersDailyRecordStats(ctx context.Context, viewerID uint64, userIDs []uint64, date time.Time) (map[uint64]*entity.DailyRecordStats, error) {
+	accesses, err := s.visibility.ResolveAccess(ctx, viewerID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return s.recordRepo.GetUsersDailyRecordStats(ctx, userIDs, accesses, date)
 }
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+)
+
+var (
+	// ErrRecordVisibilityInvalid 不支持的可见范围
+	ErrRecordVisibilityInvalid = errors.New("无效的可见范围，可选值为private、friends、circle、public")
+	// ErrRecordVisibilityCircleInvalid 可见范围为分组时必须指定自己创建的分组
+	ErrRecordVisibilityCircleInvalid = errors.New("可见范围为分组时需要指定自己创建的分组")
+)
+
+// RecordVisibilityService 记录可见范围服务接口
+type RecordVisibilityService interface {
+	// GetSetting 获取用户新记录的默认可见范围，没有设置时返回默认的好友可见
+	GetSetting(ctx context.Context, userID uint64) (*entity.RecordVisibilitySetting, error)
+
+	// UpdateSetting 更新用户新记录的默认可见范围
+	UpdateSetting(ctx context.Context, userID uint64, visibility string, circleID uint64) (*entity.RecordVisibilitySetting, error)
+
+	// ApplyVisibility 校验记录的可见范围
+	// 未指定时更新的记录沿用 previous 的可见范围，新记录使用用户的默认设置
+	ApplyVisibility(ctx context.Context, record *entity.Record, previous *entity.Record) error
+
+	// ResolveAccess 计算查看者对多个用户记录的访问权限
+	ResolveAccess(ctx context.Context, viewerID uint64, ownerIDs []uint64) (map[uint64]*entity.RecordAccess, error)
+}
+
+// recordVisibilityService 记录可见范围服务实现
+type recordVisibilityService struct {
+	settingRepo repository.RecordVisibilitySettingRepository
+	circleRepo  repository.CircleRepository
+	friendRepo  repository.FriendRepository
+	blockRepo   repository.BlockRepository
+}
+
+// NewRecordVisibilityService 创建记录可见范围服务
+func NewRecordVisibilityService(
+	settingRepo repository.RecordVisibilitySettingRepository,
+	circleRepo repository.CircleRepository,
+	friendRepo repository.FriendRepository,
+	blockRepo repository.BlockRepository,
+) RecordVisibilityService {
+	return &recordVisibilityService{
+		settingRepo: settingRepo,
+		circleRepo:  circleRepo,
+		friendRepo:  friendRepo,
+		blockRepo:   blockRepo,
+	}
+}
+
+// GetSetting 获取用户新记录的默认可见范围
+func (s *recordVisibilityService) GetSetting(ctx context.Context, userID uint64) (*entity.RecordVisibilitySetting, error) {
+	setting, err := s.settingRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		return entity.DefaultRecordVisibilitySetting(userID), nil
+	}
+	return setting, nil
+}
+
+// UpdateSetting 更新用户新记录的默认可见范围
+func (s *recordVisibilityService) UpdateSetting(ctx context.Context, userID uint64, visibility string, circleID uint64) (*entity.RecordVisibilitySetting, error) {
+	circleID, err := s.validate(ctx, userID, visibility, circleID)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := &entity.RecordVisibilitySetting{UserID: userID, Visibility: visibility, CircleID: circleID}
+	if err := s.settingRepo.Save(ctx, setting); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// ApplyVisibility 校验记录的可见范围
+func (s *recordVisibilityService) ApplyVisibility(ctx context.Context, record *entity.Record, previous *entity.Record) error {
+	if record.Visibility == "" && previous != nil {
+		record.Visibility, record.CircleID = previous.Visibility, previous.CircleID
+	}
+
+	if record.Visibility == "" {
+		setting, err := s.GetSetting(ctx, record.UserID)
+		if err != nil {
+			return err
+		}
+		circleID, err := s.validate(ctx, record.UserID, setting.Visibility, setting.CircleID)
+		if errors.Is(err, ErrRecordVisibilityCircleInvalid) {
+			// 默认分组已被删除，新记录按仅自己可见处理，避免意外公开
+			record.Visibility, record.CircleID = entity.RecordVisibilityPrivate, 0
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		record.Visibility, record.CircleID = setting.Visibility, circleID
+		return nil
+	}
+
+	circleID, err := s.validate(ctx, record.UserID, record.Visibility, record.CircleID)
+	if err != nil {
+		return err
+	}
+	record.CircleID = circleID
+	return nil
+}
+
+// ResolveAccess 计算查看者对多个用户记录的访问权限
+func (s *recordVisibilityService) ResolveAccess(ctx context.Context, viewerID uint64, ownerIDs []uint64) (map[uint64]*entity.RecordAccess, error) {
+	friendIDs, err := s.friendRepo.FindFriendIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	friendSet := make(map[uint64]bool, len(friendIDs))
+	for _, friendID := range friendIDs {
+		friendSet[friendID] = true
+	}
+
+	blockedIDs, err := s.blockRepo.FindBlockedIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	blockedSet := make(map[uint64]bool, len(blockedIDs))
+	for _, blockedID := range blockedIDs {
+		blockedSet[blockedID] = true
+	}
+
+	// 只需要查询好友创建的分组
+	var friendOwnerIDs []uint64
+	for _, ownerID := range ownerIDs {
+		if friendSet[ownerID] {
+			friendOwnerIDs = append(friendOwnerIDs, ownerID)
+		}
+	}
+	circleIDs, err := s.circleRepo.FindIDsContainingMember(ctx, friendOwnerIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses := make(map[uint64]*entity.RecordAccess, len(ownerIDs))
+	for _, ownerID := range ownerIDs {
+		accesses[ownerID] = &entity.RecordAccess{
+			OwnerID:   ownerID,
+			Self:      ownerID == viewerID,
+			Friend:    friendSet[ownerID],
+			Blocked:   blockedSet[ownerID],
+			CircleIDs: circleIDs[ownerID],
+		}
+	}
+	return accesses, nil
+}
+
+// validate 校验可见范围，返回应保存的分组ID（可见范围不是分组时为0）
+func (s *recordVisibilityService) validate(ctx context.Context, userID uint64, visibility string, circleID uint64) (uint64, error) {
+	if !entity.IsValidRecordVisibility(visibility) {
+		return 0, ErrRecordVisibilityInvalid
+	}
+	if visibility != entity.RecordVisibilityCircle {
+		return 0, nil
+	}
+
+	if circleID == 0 {
+		return 0, ErrRecordVisibilityCircleInvalid
+	}
+	circle, err := s.circleRepo.FindByID(ctx, circleID)
+	if err != nil {
+		return 0, err
+	}
+	if circle == nil || circle.UserID != userID {
+		return 0, ErrRecordVisibilityCircleInvalid
+	}
+	return circleID, nil
+}
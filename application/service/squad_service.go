@@ -0,0 +1,536 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrSquadNotFound 小组不存在，或查看者不是小组成员
+	ErrSquadNotFound = errors.New("小组不存在")
+	// ErrSquadNameInvalid 小组名称为空或过长
+	ErrSquadNameInvalid = errors.New("小组名称不能为空且不能超过20个字")
+	// ErrSquadForbidden 当前角色没有权限执行该操作
+	ErrSquadForbidden = errors.New("没有权限执行该操作")
+	// ErrSquadFull 小组人数已达上限
+	ErrSquadFull = errors.New("小组人数已满")
+	// ErrSquadJoinLimit 用户加入的小组数已达上限
+	ErrSquadJoinLimit = errors.New("加入的小组数已达上限")
+	// ErrSquadAlreadyMember 用户已经是小组成员
+	ErrSquadAlreadyMember = errors.New("已经是小组成员")
+	// ErrSquadMemberCapInvalid 成员上限小于当前人数或超过系统上限
+	ErrSquadMemberCapInvalid = errors.New("成员上限不能小于当前人数且不能超过系统上限")
+	// ErrSquadOwnerCannotLeave 创建者不能退出小组，只能解散
+	ErrSquadOwnerCannotLeave = errors.New("创建者不能退出小组，请解散小组")
+	// ErrSquadMemberNotFound 目标用户不是小组成员
+	ErrSquadMemberNotFound = errors.New("该用户不是小组成员")
+	// ErrSquadRoleInvalid 只能设置为管理员或普通成员
+	ErrSquadRoleInvalid = errors.New("无效的成员角色")
+	// ErrSquadMetricInvalid 不支持的小组排行榜指标
+	ErrSquadMetricInvalid = errors.New("无效的小组排行榜指标")
+)
+
+// SquadService 小组服务接口
+type SquadService interface {
+	// GetMySquads 获取用户加入的小组
+	GetMySquads(ctx context.Context, userID uint64) ([]*entity.Squad, error)
+
+	// GetSquad 获取小组信息和成员列表，只有成员可以查看，邀请码仅创建者和管理员可见
+	GetSquad(ctx context.Context, viewerID, squadID uint64) (*entity.Squad, []*entity.SquadMember, error)
+
+	// CreateSquad 创建小组，创建者自动成为成员，memberCap为0时使用系统上限
+	CreateSquad(ctx context.Context, ownerID uint64, name string, memberCap int) (*entity.Squad, error)
+
+	// UpdateSquad 修改小组名称和成员上限，需要创建者或管理员
+	UpdateSquad(ctx context.Context, operatorID, squadID uint64, name string, memberCap int) (*entity.Squad, error)
+
+	// DeleteSquad 解散小组，只有创建者可以操作
+	DeleteSquad(ctx context.Context, operatorID, squadID uint64) error
+
+	// GetInvite 获取小组邀请链接，需要创建者或管理员
+	GetInvite(ctx context.Context, operatorID, squadID uint64) (*entity.SquadInvite, error)
+
+	// RotateInvite 更换邀请码，旧的邀请链接随即失效
+	RotateInvite(ctx context.Context, operatorID, squadID uint64) (*entity.SquadInvite, error)
+
+	// Join 通过邀请码加入小组
+	Join(ctx context.Context, userID uint64, code string) (*entity.Squad, error)
+
+	// Leave 退出小组
+	Leave(ctx context.Context, userID, squadID uint64) error
+
+	// RemoveMember 移除成员，创建者可以移除任何人，管理员只能移除普通成员
+	RemoveMember(ctx context.Context, operatorID, squadID, targetID uint64) error
+
+	// SetMemberRole 设置成员为管理员或普通成员，只有创建者可以操作
+	SetMemberRole(ctx context.Context, operatorID, squadID, targetID uint64, role string) error
+
+	// GetDashboard 获取小组在当前周期内的每日汇总和成员排行
+	GetDashboard(ctx context.Context, viewerID, squadID uint64, period, metric string, now time.Time) (*entity.SquadDashboard, error)
+
+	// GetLeaderboard 分页获取当前周期的小组排行榜
+	GetLeaderboard(ctx context.Context, period, metric string, now time.Time, page, pageSize int) ([]*entity.SquadRankingItem, int, error)
+}
+
+// squadService 小组服务实现
+type squadService struct {
+	squadRepo   repository.SquadRepository
+	recordRepo  repository.RecordRepository
+	userRepo    repository.UserRepository
+	settingRepo repository.RankingSettingRepository
+	maxMembers  int
+	maxJoined   int
+	invitePage  string
+}
+
+// NewSquadService 创建小组服务
+// maxMembers为小组成员上限的最大值，maxJoined为每个用户最多加入的小组数，invitePage为邀请链接打开的小程序页面
+func NewSquadService(
+	squadRepo repository.SquadRepository,
+	recordRepo repository.RecordRepository,
+	userRepo repository.UserRepository,
+	settingRepo repository.RankingSettingRepository,
+	maxMembers, maxJoined int,
+	invitePage string,
+) SquadService {
+	return &squadService{
+		squadRepo:   squadRepo,
+		recordRepo:  recordRepo,
+		userRepo:    userRepo,
+		settingRepo: settingRepo,
+		maxMembers:  maxMembers,
+		maxJoined:   maxJoined,
+		invitePage:  invitePage,
+	}
+}
+
+// GetMySquads 获取用户加入的小组，普通成员看不到邀请码
+func (s *squadService) GetMySquads(ctx context.Context, userID uint64) ([]*entity.Squad, error) {
+	squads, err := s.squadRepo.FindByMemberID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, squad := range squads {
+		if squad.MyRole == entity.SquadRoleMember {
+			squad.InviteCode = ""
+		}
+	}
+	return squads, nil
+}
+
+// GetSquad 获取小组信息和成员列表
+func (s *squadService) GetSquad(ctx context.Context, viewerID, squadID uint64) (*entity.Squad, []*entity.SquadMember, error) {
+	squad, member, err := s.findAsMember(ctx, viewerID, squadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members, err := s.squadRepo.FindMembers(ctx, squadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userIDs := make([]uint64, len(members))
+	for i, m := range members {
+		userIDs[i] = m.UserID
+	}
+	userMap, err := s.userMap(ctx, userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, m := range members {
+		m.User = userMap[m.UserID]
+	}
+
+	if !member.CanManage() {
+		squad.InviteCode = ""
+	}
+	return squad, members, nil
+}
+
+// CreateSquad 创建小组，邀请码重复时重新生成
+func (s *squadService) CreateSquad(ctx context.Context, ownerID uint64, name string, memberCap int) (*entity.Squad, error) {
+	name, err := s.validateName(name)
+	if err != nil {
+		return nil, err
+	}
+	if memberCap == 0 {
+		memberCap = s.maxMembers
+	}
+	if memberCap < 1 || memberCap > s.maxMembers {
+		return nil, ErrSquadMemberCapInvalid
+	}
+	if err := s.checkJoinLimit(ctx, ownerID); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for i := 0; i < inviteCodeAttempts; i++ {
+		code, err := randomInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		squad := &entity.Squad{
+			OwnerID:    ownerID,
+			Name:       name,
+			InviteCode: code,
+			MemberCap:  memberCap,
+		}
+		if lastErr = s.squadRepo.Create(ctx, squad); lastErr == nil {
+			squad.MyRole = entity.SquadRoleOwner
+			return squad, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// UpdateSquad 修改小组名称和成员上限，成员上限不能小于当前人数
+func (s *squadService) UpdateSquad(ctx context.Context, operatorID, squadID uint64, name string, memberCap int) (*entity.Squad, error) {
+	squad, member, err := s.findAsMember(ctx, operatorID, squadID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.CanManage() {
+		return nil, ErrSquadForbidden
+	}
+
+	name, err = s.validateName(name)
+	if err != nil {
+		return nil, err
+	}
+	if memberCap == 0 {
+		memberCap = squad.MemberCap
+	}
+	if int64(memberCap) < squad.MemberCount || memberCap > s.maxMembers {
+		return nil, ErrSquadMemberCapInvalid
+	}
+
+	squad.Name = name
+	squad.MemberCap = memberCap
+	if err := s.squadRepo.Update(ctx, squad); err != nil {
+		return nil, err
+	}
+	squad.MyRole = member.Role
+	return squad, nil
+}
+
+// DeleteSquad 解散小组
+func (s *squadService) DeleteSquad(ctx context.Context, operatorID, squadID uint64) error {
+	_, member, err := s.findAsMember(ctx, operatorID, squadID)
+	if err != nil {
+		return err
+	}
+	if member.Role != entity.SquadRoleOwner {
+		return ErrSquadForbidden
+	}
+	return s.squadRepo.Delete(ctx, squadID)
+}
+
+// GetInvite 获取小组邀请链接
+func (s *squadService) GetInvite(ctx context.Context, operatorID, squadID uint64) (*entity.SquadInvite, error) {
+	squad, member, err := s.findAsMember(ctx, operatorID, squadID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.CanManage() {
+		return nil, ErrSquadForbidden
+	}
+	return s.invite(squad.InviteCode), nil
+}
+
+// RotateInvite 更换邀请码，与其他小组重复时重新生成
+func (s *squadService) RotateInvite(ctx context.Context, operatorID, squadID uint64) (*entity.SquadInvite, error) {
+	_, member, err := s.findAsMember(ctx, operatorID, squadID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.CanManage() {
+		return nil, ErrSquadForbidden
+	}
+
+	var lastErr error
+	for i := 0; i < inviteCodeAttempts; i++ {
+		code, err := randomInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		if lastErr = s.squadRepo.UpdateInviteCode(ctx, squadID, code); lastErr == nil {
+			return s.invite(code), nil
+		}
+	}
+	return nil, lastErr
+}
+
+// invite 根据邀请码生成邀请链接
+func (s *squadService) invite(code string) *entity.SquadInvite {
+	return &entity.SquadInvite{
+		Code:  code,
+		Scene: entity.SquadInviteScenePrefix + code,
+		Path:  s.invitePage + "?code=" + code,
+	}
+}
+
+// Join 通过邀请码加入小组，邀请码同时接受小程序码的scene参数
+func (s *squadService) Join(ctx context.Context, userID uint64, code string) (*entity.Squad, error) {
+	code = strings.TrimPrefix(strings.TrimSpace(code), entity.SquadInviteScenePrefix)
+	if code == "" {
+		return nil, ErrInviteCodeInvalid
+	}
+
+	squad, err := s.squadRepo.FindByInviteCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if squad == nil {
+		return nil, ErrInviteCodeInvalid
+	}
+
+	existing, err := s.squadRepo.FindMember(ctx, squad.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrSquadAlreadyMember
+	}
+	if err := s.checkJoinLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	added, err := s.squadRepo.AddMember(ctx, &entity.SquadMember{
+		SquadID: squad.ID,
+		UserID:  userID,
+		Role:    entity.SquadRoleMember,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrSquadFull
+	}
+
+	squad.MemberCount++
+	squad.InviteCode = ""
+	squad.MyRole = entity.SquadRoleMember
+	return squad, nil
+}
+
+// Leave 退出小组
+func (s *squadService) Leave(ctx context.Context, userID, squadID uint64) error {
+	_, member, err := s.findAsMember(ctx, userID, squadID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entity.SquadRoleOwner {
+		return ErrSquadOwnerCannotLeave
+	}
+	_, err = s.squadRepo.RemoveMember(ctx, squadID, userID)
+	return err
+}
+
+// RemoveMember 移除成员，不能通过移除操作移除自己
+func (s *squadService) RemoveMember(ctx context.Context, operatorID, squadID, targetID uint64) error {
+	_, operator, err := s.findAsMember(ctx, operatorID, squadID)
+	if err != nil {
+		return err
+	}
+	if !operator.CanManage() || operatorID == targetID {
+		return ErrSquadForbidden
+	}
+
+	target, err := s.squadRepo.FindMember(ctx, squadID, targetID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrSquadMemberNotFound
+	}
+	if operator.Role == entity.SquadRoleAdmin && target.Role != entity.SquadRoleMember {
+		return ErrSquadForbidden
+	}
+
+	_, err = s.squadRepo.RemoveMember(ctx, squadID, targetID)
+	return err
+}
+
+// SetMemberRole 设置成员角色，创建者的角色不能修改
+func (s *squadService) SetMemberRole(ctx context.Context, operatorID, squadID, targetID uint64, role string) error {
+	if role != entity.SquadRoleAdmin && role != entity.SquadRoleMember {
+		return ErrSquadRoleInvalid
+	}
+
+	_, operator, err := s.findAsMember(ctx, operatorID, squadID)
+	if err != nil {
+		return err
+	}
+	if operator.Role != entity.SquadRoleOwner || operatorID == targetID {
+		return ErrSquadForbidden
+	}
+
+	target, err := s.squadRepo.FindMember(ctx, squadID, targetID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrSquadMemberNotFound
+	}
+	if target.Role == role {
+		return nil
+	}
+	return s.squadRepo.UpdateMemberRole(ctx, squadID, targetID, role)
+}
+
+// GetDashboard 获取小组在当前周期内的每日汇总和成员排行
+// 每日汇总包含周期内截至今天的每一天，没有记录的日期补0；设置为不参与排行的成员不出现在排行中
+func (s *squadService) GetDashboard(ctx context.Context, viewerID, squadID uint64, period, metric string, now time.Time) (*entity.SquadDashboard, error) {
+	if !entity.IsValidRankingPeriod(period) {
+		period = entity.RankingPeriodWeekly
+	}
+	if !entity.IsValidRankingMetric(metric) {
+		metric = entity.RankingMetricCount
+	}
+
+	squad, member, err := s.findAsMember(ctx, viewerID, squadID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.CanManage() {
+		squad.InviteCode = ""
+	}
+	squad.MyRole = member.Role
+
+	memberIDs, err := s.squadRepo.FindMemberIDs(ctx, squadID)
+	if err != nil {
+		return nil, err
+	}
+
+	window := periodWindow(period)
+	start := windowStart(window, now)
+	end := endOfRange(windowEnd(window, start))
+
+	counts, err := s.recordRepo.GetDailyCounts(ctx, memberIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+	countMap := make(map[string]*entity.DailyRecordCount, len(counts))
+	for _, count := range counts {
+		countMap[count.Date.Format("2006-01-02")] = count
+	}
+	today := startOfDay(now)
+	daily := make([]*entity.DailyRecordCount, 0)
+	for day := start; !day.After(today) && day.Before(end); day = day.AddDate(0, 0, 1) {
+		if count, exists := countMap[day.Format("2006-01-02")]; exists {
+			count.Date = day
+			daily = append(daily, count)
+		} else {
+			daily = append(daily, &entity.DailyRecordCount{Date: day})
+		}
+	}
+
+	rankingIDs, err := filterFriendRankingMembers(ctx, s.settingRepo, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+	rankings, _, err := s.recordRepo.GetFriendRanking(ctx, metric, rankingIDs, entity.RecordVisibilitiesPublic, start, end, 1, len(rankingIDs))
+	if err != nil {
+		return nil, err
+	}
+	userMap, err := s.userMap(ctx, rankingIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range rankings {
+		if user, exists := userMap[item.UserID]; exists {
+			item.Nickname = user.Nickname
+			item.AvatarURL = user.AvatarURL
+		}
+	}
+
+	return &entity.SquadDashboard{
+		Squad:       squad,
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Daily:       daily,
+		Rankings:    rankings,
+	}, nil
+}
+
+// GetLeaderboard 分页获取当前周期的小组排行榜
+func (s *squadService) GetLeaderboard(ctx context.Context, period, metric string, now time.Time, page, pageSize int) ([]*entity.SquadRankingItem, int, error) {
+	if !entity.IsValidRankingPeriod(period) {
+		period = entity.RankingPeriodWeekly
+	}
+	if metric == "" {
+		metric = entity.SquadMetricCount
+	}
+	if !entity.IsValidSquadMetric(metric) {
+		return nil, 0, ErrSquadMetricInvalid
+	}
+
+	window := periodWindow(period)
+	start := windowStart(window, now)
+	end := endOfRange(windowEnd(window, start))
+	return s.recordRepo.GetSquadRanking(ctx, metric, start, end, page, pageSize)
+}
+
+// findAsMember 查找小组以及查看者的成员信息，查看者不是成员时按小组不存在处理，避免泄露小组信息
+func (s *squadService) findAsMember(ctx context.Context, userID, squadID uint64) (*entity.Squad, *entity.SquadMember, error) {
+	member, err := s.squadRepo.FindMember(ctx, squadID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if member == nil {
+		return nil, nil, ErrSquadNotFound
+	}
+
+	squad, err := s.squadRepo.FindByID(ctx, squadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if squad == nil {
+		return nil, nil, ErrSquadNotFound
+	}
+	return squad, member, nil
+}
+
+// checkJoinLimit 检查用户加入的小组数是否已达上限
+func (s *squadService) checkJoinLimit(ctx context.Context, userID uint64) error {
+	count, err := s.squadRepo.CountByMemberID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.maxJoined) {
+		return ErrSquadJoinLimit
+	}
+	return nil
+}
+
+// validateName 校验并规整小组名称
+func (s *squadService) validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > entity.SquadNameMaxLength {
+		return "", ErrSquadNameInvalid
+	}
+	return name, nil
+}
+
+// userMap 批量查询用户，按用户ID索引
+func (s *squadService) userMap(ctx context.Context, userIDs []uint64) (map[uint64]*entity.User, error) {
+	userMap := make(map[uint64]*entity.User, len(userIDs))
+	if len(userIDs) == 0 {
+		return userMap, nil
+	}
+	users, err := s.userRepo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	return userMap, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import "time"
+
+// shanghaiLocation 业务统一使用东八区计算自然日
+var shanghaiLocation = loadShanghaiLocation()
+
+// loadShanghaiLocation 加载东八区时区，系统缺少时区数据时使用固定偏移
+func loadShanghaiLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return time.FixedZone("CST", 8*3600)
+	}
+	return loc
+}
+
+// startOfDay 获取指定时间在东八区当天的0点
+func startOfDay(t time.Time) time.Time {
+	t = t.In(shanghaiLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, shanghaiLocation)
+}
+
+// startOfMonth 获取指定时间在东八区当月的第一天0点
+func startOfMonth(t time.Time) time.Time {
+	t = t.In(shanghaiLocation)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, shanghaiLocation)
+}
+
+// startOfYear 获取指定时间在东八区当年的第一天0点
+func startOfYear(t time.Time) time.Time {
+	t = t.In(shanghaiLocation)
+	return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, shanghaiLocation)
+}
+
+// 统计周期粒度，与目标周期的取值保持一致
+const (
+	windowDay   = "day"
+	windowWeek  = "week"
+	windowMonth = "month"
+)
+
+// windowStart 获取时间所在统计周期（day/week/month）的开始时间，周从周一开始
+func windowStart(window string, t time.Time) time.Time {
+	switch window {
+	case windowWeek:
+		day := startOfDay(t)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case windowMonth:
+		return startOfMonth(t)
+	default:
+		return startOfDay(t)
+	}
+}
+
+// windowEnd 获取统计周期的结束时间（不含）
+func windowEnd(window string, start time.Time) time.Time {
+	switch window {
+	case windowWeek:
+		return start.AddDate(0, 0, 7)
+	case windowMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// endOfRange 将半开区间的结束时间转换为闭区间的最后一纳秒，便于配合BETWEEN查询
+func endOfRange(exclusiveEnd time.Time) time.Time {
+	return exclusiveEnd.Add(-time.Nanosecond)
+}
+
+// recordDays 将记录时间转换为东八区去重后的自然日列表（升序）
+func recordDays(times []time.Time) []time.Time {
+	days := make([]time.Time, 0, len(times))
+	seen := make(map[string]bool)
+	for _, t := range times {
+		day := startOfDay(t)
+		key := day.Format("2006-01-02")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		days = append(days, day)
+	}
+	return days
+}
+
+// isNextDay 判断next是否为prev的后一天
+func isNextDay(prev, next time.Time) bool {
+	return prev.AddDate(0, 0, 1).Equal(next)
+}
+
+// longestStreak 计算升序自然日列表中的最长连续天数
+func longestStreak(days []time.Time) int {
+	longest, current := 0, 0
+	for i, day := range days {
+		if i > 0 && isNextDay(days[i-1], day) {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// currentStreak 计算截止到today（含）仍在持续的连续天数，today当天没有记录时从昨天开始算
+func currentStreak(days []time.Time, today time.Time) int {
+	if len(days) == 0 {
+		return 0
+	}
+	today = startOfDay(today)
+	last := days[len(days)-1]
+	if !last.Equal(today) && !isNextDay(last, today) {
+		return 0
+	}
+
+	streak := 1
+	for i := len(days) - 1; i > 0; i-- {
+		if !isNextDay(days[i-1], days[i]) {
+			break
+		}
+		streak++
+	}
+	return streak
+}
@@ -0,0 +1,57 @@
+// backfill-badges 按现有记录和好友关系为用户补发徽章
+//
+// 用法: go run ./cmd/backfill-badges [-user 用户ID]
+// 未指定用户时处理全部用户；补发的徽章不发送通知也不生成动态，可重复执行
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"record-project/application/service"
+	"record-project/infrastructure/config"
+	"record-project/infrastructure/eventbus"
+	"record-project/infrastructure/notify"
+	"record-project/infrastructure/persistence"
+	"record-project/infrastructure/persistence/repository"
+	"time"
+)
+
+func main() {
+	userID := flag.Uint64("user", 0, "只补发指定用户的徽章，0表示全部用户")
+	flag.Parse()
+
+	// 加载配置
+	cfg := config.LoadConfig()
+
+	// 初始化数据库，同时迁移徽章数据表
+	db, err := persistence.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("初始化数据库失败: %v", err)
+	}
+	if err := db.InitData(); err != nil {
+		log.Fatalf("初始化数据失败: %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(db.DB)
+	notificationService := service.NewNotificationService(repository.NewNotificationRepository(db.DB), userRepo, notify.NewLogNotifier())
+	badgeService := service.NewBadgeService(
+		repository.NewUserBadgeRepository(db.DB),
+		repository.NewRecordRepository(db.DB),
+		repository.NewFriendRepository(db.DB),
+		userRepo,
+		notificationService,
+		eventbus.NewBus(),
+	)
+
+	var userIDs []uint64
+	if *userID != 0 {
+		userIDs = []uint64{*userID}
+	}
+
+	awarded, err := badgeService.Backfill(context.Background(), userIDs, time.Now())
+	if err != nil {
+		log.Fatalf("补发徽章失败（已补发%d个）: %v", awarded, err)
+	}
+	log.Printf("补发徽章完成，共补发%d个", awarded)
+}
@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// 动态类型
+const (
+	ActivityTypeRecord          = "record"           // 新增记录
+	ActivityTypeStreakMilestone = "streak_milestone" // 连续记录天数达到里程碑
+	ActivityTypeGoalMet         = "goal_met"         // 达成个人目标
+	ActivityTypeRankChange      = "rank_change"      // 排行榜名次上升
+	ActivityTypeBadge           = "badge"            // 获得徽章
+)
+
+// StreakMilestones 产生动态的连续记录天数
+var StreakMilestones = []int{3, 7, 14, 30, 60, 100, 365}
+
+// IsStreakMilestone 判断连续天数是否为里程碑
+func IsStreakMilestone(days int) bool {
+	for _, milestone := range StreakMilestones {
+		if milestone == days {
+			return true
+		}
+	}
+	return false
+}
+
+// Activity 好友动态
+// 与记录相关的动态沿用记录的可见范围，记录修改或删除时同步更新
+type Activity struct {
+	ID         uint64        `json:"id"`
+	ActorID    uint64        `json:"actor_id"`
+	Type       string        `json:"type"`
+	RefKey     string        `json:"-"` // 去重键，同一用户同一来源只产生一条动态
+	RecordID   uint64        `json:"record_id,omitempty"`
+	Visibility string        `json:"-"`
+	CircleID   uint64        `json:"-"`
+	Data       *ActivityData `json:"data,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+
+	// 关联对象，不存储在数据库中
+	Actor        *User               `json:"actor,omitempty"`
+	Record       *Record             `json:"record,omitempty"`
+	Interactions *RecordInteractions `json:"interactions,omitempty"` // 记录动态的回应和评论汇总
+}
+
+// ActivityData 动态的附加信息，按动态类型填写对应字段
+type ActivityData struct {
+	StreakDays   int     `json:"streak_days,omitempty"`
+	GoalID       uint64  `json:"goal_id,omitempty"`
+	GoalName     string  `json:"goal_name,omitempty"`
+	GoalWindow   string  `json:"goal_window,omitempty"`
+	Period       string  `json:"period,omitempty"`
+	Metric       string  `json:"metric,omitempty"`
+	Rank         uint64  `json:"rank,omitempty"`
+	PreviousRank uint64  `json:"previous_rank,omitempty"`
+	MetricValue  float64 `json:"metric_value,omitempty"`
+	BadgeCode    string  `json:"badge_code,omitempty"`
+	BadgeName    string  `json:"badge_name,omitempty"`
+}
+
+// FeedCursor 动态流的分页游标，指向上一页最后一条动态
+type FeedCursor struct {
+	OccurredAt time.Time
+	ID         uint64
+}
+
+// FeedPage 一页动态，NextCursor 为空表示没有更多
+type FeedPage struct {
+	Items      []*Activity `json:"items"`
+	NextCursor string      `json:"next_cursor"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import "time"
+
+// 徽章规则类型
+const (
+	BadgeRuleRecordCount = "record_count" // 累计记录次数达到Threshold
+	BadgeRuleStreak      = "streak"       // 最长连续记录天数达到Threshold
+	BadgeRulePerfectWeek = "perfect_week" // 某个已结束的自然周（周一至周日）每天都有记录，且全部为PoopTypeID类型
+	BadgeRuleHourRange   = "hour_range"   // 在[HourFrom, HourTo)点之间的记录次数达到Threshold
+	BadgeRuleFriendSync  = "friend_sync"  // 与好友在同一分钟内记录（双方的记录都不是仅自己可见）
+	BadgeRuleFriendCount = "friend_count" // 好友人数达到Threshold
+)
+
+// BadgeRule 徽章的获得条件，按Kind使用对应的字段
+type BadgeRule struct {
+	Kind       string `json:"kind"`
+	Threshold  int    `json:"threshold,omitempty"`
+	PoopTypeID uint64 `json:"poop_type_id,omitempty"`
+	HourFrom   int    `json:"hour_from,omitempty"`
+	HourTo     int    `json:"hour_to,omitempty"`
+}
+
+// RecordBased 规则是否依赖用户自己的记录，记录变更时需要重新判断
+func (r BadgeRule) RecordBased() bool {
+	switch r.Kind {
+	case BadgeRuleRecordCount, BadgeRuleStreak, BadgeRulePerfectWeek, BadgeRuleHourRange:
+		return true
+	}
+	return false
+}
+
+// FriendBased 规则是否依赖好友关系，成为好友时需要重新判断
+func (r BadgeRule) FriendBased() bool {
+	return r.Kind == BadgeRuleFriendSync || r.Kind == BadgeRuleFriendCount
+}
+
+// Badge 徽章定义
+type Badge struct {
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Rule        BadgeRule `json:"rule"`
+}
+
+// BadgeCatalog 徽章目录，新增徽章只需在此添加定义，已有徽章的Code不能修改
+var BadgeCatalog = []*Badge{
+	{Code: "first_record", Name: "初次记录", Description: "完成第一次记录", Rule: BadgeRule{Kind: BadgeRuleRecordCount, Threshold: 1}},
+	{Code: "records_100", Name: "百次达人", Description: "累计记录100次", Rule: BadgeRule{Kind: BadgeRuleRecordCount, Threshold: 100}},
+	{Code: "streak_7", Name: "一周不断", Description: "连续7天都有记录", Rule: BadgeRule{Kind: BadgeRuleStreak, Threshold: 7}},
+	{Code: "streak_30", Name: "月度坚持", Description: "连续30天都有记录", Rule: BadgeRule{Kind: BadgeRuleStreak, Threshold: 30}},
+	{Code: "perfect_type4_week", Name: "完美一周", Description: "一个自然周每天都有记录，且全部是4型", Rule: BadgeRule{Kind: BadgeRulePerfectWeek, PoopTypeID: 4}},
+	{Code: "night_owl", Name: "夜猫子", Description: "在0点到4点之间记录3次", Rule: BadgeRule{Kind: BadgeRuleHourRange, HourFrom: 0, HourTo: 4, Threshold: 3}},
+	{Code: "friend_sync", Name: "心有灵犀", Description: "与好友在同一分钟内记录", Rule: BadgeRule{Kind: BadgeRuleFriendSync}},
+	{Code: "friends_5", Name: "呼朋唤友", Description: "拥有5位好友", Rule: BadgeRule{Kind: BadgeRuleFriendCount, Threshold: 5}},
+}
+
+// FindBadge 根据Code查找徽章定义，不存在时返回nil
+func FindBadge(code string) *Badge {
+	for _, badge := range BadgeCatalog {
+		if badge.Code == code {
+			return badge
+		}
+	}
+	return nil
+}
+
+// UserBadge 用户获得的徽章，每个徽章只能获得一次
+type UserBadge struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	BadgeCode string    `json:"badge_code"`
+	AwardedAt time.Time `json:"awarded_at"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// 徽章定义，不存储在数据库中
+	Badge *Badge `json:"badge,omitempty"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// HealthyPoopTypeIDs 布里斯托尔分类中被视为健康的类型（第三型、第四型）
+var HealthyPoopTypeIDs = []uint64{3, 4}
+
+// 对比指标
+const (
+	BenchmarkMetricFrequency    = "frequency"     // 日均记录次数
+	BenchmarkMetricAvgDuration  = "avg_duration"  // 平均时长(秒)
+	BenchmarkMetricHealthyShare = "healthy_share" // 健康类型占比
+)
+
+// UserRecordStats 用户在一段时间内的记录汇总
+type UserRecordStats struct {
+	UserID        uint64 `json:"user_id"`
+	RecordCount   int64  `json:"record_count"`
+	TotalDuration int64  `json:"total_duration"`
+	HealthyCount  int64  `json:"healthy_count"` // 健康类型的记录数
+}
+
+// BenchmarkReport 用户在好友和全体用户中的匿名百分位对比
+type BenchmarkReport struct {
+	UserID  uint64             `json:"user_id"`
+	Start   time.Time          `json:"start"`
+	End     time.Time          `json:"end"`
+	Metrics []*BenchmarkMetric `json:"metrics"`
+}
+
+// BenchmarkMetric 单个指标的对比结果
+type BenchmarkMetric struct {
+	Metric  string           `json:"metric"`
+	Value   *float64         `json:"value"` // 用户自身的值，无法计算时为空（例如没有记录时的平均时长）
+	Friends *BenchmarkCohort `json:"friends"`
+	Global  *BenchmarkCohort `json:"global"`
+}
+
+// BenchmarkCohort 某个群体（不含自己）的聚合对比结果，群体人数不足时不返回任何聚合数据
+type BenchmarkCohort struct {
+	Available  bool     `json:"available"`
+	CohortSize int      `json:"cohort_size,omitempty"`
+	Percentile *float64 `json:"percentile,omitempty"` // 用户所处百分位(0-100)，按5取整
+	Median     *float64 `json:"median,omitempty"`     // 群体中位数，按指标的粒度取整
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// Block 屏蔽关系实体，UserID屏蔽了BlockedID
+type Block struct {
+	ID          uint64    `json:"id"`
+	UserID      uint64    `json:"user_id"`
+	BlockedID   uint64    `json:"blocked_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	BlockedUser *User     `json:"blocked_user,omitempty"` // 被屏蔽用户的信息
+}
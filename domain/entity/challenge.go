@@ -0,0 +1,96 @@
+package entity
+
+import "time"
+
+// 挑战目标
+const (
+	ChallengeGoalMostRecords   = "most_records"   // 记录次数最多
+	ChallengeGoalLongestStreak = "longest_streak" // 最长连续打卡天数
+	ChallengeGoalMostHealthy   = "most_healthy"   // 3-4型记录次数最多
+)
+
+// challengeGoalMetrics 挑战目标对应的排行榜指标
+var challengeGoalMetrics = map[string]string{
+	ChallengeGoalMostRecords:   RankingMetricCount,
+	ChallengeGoalLongestStreak: RankingMetricStreak,
+	ChallengeGoalMostHealthy:   RankingMetricHealthyCount,
+}
+
+// IsValidChallengeGoal 判断是否为支持的挑战目标
+func IsValidChallengeGoal(goal string) bool {
+	_, ok := challengeGoalMetrics[goal]
+	return ok
+}
+
+// ChallengeGoalMetric 获取挑战目标对应的排行榜指标
+func ChallengeGoalMetric(goal string) string {
+	return challengeGoalMetrics[goal]
+}
+
+// 挑战状态
+const (
+	ChallengeStatusActive  = "active"  // 未开始或进行中
+	ChallengeStatusSettled = "settled" // 已结束并结算
+)
+
+// 参与者状态
+const (
+	ChallengeParticipantInvited  = "invited"  // 已邀请，尚未回应
+	ChallengeParticipantJoined   = "joined"   // 已参加
+	ChallengeParticipantDeclined = "declined" // 已拒绝
+)
+
+// 挑战限制
+const (
+	ChallengeTitleMaxLength   = 20                  // 标题最大字符数
+	ChallengeMaxParticipants  = 20                  // 参与人数上限（含创建者）
+	ChallengeMaxDuration      = 31 * 24 * time.Hour // 最长持续时间
+	ChallengeMinDuration      = time.Hour           // 最短持续时间
+	ChallengeMaxStartInFuture = 30 * 24 * time.Hour // 开始时间最多可以设置在多久之后
+)
+
+// Challenge 好友之间的限时挑战
+type Challenge struct {
+	ID        uint64     `json:"id"`
+	CreatorID uint64     `json:"creator_id"`
+	Title     string     `json:"title"`
+	Goal      string     `json:"goal"`
+	StartAt   time.Time  `json:"start_at"`
+	EndAt     time.Time  `json:"end_at"` // 结束时间（不含）
+	Status    string     `json:"status"`
+	SettledAt *time.Time `json:"settled_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// 查询者的参与信息，不存储在数据库中
+	MyStatus    string `json:"my_status,omitempty"`
+	MyFinalRank uint64 `json:"my_final_rank,omitempty"`
+}
+
+// Ended 判断挑战在指定时间是否已经结束
+func (c *Challenge) Ended(now time.Time) bool {
+	return !now.Before(c.EndAt)
+}
+
+// ChallengeParticipant 挑战参与者，结算后保存最终名次和成绩
+type ChallengeParticipant struct {
+	ID          uint64     `json:"id"`
+	ChallengeID uint64     `json:"challenge_id"`
+	UserID      uint64     `json:"user_id"`
+	Status      string     `json:"status"`
+	FinalRank   uint64     `json:"final_rank,omitempty"`
+	Score       float64    `json:"score"`
+	Winner      bool       `json:"winner"`
+	RespondedAt *time.Time `json:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// 关联对象，不存储在数据库中
+	User *User `json:"user,omitempty"`
+}
+
+// ChallengeDetail 挑战详情，进行中时为实时排名，结算后为最终排名
+type ChallengeDetail struct {
+	Challenge    *Challenge              `json:"challenge"`
+	Participants []*ChallengeParticipant `json:"participants"`
+	Standings    []*RankingItem          `json:"standings"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// 好友分组规则
+const (
+	CircleNameMaxLength = 20 // 分组名称最大字符数
+	MaxCirclesPerUser   = 20 // 每个用户最多创建的分组数
+)
+
+// Circle 好友分组，如"家人"、"同事"，仅创建者可见
+type Circle struct {
+	ID          uint64    `json:"id"`
+	UserID      uint64    `json:"user_id"`
+	Name        string    `json:"name"`
+	SortOrder   int       `json:"sort_order"`   // 越小越靠前
+	MemberCount int64     `json:"member_count"` // 分组中仍是好友的成员数
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CircleMember 好友分组成员
+type CircleMember struct {
+	ID        uint64    `json:"id"`
+	CircleID  uint64    `json:"circle_id"`
+	MemberID  uint64    `json:"member_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,14 +1,168 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// FriendStatus 好友关系状态
+type FriendStatus int8
+
+// 好友关系状态
+const (
+	FriendStatusPending  FriendStatus = 0 // 待确认
+	FriendStatusAccepted FriendStatus = 1 // 已确认
+	FriendStatusRejected FriendStatus = 2 // 已拒绝
+	FriendStatusRemoved  FriendStatus = 3 // 已删除（含撤回的申请）
+)
+
+// FriendRerequestCooldown 申请被拒绝后，发起方需要等待多久才能再次申请
+const FriendRerequestCooldown = 7 * 24 * time.Hour
+
+var (
+	// ErrFriendNotParticipant 操作者不是该好友关系的任何一方
+	ErrFriendNotParticipant = errors.New("无权更新此好友关系")
+	// ErrFriendTransitionNotAllowed 当前状态下不允许变更为目标状态，或操作者无权执行该变更
+	ErrFriendTransitionNotAllowed = errors.New("不允许的好友状态变更")
+	// ErrFriendRerequestTooSoon 申请被拒绝后冷却期内再次申请
+	ErrFriendRerequestTooSoon = errors.New("对方已拒绝你的好友申请，请稍后再试")
+)
+
+// Valid 是否为已定义的状态
+func (s FriendStatus) Valid() bool {
+	return s >= FriendStatusPending && s <= FriendStatusRemoved
+}
 
 // Friend 好友关系实体
+// UserID 为申请发起方，FriendID 为接收方
 type Friend struct {
-	ID         uint64    `json:"id"`
-	UserID     uint64    `json:"user_id"`
-	FriendID   uint64    `json:"friend_id"`
-	Status     int8      `json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	FriendUser *User     `json:"friend_user,omitempty"` // 好友的用户信息
-}
\ No newline at end of file
+	ID          uint64       `json:"id"`
+	UserID      uint64       `json:"user_id"`
+	FriendID    uint64       `json:"friend_id"`
+	Status      FriendStatus `json:"status"`
+	RequestedAt *time.Time   `json:"requested_at,omitempty"` // 最近一次发起申请的时间
+	AcceptedAt  *time.Time   `json:"accepted_at,omitempty"`
+	RejectedAt  *time.Time   `json:"rejected_at,omitempty"`
+	RemovedAt   *time.Time   `json:"removed_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	FriendUser  *User        `json:"friend_user,omitempty"` // 好友的用户信息
+}
+
+// NewFriendRequest 创建一条待确认的好友申请
+func NewFriendRequest(userID, friendID uint64, now time.Time) *Friend {
+	return &Friend{
+		UserID:      userID,
+		FriendID:    friendID,
+		Status:      FriendStatusPending,
+		RequestedAt: &now,
+	}
+}
+
+// Involves 用户是否为该好友关系的一方
+func (f *Friend) Involves(userID uint64) bool {
+	return f.UserID == userID || f.FriendID == userID
+}
+
+// OtherParty 返回关系中另一方的用户ID
+func (f *Friend) OtherParty(userID uint64) uint64 {
+	if f.UserID == userID {
+		return f.FriendID
+	}
+	return f.UserID
+}
+
+// CanTransition 判断操作者能否把关系变更为目标状态
+//   - 待确认 -> 已确认/已拒绝：仅接收方
+//   - 待确认 -> 已删除：仅发起方（撤回申请）
+//   - 已确认 -> 已删除：任意一方
+//   - 已拒绝 -> 待确认：拒绝方随时可以重新申请，被拒绝的发起方需等待FriendRerequestCooldown
+//   - 已删除 -> 待确认：任意一方（重新申请）
+func (f *Friend) CanTransition(actorID uint64, to FriendStatus, now time.Time) error {
+	if !f.Involves(actorID) {
+		return ErrFriendNotParticipant
+	}
+
+	allowed := false
+	switch f.Status {
+	case FriendStatusPending:
+		switch to {
+		case FriendStatusAccepted, FriendStatusRejected:
+			allowed = actorID == f.FriendID
+		case FriendStatusRemoved:
+			allowed = actorID == f.UserID
+		}
+	case FriendStatusAccepted:
+		allowed = to == FriendStatusRemoved
+	case FriendStatusRejected:
+		allowed = to == FriendStatusPending
+		if allowed && actorID == f.UserID {
+			rejectedAt := f.UpdatedAt
+			if f.RejectedAt != nil {
+				rejectedAt = *f.RejectedAt
+			}
+			if now.Before(rejectedAt.Add(FriendRerequestCooldown)) {
+				return ErrFriendRerequestTooSoon
+			}
+		}
+	case FriendStatusRemoved:
+		allowed = to == FriendStatusPending
+	}
+	if !allowed {
+		return ErrFriendTransitionNotAllowed
+	}
+	return nil
+}
+
+// Transition 按状态机变更关系状态并记录变更时间
+// 重新申请时由操作者成为新的发起方
+func (f *Friend) Transition(actorID uint64, to FriendStatus, now time.Time) error {
+	if err := f.CanTransition(actorID, to, now); err != nil {
+		return err
+	}
+
+	switch to {
+	case FriendStatusPending:
+		f.UserID, f.FriendID = actorID, f.OtherParty(actorID)
+		f.RequestedAt = &now
+		f.AcceptedAt, f.RejectedAt, f.RemovedAt = nil, nil, nil
+	case FriendStatusAccepted:
+		f.AcceptedAt = &now
+	case FriendStatusRejected:
+		f.RejectedAt = &now
+	case FriendStatusRemoved:
+		f.RemovedAt = &now
+	}
+	f.Status = to
+	return nil
+}
+
+// AcceptByInvite 通过邀请码直接成为好友
+// 分享邀请码和扫码分别代表了双方的同意，因此不受接收方限制，已确认的关系不做变更
+func (f *Friend) AcceptByInvite(now time.Time) {
+	if f.Status == FriendStatusAccepted {
+		return
+	}
+	if f.RequestedAt == nil {
+		f.RequestedAt = &now
+	}
+	f.Status = FriendStatusAccepted
+	f.AcceptedAt = &now
+	f.RejectedAt, f.RemovedAt = nil, nil
+}
+
+// FriendSuggestion 好友推荐
+type FriendSuggestion struct {
+	UserID        uint64  `json:"user_id"`
+	User          *User   `json:"user"`
+	MutualCount   int64   `json:"mutual_count"`   // 共同好友数
+	SharedGroups  int64   `json:"shared_groups"`  // 同在一个分组（如联赛分组）的数量
+	Score         float64 `json:"score"`          // 综合得分，越高越靠前
+	MutualFriends []*User `json:"mutual_friends"` // 部分共同好友，用于展示
+}
+
+// MutualFriendCount 两个用户之间的共同好友数
+type MutualFriendCount struct {
+	UserID      uint64
+	MutualCount int64
+}
@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFriendCanTransition(t *testing.T) {
+	const (
+		requester = uint64(1)
+		receiver  = uint64(2)
+		outsider  = uint64(3)
+	)
+	now := time.Date(2024, 5, 20, 10, 0, 0, 0, time.UTC)
+	rejectedAt := func(ago time.Duration) *time.Time {
+		at := now.Add(-ago)
+		return &at
+	}
+
+	tests := []struct {
+		name       string
+		status     FriendStatus
+		rejectedAt *time.Time
+		actor      uint64
+		to         FriendStatus
+		want       error
+	}{
+		{name: "接收方同意申请", status: FriendStatusPending, actor: receiver, to: FriendStatusAccepted},
+		{name: "接收方拒绝申请", status: FriendStatusPending, actor: receiver, to: FriendStatusRejected},
+		{name: "发起方不能同意自己的申请", status: FriendStatusPending, actor: requester, to: FriendStatusAccepted, want: ErrFriendTransitionNotAllowed},
+		{name: "发起方不能拒绝自己的申请", status: FriendStatusPending, actor: requester, to: FriendStatusRejected, want: ErrFriendTransitionNotAllowed},
+		{name: "发起方撤回申请", status: FriendStatusPending, actor: requester, to: FriendStatusRemoved},
+		{name: "接收方不能撤回申请", status: FriendStatusPending, actor: receiver, to: FriendStatusRemoved, want: ErrFriendTransitionNotAllowed},
+		{name: "发起方删除好友", status: FriendStatusAccepted, actor: requester, to: FriendStatusRemoved},
+		{name: "接收方删除好友", status: FriendStatusAccepted, actor: receiver, to: FriendStatusRemoved},
+		{name: "已确认的关系不能改为拒绝", status: FriendStatusAccepted, actor: receiver, to: FriendStatusRejected, want: ErrFriendTransitionNotAllowed},
+		{name: "已确认的关系不能重新申请", status: FriendStatusAccepted, actor: requester, to: FriendStatusPending, want: ErrFriendTransitionNotAllowed},
+		{name: "拒绝方随时可以重新申请", status: FriendStatusRejected, rejectedAt: rejectedAt(time.Minute), actor: receiver, to: FriendStatusPending},
+		{name: "被拒绝的发起方冷却期内不能重新申请", status: FriendStatusRejected, rejectedAt: rejectedAt(time.Hour), actor: requester, to: FriendStatusPending, want: ErrFriendRerequestTooSoon},
+		{name: "被拒绝的发起方冷却期结束后可以重新申请", status: FriendStatusRejected, rejectedAt: rejectedAt(FriendRerequestCooldown), actor: requester, to: FriendStatusPending},
+		{name: "已拒绝的关系不能直接同意", status: FriendStatusRejected, rejectedAt: rejectedAt(time.Minute), actor: receiver, to: FriendStatusAccepted, want: ErrFriendTransitionNotAllowed},
+		{name: "已删除的关系发起方可以重新申请", status: FriendStatusRemoved, actor: requester, to: FriendStatusPending},
+		{name: "已删除的关系接收方可以重新申请", status: FriendStatusRemoved, actor: receiver, to: FriendStatusPending},
+		{name: "已删除的关系不能直接同意", status: FriendStatusRemoved, actor: receiver, to: FriendStatusAccepted, want: ErrFriendTransitionNotAllowed},
+		{name: "关系之外的用户", status: FriendStatusPending, actor: outsider, to: FriendStatusAccepted, want: ErrFriendNotParticipant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Friend{UserID: requester, FriendID: receiver, Status: tt.status, RejectedAt: tt.rejectedAt}
+			if err := f.CanTransition(tt.actor, tt.to, now); !errors.Is(err, tt.want) {
+				t.Fatalf("CanTransition() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFriendTransition(t *testing.T) {
+	requestedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	now := requestedAt.Add(FriendRerequestCooldown + time.Hour)
+
+	t.Run("同意时记录同意时间", func(t *testing.T) {
+		f := NewFriendRequest(1, 2, requestedAt)
+		if err := f.Transition(2, FriendStatusAccepted, now); err != nil {
+			t.Fatal(err)
+		}
+		if f.Status != FriendStatusAccepted || f.AcceptedAt == nil || !f.AcceptedAt.Equal(now) {
+			t.Fatalf("unexpected relation %+v", f)
+		}
+	})
+
+	t.Run("拒绝方重新申请时成为发起方并清空之前的时间", func(t *testing.T) {
+		f := NewFriendRequest(1, 2, requestedAt)
+		if err := f.Transition(2, FriendStatusRejected, requestedAt.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Transition(2, FriendStatusPending, now); err != nil {
+			t.Fatal(err)
+		}
+		if f.UserID != 2 || f.FriendID != 1 || f.Status != FriendStatusPending {
+			t.Fatalf("unexpected direction %d -> %d status %d", f.UserID, f.FriendID, f.Status)
+		}
+		if f.RequestedAt == nil || !f.RequestedAt.Equal(now) || f.RejectedAt != nil || f.AcceptedAt != nil || f.RemovedAt != nil {
+			t.Fatalf("unexpected times %+v", f)
+		}
+	})
+
+	t.Run("不允许的变更不修改关系", func(t *testing.T) {
+		f := NewFriendRequest(1, 2, requestedAt)
+		before := *f
+		if err := f.Transition(1, FriendStatusAccepted, now); !errors.Is(err, ErrFriendTransitionNotAllowed) {
+			t.Fatalf("Transition() = %v, want %v", err, ErrFriendTransitionNotAllowed)
+		}
+		if *f != before {
+			t.Fatalf("relation changed: %+v", f)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// 目标指标
+const (
+	GoalMetricRecordCountMin  = "record_count_min"  // 周期内记录次数不少于目标值
+	GoalMetricAvgDurationMax  = "avg_duration_max"  // 周期内平均时长不超过目标值(秒)
+	GoalMetricHealthyShareMin = "healthy_share_min" // 周期内健康类型占比不低于目标值(百分比)
+)
+
+// 目标周期
+const (
+	GoalWindowDay   = "day"
+	GoalWindowWeek  = "week"
+	GoalWindowMonth = "month"
+)
+
+// 目标状态
+const (
+	GoalStatusArchived int8 = 0 // 已归档
+	GoalStatusActive   int8 = 1 // 进行中
+)
+
+// Goal 个人目标实体
+type Goal struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	Name      string    `json:"name"`
+	Metric    string    `json:"metric"`
+	Target    float64   `json:"target"`
+	Window    string    `json:"window"`
+	Status    int8      `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GoalProgress 目标在当前周期的进度
+type GoalProgress struct {
+	Goal        *Goal     `json:"goal"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Value       *float64  `json:"value"` // 当前值，周期内没有记录且无法计算时为空
+	RecordCount int64     `json:"record_count"`
+	Met         bool      `json:"met"`
+}
+
+// GoalCompletion 目标在某个周期的完成情况
+type GoalCompletion struct {
+	ID          uint64     `json:"id"`
+	GoalID      uint64     `json:"goal_id"`
+	UserID      uint64     `json:"user_id"`
+	PeriodStart time.Time  `json:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end"`
+	Value       *float64   `json:"value"`
+	Met         bool       `json:"met"`
+	MetAt       *time.Time `json:"met_at"`  // 达成时间
+	Settled     bool       `json:"settled"` // 周期是否已结束并结算
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
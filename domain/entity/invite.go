@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// InviteScenePrefix 邀请小程序码scene参数的前缀，scene格式为"i=邀请码"
+const InviteScenePrefix = "i="
+
+// InviteCode 好友邀请码，每个用户同一时间只有一个有效的邀请码
+type InviteCode struct {
+	ID        uint64     `json:"id"`
+	UserID    uint64     `json:"user_id"`
+	Code      string     `json:"code"`
+	RevokedAt *time.Time `json:"revoked_at"` // 更换邀请码后旧邀请码失效的时间
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Scene 小程序码的scene参数
+func (c *InviteCode) Scene() string {
+	return InviteScenePrefix + c.Code
+}
+
+// InviteRedemption 通过邀请码成为好友的记录，用于给邀请人计数
+type InviteRedemption struct {
+	ID        uint64    `json:"id"`
+	InviterID uint64    `json:"inviter_id"`
+	InviteeID uint64    `json:"invitee_id"`
+	Code      string    `json:"code"`
+	NewUser   bool      `json:"new_user"` // 被邀请人是否为通过邀请注册的新用户
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InviteSummary 用户的邀请信息
+type InviteSummary struct {
+	Code         string `json:"code"`
+	Scene        string `json:"scene"`
+	InvitedCount int64  `json:"invited_count"`  // 通过邀请成为好友的人数
+	NewUserCount int64  `json:"new_user_count"` // 其中通过邀请注册的新用户人数
+}
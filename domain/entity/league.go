@@ -0,0 +1,123 @@
+package entity
+
+import "time"
+
+// 联赛段位，数值越大段位越高
+const (
+	LeagueTierBronze   = 1 // 青铜
+	LeagueTierSilver   = 2 // 白银
+	LeagueTierGold     = 3 // 黄金
+	LeagueTierPlatinum = 4 // 铂金
+	LeagueTierDiamond  = 5 // 钻石
+
+	LeagueMinTier = LeagueTierBronze
+	LeagueMaxTier = LeagueTierDiamond
+)
+
+// leagueTierNames 段位名称
+var leagueTierNames = map[int]string{
+	LeagueTierBronze:   "青铜",
+	LeagueTierSilver:   "白银",
+	LeagueTierGold:     "黄金",
+	LeagueTierPlatinum: "铂金",
+	LeagueTierDiamond:  "钻石",
+}
+
+// LeagueTierName 获取段位名称
+func LeagueTierName(tier int) string {
+	return leagueTierNames[tier]
+}
+
+// 赛季状态
+const (
+	LeagueStatusActive = "active" // 进行中
+	LeagueStatusClosed = "closed" // 已结算
+)
+
+// 赛季结束后的升降级结果
+const (
+	LeagueOutcomePromoted  = "promoted"  // 升级
+	LeagueOutcomeRelegated = "relegated" // 降级
+	LeagueOutcomeStayed    = "stayed"    // 保级
+)
+
+// 排名所在区域
+const (
+	LeagueZonePromotion  = "promotion"  // 升级区
+	LeagueZoneRelegation = "relegation" // 降级区
+)
+
+// League 联赛赛季，每个日历周或月为一个赛季
+type League struct {
+	ID        uint64     `json:"id"`
+	Period    string     `json:"period"` // 赛季周期: weekly, monthly
+	Metric    string     `json:"metric"` // 排名指标
+	StartAt   time.Time  `json:"start_at"`
+	EndAt     time.Time  `json:"end_at"` // 赛季结束时间（不含）
+	Status    string     `json:"status"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// LeagueDivision 赛季中的分组，同一分组内的用户为相同段位
+type LeagueDivision struct {
+	ID        uint64    `json:"id"`
+	LeagueID  uint64    `json:"league_id"`
+	Tier      int       `json:"tier"`
+	TierName  string    `json:"tier_name"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Members []*LeagueMembership `json:"-"` // 创建赛季时一起保存的成员
+}
+
+// LeagueMembership 用户在某个赛季中的参赛信息
+type LeagueMembership struct {
+	ID          uint64    `json:"id"`
+	LeagueID    uint64    `json:"league_id"`
+	DivisionID  uint64    `json:"division_id"`
+	UserID      uint64    `json:"user_id"`
+	Tier        int       `json:"tier"`
+	TierName    string    `json:"tier_name"`
+	FinalRank   uint64    `json:"final_rank"`   // 赛季结束时的名次，未结算时为0
+	MetricValue float64   `json:"metric_value"` // 赛季结束时的指标值
+	Outcome     string    `json:"outcome"`      // 升降级结果，未结算时为空
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	League *League `json:"league,omitempty"` // 所属赛季，查询参赛历史时填充
+}
+
+// NextTier 根据本赛季结果计算下个赛季的段位
+func (m *LeagueMembership) NextTier() int {
+	tier := m.Tier
+	switch m.Outcome {
+	case LeagueOutcomePromoted:
+		tier++
+	case LeagueOutcomeRelegated:
+		tier--
+	}
+	if tier < LeagueMinTier {
+		return LeagueMinTier
+	}
+	if tier > LeagueMaxTier {
+		return LeagueMaxTier
+	}
+	return tier
+}
+
+// LeagueStanding 分组积分榜中的一项
+type LeagueStanding struct {
+	*RankingItem
+	Zone string `json:"zone"` // 所在区域: promotion-升级区, relegation-降级区, 空表示保级区
+}
+
+// LeagueOverview 用户当前赛季的概览
+type LeagueOverview struct {
+	League        *League           `json:"league"`
+	Membership    *LeagueMembership `json:"membership"` // 未参赛时为空
+	Division      *LeagueDivision   `json:"division"`
+	Standings     []*LeagueStanding `json:"standings"`
+	PromoteCount  int               `json:"promote_count"`
+	RelegateCount int               `json:"relegate_count"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// 通知类型
+const (
+	NotificationTypeNudge           = "nudge"            // 好友戳了戳你
+	NotificationTypeChallengeInvite = "challenge_invite" // 好友邀请你参加挑战
+	NotificationTypeChallengeResult = "challenge_result" // 参加的挑战已结束，公布获胜者
+	NotificationTypeBadge           = "badge"            // 获得了新徽章
+)
+
+// Notification 站内通知，保存在接收者的收件箱中
+type Notification struct {
+	ID        uint64     `json:"id"`
+	UserID    uint64     `json:"user_id"`  // 接收者
+	ActorID   uint64     `json:"actor_id"` // 触发通知的用户，系统通知为0
+	Type      string     `json:"type"`
+	Content   string     `json:"content"`
+	RefID     uint64     `json:"ref_id,omitempty"` // 关联对象ID，含义由通知类型决定
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// 关联对象，不存储在数据库中
+	Actor *User `json:"actor,omitempty"`
+}
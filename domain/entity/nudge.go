@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// Nudge 戳一戳，提醒今天还没有记录的好友，每个发送者每天对同一好友只能戳一次
+type Nudge struct {
+	ID        uint64    `json:"id"`
+	SenderID  uint64    `json:"sender_id"`
+	TargetID  uint64    `json:"target_id"`
+	Day       time.Time `json:"day"` // 发送当天0点（东八区）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NudgeSetting 用户的戳一戳设置，没有设置时视为接收
+type NudgeSetting struct {
+	UserID    uint64    `json:"user_id"`
+	Muted     bool      `json:"muted"` // 不再接收好友的戳一戳
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultNudgeSetting 用户没有设置时的默认戳一戳设置
+func DefaultNudgeSetting(userID uint64) *NudgeSetting {
+	return &NudgeSetting{UserID: userID}
+}
@@ -1,11 +1,54 @@
 package entity
 
+// 排行榜指标
+const (
+	RankingMetricCount         = "count"          // 记录次数
+	RankingMetricTotalDuration = "total_duration" // 总时长(秒)
+	RankingMetricAvgDuration   = "avg_duration"   // 平均时长(秒)
+	RankingMetricStreak        = "streak"         // 最长连续打卡天数
+	RankingMetricHealthScore   = "health_score"   // 健康分
+
+	// RankingMetricHealthyCount 3-4型记录次数，只用于好友挑战，不在排行榜中提供
+	RankingMetricHealthyCount = "healthy_count"
+)
+
+// RankingMetrics 支持的排行榜指标
+var RankingMetrics = []string{
+	RankingMetricCount,
+	RankingMetricTotalDuration,
+	RankingMetricAvgDuration,
+	RankingMetricStreak,
+	RankingMetricHealthScore,
+}
+
+// IsValidRankingMetric 判断是否为支持的排行榜指标
+func IsValidRankingMetric(metric string) bool {
+	for _, m := range RankingMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
 // RankingItem 排行榜项目
 type RankingItem struct {
-	Rank       uint64 `json:"rank"`
-	UserID     uint64 `json:"user_id"`
-	Nickname   string `json:"nickname"`
-	AvatarURL  string `json:"avatar_url"`
-	RecordCount int64  `json:"record_count"`
-	TotalDuration int64 `json:"total_duration"`
-}
\ No newline at end of file
+	Rank          uint64  `json:"rank"`
+	UserID        uint64  `json:"user_id"`
+	Nickname      string  `json:"nickname"`
+	AvatarURL     string  `json:"avatar_url"`
+	RecordCount   int64   `json:"record_count"`
+	TotalDuration int64   `json:"total_duration"`
+	Metric        string  `json:"metric"`
+	MetricValue   float64 `json:"metric_value"`
+	PreviousRank  *uint64 `json:"previous_rank" gorm:"-"` // 上一个周期结束时的名次，没有快照时为空
+	RankDelta     *int64  `json:"rank_delta" gorm:"-"`    // 名次变化，正数表示上升
+	Anonymous     bool    `json:"anonymous" gorm:"-"`     // 是否匿名显示，匿名时客户端显示通用头像
+}
+
+// RankingPosition 用户在排行榜中的位置及前后相邻的用户
+type RankingPosition struct {
+	Me    *RankingItem `json:"me"`    // 用户自己，没有上榜时排名为0
+	Above *RankingItem `json:"above"` // 排在前一位的用户
+	Below *RankingItem `json:"below"` // 排在后一位的用户
+}
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// 排行榜可见性
+const (
+	RankingVisibilityVisible     = "visible"      // 公开显示昵称和头像
+	RankingVisibilityAnonymous   = "anonymous"    // 在全局排行榜中匿名显示
+	RankingVisibilityFriendsOnly = "friends_only" // 只出现在好友排行榜中
+	RankingVisibilityExcluded    = "excluded"     // 不参与任何排行榜
+)
+
+// AnonymousNickname 匿名用户在排行榜中显示的昵称
+const AnonymousNickname = "神秘用户"
+
+// RankingVisibilitiesHiddenFromGlobal 不出现在全局排行榜中的可见性
+var RankingVisibilitiesHiddenFromGlobal = []string{
+	RankingVisibilityFriendsOnly,
+	RankingVisibilityExcluded,
+}
+
+// IsValidRankingVisibility 判断是否为支持的排行榜可见性
+func IsValidRankingVisibility(visibility string) bool {
+	switch visibility {
+	case RankingVisibilityVisible, RankingVisibilityAnonymous, RankingVisibilityFriendsOnly, RankingVisibilityExcluded:
+		return true
+	}
+	return false
+}
+
+// RankingSetting 用户的排行榜设置，没有设置时视为公开
+type RankingSetting struct {
+	UserID     uint64    `json:"user_id"`
+	Visibility string    `json:"visibility"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// VisibleInGlobal 是否出现在全局排行榜中
+func (s *RankingSetting) VisibleInGlobal() bool {
+	return s.Visibility != RankingVisibilityFriendsOnly && s.Visibility != RankingVisibilityExcluded
+}
+
+// VisibleInFriends 是否出现在好友排行榜中
+func (s *RankingSetting) VisibleInFriends() bool {
+	return s.Visibility != RankingVisibilityExcluded
+}
+
+// DefaultRankingSetting 用户没有设置时的默认排行榜设置
+func DefaultRankingSetting(userID uint64) *RankingSetting {
+	return &RankingSetting{UserID: userID, Visibility: RankingVisibilityVisible}
+}
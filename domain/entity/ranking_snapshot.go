@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// 排行榜范围
+const (
+	RankingScopeGlobal  = "global"  // 全局排行榜
+	RankingScopeFriends = "friends" // 好友排行榜
+)
+
+// 排行榜快照周期
+const (
+	RankingPeriodDaily   = "daily"
+	RankingPeriodWeekly  = "weekly"
+	RankingPeriodMonthly = "monthly"
+)
+
+// RankingPeriods 支持的排行榜快照周期
+var RankingPeriods = []string{
+	RankingPeriodDaily,
+	RankingPeriodWeekly,
+	RankingPeriodMonthly,
+}
+
+// IsValidRankingPeriod 判断是否为支持的排行榜快照周期
+func IsValidRankingPeriod(period string) bool {
+	for _, p := range RankingPeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// RankingSnapshot 排行榜快照，记录周期结束时某个用户在某个榜单中的名次
+type RankingSnapshot struct {
+	ID          uint64    `json:"id"`
+	Scope       string    `json:"scope"`
+	OwnerID     uint64    `json:"owner_id"` // 好友排行榜所属用户，全局排行榜为0
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Metric      string    `json:"metric"`
+	UserID      uint64    `json:"user_id"`
+	Rank        uint64    `json:"rank"`
+	MetricValue float64   `json:"metric_value"`
+	RecordCount int64     `json:"record_count"`
+	Total       int       `json:"total"` // 榜单总人数
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// 回顾周期类型
+const (
+	RecapPeriodMonth = "month" // 月度回顾
+	RecapPeriodYear  = "year"  // 年度回顾
+)
+
+// Recap 月度/年度回顾实体，生成后不可修改
+type Recap struct {
+	ID                 uint64            `json:"id"`
+	UserID             uint64            `json:"user_id"`
+	PeriodType         string            `json:"period_type"`
+	PeriodStart        time.Time         `json:"period_start"`
+	PeriodEnd          time.Time         `json:"period_end"`
+	TotalCount         int64             `json:"total_count"`          // 记录总数
+	TotalDuration      int64             `json:"total_duration"`       // 总时长(秒)
+	BusiestDay         *time.Time        `json:"busiest_day"`          // 记录最多的一天
+	BusiestDayCount    int               `json:"busiest_day_count"`    // 最忙一天的记录数
+	FavoriteHour       int               `json:"favorite_hour"`        // 最常记录的小时(0-23)，无记录时为-1
+	LongestSessionID   uint64            `json:"longest_session_id"`   // 时长最长的记录ID
+	LongestSession     int               `json:"longest_session"`      // 最长单次时长(秒)
+	TypeDistribution   map[uint64]int64  `json:"type_distribution"`    // 屎类型ID -> 次数
+	FriendRankHistory  []*RecapRankPoint `json:"friend_rank_history"`  // 好友排名变化
+	LongestStreak      int               `json:"longest_streak"`       // 周期内最长连续打卡天数
+	PreviousBestStreak int               `json:"previous_best_streak"` // 周期开始前的历史最长连续天数
+	NewStreakRecord    bool              `json:"new_streak_record"`    // 是否刷新了连续打卡记录
+	CreatedAt          time.Time         `json:"created_at"`
+}
+
+// RecapRankPoint 回顾中的好友排名节点
+type RecapRankPoint struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Rank        uint64    `json:"rank"`
+	Total       int       `json:"total"`
+	RecordCount int64     `json:"record_count"`
+}
@@ -10,6 +10,8 @@ type Record struct {
 	Duration   int       `json:"duration"`
 	PoopTypeID uint64    `json:"poop_type_id"`
 	Note       string    `json:"note"`
+	Visibility string    `json:"visibility"`          // 可见范围，见 RecordVisibility* 常量
+	CircleID   uint64    `json:"circle_id,omitempty"` // 可见范围为分组时可见的好友分组
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// 反作弊规则
+const (
+	CheatRuleOverlap   = "overlap"   // 与其他记录的时间段重叠
+	CheatRuleDailyCap  = "daily_cap" // 超过单日记录上限
+	CheatRuleDuration  = "duration"  // 时长不合理
+	CheatRuleBackdated = "backdated" // 补记的时间过早
+	CheatRuleBurst     = "burst"     // 短时间内集中提交
+)
+
+// 标记状态
+const (
+	RecordFlagStatusPending   = "pending"   // 待审核
+	RecordFlagStatusAppealed  = "appealed"  // 用户已申诉，待复核
+	RecordFlagStatusDismissed = "dismissed" // 审核通过，记录恢复正常
+	RecordFlagStatusConfirmed = "confirmed" // 确认作弊
+)
+
+// RecordFlagStatusesExcluded 记录被排除在排行榜之外的标记状态
+var RecordFlagStatusesExcluded = []string{
+	RecordFlagStatusPending,
+	RecordFlagStatusAppealed,
+	RecordFlagStatusConfirmed,
+}
+
+// RecordFlagStatusesInQueue 审核队列中的标记状态
+var RecordFlagStatusesInQueue = []string{
+	RecordFlagStatusPending,
+	RecordFlagStatusAppealed,
+}
+
+// RecordFlag 记录的反作弊标记，每条记录最多一个标记
+type RecordFlag struct {
+	ID           uint64     `json:"id"`
+	RecordID     uint64     `json:"record_id"`
+	UserID       uint64     `json:"user_id"`
+	Rules        []string   `json:"rules"`  // 命中的规则
+	Reason       string     `json:"reason"` // 命中规则的说明
+	Status       string     `json:"status"`
+	AppealReason string     `json:"appeal_reason"`
+	AppealedAt   *time.Time `json:"appealed_at"`
+	ReviewerID   uint64     `json:"reviewer_id"`
+	ReviewNote   string     `json:"review_note"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// 关联对象，不存储在数据库中
+	Record *Record `json:"record,omitempty" gorm:"-"`
+}
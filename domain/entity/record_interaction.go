@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// 记录可用的表情回应
+const (
+	ReactionPoop  = "💩"
+	ReactionLike  = "👍"
+	ReactionLaugh = "😂"
+)
+
+// ReactionEmojis 支持的表情回应（按展示顺序）
+var ReactionEmojis = []string{
+	ReactionPoop,
+	ReactionLike,
+	ReactionLaugh,
+}
+
+// IsValidReactionEmoji 判断是否为支持的表情回应
+func IsValidReactionEmoji(emoji string) bool {
+	for _, e := range ReactionEmojis {
+		if e == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordCommentMaxLength 评论内容的最大字数
+const RecordCommentMaxLength = 200
+
+// RecordReaction 对记录的表情回应，同一用户对同一记录的每种表情只能回应一次
+type RecordReaction struct {
+	ID        uint64    `json:"id"`
+	RecordID  uint64    `json:"record_id"`
+	UserID    uint64    `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// 关联对象，不存储在数据库中
+	User *User `json:"user,omitempty"`
+}
+
+// RecordComment 记录的评论
+// 评论只有两层：ParentID 为0的是一级评论，回复统一挂在一级评论下，ReplyToUserID 标明回复的对象
+type RecordComment struct {
+	ID            uint64    `json:"id"`
+	RecordID      uint64    `json:"record_id"`
+	UserID        uint64    `json:"user_id"`
+	ParentID      uint64    `json:"parent_id"`
+	ReplyToUserID uint64    `json:"reply_to_user_id,omitempty"`
+	Content       string    `json:"content"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// 关联对象，不存储在数据库中
+	User        *User            `json:"user,omitempty"`
+	ReplyToUser *User            `json:"reply_to_user,omitempty"`
+	Replies     []*RecordComment `json:"replies,omitempty"`
+}
+
+// RecordInteractions 记录的回应和评论汇总
+type RecordInteractions struct {
+	Reactions    map[string]int64 `json:"reactions"`    // 各表情的回应数
+	MyReactions  []string         `json:"my_reactions"` // 查看者自己的回应
+	CommentCount int64            `json:"comment_count"`
+}
+
+// NewRecordInteractions 创建空的回应和评论汇总
+func NewRecordInteractions() *RecordInteractions {
+	return &RecordInteractions{Reactions: map[string]int64{}, MyReactions: []string{}}
+}
@@ -0,0 +1,84 @@
+package entity
+
+import "time"
+
+// 记录可见范围
+const (
+	RecordVisibilityPrivate = "private" // 仅自己可见
+	RecordVisibilityFriends = "friends" // 好友可见
+	RecordVisibilityCircle  = "circle"  // 指定好友分组可见
+	RecordVisibilityPublic  = "public"  // 所有人可见
+)
+
+// 汇总统计的结果由多个查看者共用，无法按单个查看者的好友关系和分组过滤，只能统计对所有查看者都可见的记录：
+// 全局排行榜、榜单快照、小组、挑战、联赛和全体用户对比基准的查看者不一定是好友，只统计公开的记录；
+// 好友排行榜和好友对比基准的查看者都是好友，额外统计好友可见的记录；
+// 目标进度、回顾、预测、徽章等只给本人看的统计仍包含全部记录
+
+// RecordVisibilitiesPublic 计入面向所有用户的汇总统计的记录可见范围
+var RecordVisibilitiesPublic = []string{RecordVisibilityPublic}
+
+// RecordVisibilitiesFriends 计入好友之间汇总统计的记录可见范围
+var RecordVisibilitiesFriends = []string{RecordVisibilityFriends, RecordVisibilityPublic}
+
+// DefaultRecordVisibility 用户没有设置时新记录的可见范围
+const DefaultRecordVisibility = RecordVisibilityFriends
+
+// IsValidRecordVisibility 判断是否为支持的记录可见范围
+func IsValidRecordVisibility(visibility string) bool {
+	switch visibility {
+	case RecordVisibilityPrivate, RecordVisibilityFriends, RecordVisibilityCircle, RecordVisibilityPublic:
+		return true
+	}
+	return false
+}
+
+// RecordVisibilitySetting 用户新记录的默认可见范围
+type RecordVisibilitySetting struct {
+	UserID     uint64    `json:"user_id"`
+	Visibility string    `json:"visibility"`
+	CircleID   uint64    `json:"circle_id,omitempty"` // 可见范围为分组时的默认分组
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// DefaultRecordVisibilitySetting 用户没有设置时的默认可见范围设置
+func DefaultRecordVisibilitySetting(userID uint64) *RecordVisibilitySetting {
+	return &RecordVisibilitySetting{UserID: userID, Visibility: DefaultRecordVisibility}
+}
+
+// RecordAccess 查看者对某个用户记录的访问权限
+type RecordAccess struct {
+	OwnerID   uint64
+	Self      bool     // 查看自己的记录
+	Friend    bool     // 查看者是记录主人的好友
+	Blocked   bool     // 双方之间存在屏蔽关系
+	CircleIDs []uint64 // 记录主人的分组中包含查看者的分组
+}
+
+// CanView 查看者能否看到该记录
+// 屏蔽关系下连公开的记录也不可见；分组可见的记录要求查看者仍是好友
+func (a *RecordAccess) CanView(record *Record) bool {
+	if a.Self {
+		return true
+	}
+	if a.Blocked {
+		return false
+	}
+
+	switch record.Visibility {
+	case RecordVisibilityPublic:
+		return true
+	case RecordVisibilityFriends:
+		return a.Friend
+	case RecordVisibilityCircle:
+		if !a.Friend {
+			return false
+		}
+		for _, circleID := range a.CircleIDs {
+			if circleID == record.CircleID {
+				return true
+			}
+		}
+	}
+	return false
+}
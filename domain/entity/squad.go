@@ -0,0 +1,96 @@
+package entity
+
+import "time"
+
+// 小组成员角色
+const (
+	SquadRoleOwner  = "owner"  // 创建者，可以管理成员、设置管理员和解散小组
+	SquadRoleAdmin  = "admin"  // 管理员，可以修改小组信息、管理普通成员和邀请链接
+	SquadRoleMember = "member" // 普通成员
+)
+
+// SquadNameMaxLength 小组名称最大字符数
+const SquadNameMaxLength = 20
+
+// SquadInviteScenePrefix 小组邀请小程序码scene参数的前缀，scene格式为"s=邀请码"
+const SquadInviteScenePrefix = "s="
+
+// 小组排行榜指标
+const (
+	SquadMetricCount    = "count"     // 成员记录总次数
+	SquadMetricAvgCount = "avg_count" // 人均记录次数
+)
+
+// IsValidSquadMetric 判断是否为支持的小组排行榜指标
+func IsValidSquadMetric(metric string) bool {
+	return metric == SquadMetricCount || metric == SquadMetricAvgCount
+}
+
+// Squad 用户创建的小组，与好友关系无关，成员之间不一定是好友
+type Squad struct {
+	ID          uint64    `json:"id"`
+	OwnerID     uint64    `json:"owner_id"`
+	Name        string    `json:"name"`
+	InviteCode  string    `json:"invite_code,omitempty"` // 仅创建者和管理员可见
+	MemberCap   int       `json:"member_cap"`
+	MemberCount int64     `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 查询者在小组中的角色，不存储在数据库中
+	MyRole string `json:"my_role,omitempty"`
+}
+
+// SquadMember 小组成员
+type SquadMember struct {
+	ID       uint64    `json:"id"`
+	SquadID  uint64    `json:"squad_id"`
+	UserID   uint64    `json:"user_id"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+
+	// 关联对象，不存储在数据库中
+	User *User `json:"user,omitempty"`
+}
+
+// CanManage 成员能否管理小组信息和邀请链接
+func (m *SquadMember) CanManage() bool {
+	return m.Role == SquadRoleOwner || m.Role == SquadRoleAdmin
+}
+
+// SquadInvite 小组邀请链接
+type SquadInvite struct {
+	Code  string `json:"code"`
+	Scene string `json:"scene"` // 小程序码的scene参数
+	Path  string `json:"path"`  // 分享卡片打开的小程序页面路径
+}
+
+// DailyRecordCount 某一天的记录汇总
+type DailyRecordCount struct {
+	Date          time.Time `json:"date"`
+	RecordCount   int64     `json:"record_count"`
+	TotalDuration int64     `json:"total_duration"`
+	ActiveUsers   int64     `json:"active_users"` // 当天有记录的人数
+}
+
+// SquadDashboard 小组在某个周期内的每日汇总和成员排行
+type SquadDashboard struct {
+	Squad       *Squad              `json:"squad"`
+	Period      string              `json:"period"`
+	PeriodStart time.Time           `json:"period_start"`
+	PeriodEnd   time.Time           `json:"period_end"`
+	Daily       []*DailyRecordCount `json:"daily"`
+	Rankings    []*RankingItem      `json:"rankings"`
+}
+
+// SquadRankingItem 小组排行榜项目
+type SquadRankingItem struct {
+	Rank          uint64  `json:"rank"`
+	SquadID       uint64  `json:"squad_id"`
+	Name          string  `json:"name"`
+	MemberCount   int64   `json:"member_count"`
+	RecordCount   int64   `json:"record_count"`
+	TotalDuration int64   `json:"total_duration"`
+	Metric        string  `json:"metric"`
+	MetricValue   float64 `json:"metric_value"`
+}
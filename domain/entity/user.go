@@ -11,4 +11,12 @@ type User struct {
 	Status    int8      `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// UserProfile 用户资料，包含获得的徽章；查看他人资料时附带与查看者的共同好友
+type UserProfile struct {
+	*User
+	MutualFriendCount int          `json:"mutual_friend_count"`
+	MutualFriends     []*User      `json:"mutual_friends"`
+	Badges            []*UserBadge `json:"badges"`
+}
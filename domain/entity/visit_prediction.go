@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// 作息画像类型
+const (
+	ProfileWeekday = "weekday" // 工作日
+	ProfileWeekend = "weekend" // 周末
+)
+
+// VisitPrediction 基于作息规律预测的未来24小时如厕概率
+type VisitPrediction struct {
+	UserID         uint64                    `json:"user_id"`
+	GeneratedAt    time.Time                 `json:"generated_at"`
+	Hours          []*HourlyVisitProbability `json:"hours"`           // 未来24个整点时段的概率
+	MostLikely     *HourlyVisitProbability   `json:"most_likely"`     // 概率最高的时段，没有历史数据时为空
+	ExpectedVisits float64                   `json:"expected_visits"` // 未来24小时的期望次数
+	SampleDays     int                       `json:"sample_days"`     // 参与建模的天数
+}
+
+// HourlyVisitProbability 单个整点时段的如厕概率
+type HourlyVisitProbability struct {
+	Start       time.Time `json:"start"`
+	Hour        int       `json:"hour"`
+	Profile     string    `json:"profile"`     // 使用的作息画像: weekday/weekend
+	Probability float64   `json:"probability"` // 该时段至少记录一次的概率
+}
+
+// NextLikely 获取未来24小时内第一个概率不低于阈值的时段，可作为提醒的输入，没有满足条件的时段时返回nil
+func (p *VisitPrediction) NextLikely(threshold float64) *HourlyVisitProbability {
+	for _, slot := range p.Hours {
+		if slot.Probability > 0 && slot.Probability >= threshold {
+			return slot
+		}
+	}
+	return nil
+}
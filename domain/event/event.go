@@ -0,0 +1,129 @@
+package event
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// 事件名称
+const (
+	RecordCreated = "record.created" // 记录已创建
+	RecordUpdated = "record.updated" // 记录已更新
+	RecordDeleted = "record.deleted" // 记录已删除
+	GoalMet       = "goal.met"       // 目标已达成
+	GoalRevoked   = "goal.revoked"   // 目标的达成已撤销
+
+	RankingSettingChanged = "ranking_setting.changed" // 排行榜设置已变更
+	RecordFlagged         = "record.flagged"          // 记录的反作弊标记已创建或审核
+	RankingSnapshotTaken  = "ranking_snapshot.taken"  // 全局排行榜快照已生成
+	FriendAccepted        = "friend.accepted"         // 双方已成为好友
+	BadgeAwarded          = "badge.awarded"           // 用户获得了徽章
+)
+
+// Event 领域事件
+type Event interface {
+	EventName() string
+}
+
+// Handler 事件处理函数
+type Handler func(ctx context.Context, e Event)
+
+// Publisher 事件发布接口
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Subscriber 事件订阅接口
+type Subscriber interface {
+	Subscribe(name string, handler Handler)
+}
+
+// RecordChanged 记录变更事件，Previous仅在更新和删除时有值
+type RecordChanged struct {
+	Name     string
+	Record   *entity.Record
+	Previous *entity.Record
+}
+
+// EventName 事件名称
+func (e *RecordChanged) EventName() string {
+	return e.Name
+}
+
+// GoalMetEvent 目标达成事件
+type GoalMetEvent struct {
+	Goal       *entity.Goal
+	Completion *entity.GoalCompletion
+}
+
+// EventName 事件名称
+func (e *GoalMetEvent) EventName() string {
+	return GoalMet
+}
+
+// GoalRevokedEvent 目标达成撤销事件，周期结束前记录被删除或修改导致不再满足目标时发布
+type GoalRevokedEvent struct {
+	Goal       *entity.Goal
+	Completion *entity.GoalCompletion
+}
+
+// EventName 事件名称
+func (e *GoalRevokedEvent) EventName() string {
+	return GoalRevoked
+}
+
+// RankingSettingChangedEvent 排行榜设置变更事件
+type RankingSettingChangedEvent struct {
+	Setting  *entity.RankingSetting
+	Previous *entity.RankingSetting
+}
+
+// EventName 事件名称
+func (e *RankingSettingChangedEvent) EventName() string {
+	return RankingSettingChanged
+}
+
+// RecordFlagChanged 记录的反作弊标记创建或审核后的事件
+type RecordFlagChanged struct {
+	Flag   *entity.RecordFlag
+	Record *entity.Record
+}
+
+// EventName 事件名称
+func (e *RecordFlagChanged) EventName() string {
+	return RecordFlagged
+}
+
+// RankingSnapshotTakenEvent 某个周期的全局排行榜快照生成后的事件
+type RankingSnapshotTakenEvent struct {
+	Period      string
+	Metric      string
+	PeriodStart time.Time
+	Snapshots   []*entity.RankingSnapshot
+}
+
+// EventName 事件名称
+func (e *RankingSnapshotTakenEvent) EventName() string {
+	return RankingSnapshotTaken
+}
+
+// FriendAcceptedEvent 双方成为好友后的事件，包括通过好友申请和通过邀请码直接成为好友
+type FriendAcceptedEvent struct {
+	Relation *entity.Friend
+}
+
+// EventName 事件名称
+func (e *FriendAcceptedEvent) EventName() string {
+	return FriendAccepted
+}
+
+// BadgeAwardedEvent 用户获得徽章的事件，回溯补发的徽章不发布
+type BadgeAwardedEvent struct {
+	Badge *entity.UserBadge
+}
+
+// EventName 事件名称
+func (e *BadgeAwardedEvent) EventName() string {
+	return BadgeAwarded
+}
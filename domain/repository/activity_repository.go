@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// ActivityRepository 好友动态仓储接口
+// 目前按读扩散实现：动态只按产生者保存一份，读取时按好友列表合并查询。
+// 好友数较多时可以改为写扩散，在保存时写入每个好友的收件箱，由 FindFeed 直接读取收件箱，调用方无需修改
+type ActivityRepository interface {
+	// Save 保存动态，同一用户的去重键已存在时忽略
+	Save(ctx context.Context, activity *entity.Activity) error
+
+	// SaveBatch 批量保存动态，去重键已存在的忽略
+	SaveBatch(ctx context.Context, activities []*entity.Activity) error
+
+	// UpdateByRecord 将记录的可见范围和时间同步到与该记录相关的动态
+	UpdateByRecord(ctx context.Context, record *entity.Record) error
+
+	// DeleteByRecordID 删除与记录相关的动态
+	DeleteByRecordID(ctx context.Context, recordID uint64) error
+
+	// DeleteByRefKey 删除用户指定去重键的动态
+	DeleteByRefKey(ctx context.Context, actorID uint64, refKey string) error
+
+	// FindFeed 查询actorIDs产生的、查看者可见的动态（按发生时间倒序）
+	// circleIDs 为这些用户的分组中包含查看者的分组，cursor 为空时从最新开始
+	FindFeed(ctx context.Context, actorIDs, circleIDs []uint64, cursor *entity.FeedCursor, limit int) ([]*entity.Activity, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// BlockRepository 屏蔽关系仓储接口
+type BlockRepository interface {
+	// Save 保存屏蔽关系，同时解除双方之间的好友关系和好友申请，已屏蔽时忽略
+	Save(ctx context.Context, block *entity.Block) error
+
+	// Delete 解除屏蔽，屏蔽关系不存在时返回false
+	Delete(ctx context.Context, userID, blockedID uint64) (bool, error)
+
+	// FindByUserID 分页查询用户屏蔽的人
+	FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Block, int64, error)
+
+	// IsBlocked 判断两个用户之间是否有任意一方屏蔽了另一方
+	IsBlocked(ctx context.Context, userID, otherID uint64) (bool, error)
+
+	// FindBlockedIDs 获取与用户存在屏蔽关系的用户ID，包括用户屏蔽的人和屏蔽了用户的人
+	FindBlockedIDs(ctx context.Context, userID uint64) ([]uint64, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// ChallengeRepository 好友挑战仓储接口
+type ChallengeRepository interface {
+	FindByID(ctx context.Context, id uint64) (*entity.Challenge, error)
+
+	// Create 在同一事务中保存挑战和参与者
+	Create(ctx context.Context, challenge *entity.Challenge, participants []*entity.ChallengeParticipant) error
+
+	// FindByParticipant 分页查询用户参与的挑战，并填写用户的参与状态和最终名次
+	// settled为false时查询未结算且已参加或待回应的挑战（按结束时间升序），为true时查询已参加且已结算的挑战（按结束时间倒序）
+	FindByParticipant(ctx context.Context, userID uint64, settled bool, page, size int) ([]*entity.Challenge, int64, error)
+
+	// FindDueActive 查找已到结束时间但尚未结算的挑战
+	FindDueActive(ctx context.Context, now time.Time) ([]*entity.Challenge, error)
+
+	// Settle 保存参与者的结算结果并将挑战标记为已结算，挑战已被结算时返回false
+	Settle(ctx context.Context, challenge *entity.Challenge, participants []*entity.ChallengeParticipant) (bool, error)
+
+	// FindParticipant 查询用户在挑战中的参与信息，不是参与者时返回nil
+	FindParticipant(ctx context.Context, challengeID, userID uint64) (*entity.ChallengeParticipant, error)
+
+	// FindParticipants 查询挑战的全部参与者（按加入顺序）
+	FindParticipants(ctx context.Context, challengeID uint64) ([]*entity.ChallengeParticipant, error)
+
+	// Respond 回应挑战邀请，只有待回应的邀请可以修改，状态已变化时返回false
+	Respond(ctx context.Context, challengeID, userID uint64, status string, respondedAt time.Time) (bool, error)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// CircleRepository 好友分组仓储接口
+type CircleRepository interface {
+	FindByID(ctx context.Context, id uint64) (*entity.Circle, error)
+
+	// FindByUserID 获取用户创建的分组（按排序），并统计各分组中仍是好友的成员数
+	FindByUserID(ctx context.Context, userID uint64) ([]*entity.Circle, error)
+
+	// CountByUserID 统计用户创建的分组数
+	CountByUserID(ctx context.Context, userID uint64) (int64, error)
+
+	// Save 保存分组，排在用户现有分组的最后
+	Save(ctx context.Context, circle *entity.Circle) error
+
+	Update(ctx context.Context, circle *entity.Circle) error
+
+	// Delete 删除分组及其成员
+	Delete(ctx context.Context, id uint64) error
+
+	// Reorder 按给定顺序重排用户的分组，circleIDs 必须都属于该用户
+	Reorder(ctx context.Context, userID uint64, circleIDs []uint64) error
+
+	// AddMembers 添加分组成员，已在分组中的忽略
+	AddMembers(ctx context.Context, circleID uint64, memberIDs []uint64) error
+
+	// RemoveMember 移除分组成员，成员不在分组中时返回false
+	RemoveMember(ctx context.Context, circleID, memberID uint64) (bool, error)
+
+	// FindIDsContainingMember 查找指定用户创建的、包含某个成员的分组ID，按创建者分组
+	FindIDsContainingMember(ctx context.Context, ownerIDs []uint64, memberID uint64) (map[uint64][]uint64, error)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"record-project/domain/entity"
+	"time"
 )
 
 // FriendRepository 好友关系仓储接口
@@ -13,11 +14,15 @@ type FriendRepository interface {
 	// FindFriendIDs 获取用户的好友ID列表(已确认的好友)
 	FindFriendIDs(ctx context.Context, userID uint64) ([]uint64, error)
 
-	// Save 保存好友关系
+	// FindAllFriendIDs 获取所有用户的好友ID列表(已确认的好友)，供批量任务使用
+	FindAllFriendIDs(ctx context.Context) (map[uint64][]uint64, error)
+
+	// Save 新建好友关系，双方存在屏蔽或已有关系记录时返回错误
 	Save(ctx context.Context, friend *entity.Friend) error
 
-	// Update 更新好友关系
-	Update(ctx context.Context, friend *entity.Friend) error
+	// Update 在关系仍处于from状态时更新方向、状态及各状态的变更时间
+	// 关系已被其他请求改为别的状态时不更新并返回false
+	Update(ctx context.Context, friend *entity.Friend, from entity.FriendStatus) (bool, error)
 
 	// Delete 删除好友关系
 	Delete(ctx context.Context, id uint64, userID uint64) error
@@ -25,12 +30,34 @@ type FriendRepository interface {
 	// FindByUserIDAndFriendID 查找特定的好友关系
 	FindByUserIDAndFriendID(ctx context.Context, userID, friendID uint64) (*entity.Friend, error)
 
-	// FindByUserIDWithPagination 分页查询用户的好友列表
-	FindByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string) ([]*entity.Friend, int64, error)
+	// FindByUserIDWithPagination 分页查询用户的好友列表，circleID 大于0时只查询该分组中的好友
+	FindByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string, circleID uint64) ([]*entity.Friend, int64, error)
 
 	// FindFriendRequestsByUserID 查询发送给用户的好友申请
 	FindFriendRequestsByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Friend, int64, error)
 
 	// FindByID 通过ID查找好友关系
 	FindByID(ctx context.Context, id uint64) (*entity.Friend, error)
+
+	// FindSentRequestsByUserID 查询用户发出的待确认好友申请
+	FindSentRequestsByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Friend, int64, error)
+
+	// CountPendingSent 统计用户发出的待确认好友申请数量
+	CountPendingSent(ctx context.Context, userID uint64) (int64, error)
+
+	// DeleteExpiredRequests 删除最近一次发起申请早于before的待确认好友申请，返回删除的数量
+	DeleteExpiredRequests(ctx context.Context, before time.Time) (int64, error)
+
+	// FindMutualFriendIDs 获取两个用户的共同好友ID(已确认的好友)
+	FindMutualFriendIDs(ctx context.Context, userID, otherID uint64) ([]uint64, error)
+
+	// FindRelatedUserIDs 获取与用户存在任意状态好友关系（含待确认、已拒绝）的用户ID
+	FindRelatedUserIDs(ctx context.Context, userID uint64) ([]uint64, error)
+
+	// FindSuggestionCandidates 获取与用户有共同好友的非好友用户及共同好友数（按共同好友数降序）
+	// 已存在任意状态好友关系或屏蔽关系的用户不在结果中
+	FindSuggestionCandidates(ctx context.Context, userID uint64, limit int) ([]*entity.MutualFriendCount, error)
+
+	// FindCircleFriendIDs 获取分组中仍是用户好友的成员ID
+	FindCircleFriendIDs(ctx context.Context, userID, circleID uint64) ([]uint64, error)
 }
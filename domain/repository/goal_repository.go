@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// GoalRepository 个人目标仓储接口
+type GoalRepository interface {
+	FindByID(ctx context.Context, id uint64) (*entity.Goal, error)
+	FindByUserID(ctx context.Context, userID uint64, includeArchived bool) ([]*entity.Goal, error)
+	Save(ctx context.Context, goal *entity.Goal) error
+	Update(ctx context.Context, goal *entity.Goal) error
+	Delete(ctx context.Context, id uint64) error
+
+	// FindAllActive 获取所有进行中的目标，供结算任务使用
+	FindAllActive(ctx context.Context) ([]*entity.Goal, error)
+}
+
+// GoalCompletionRepository 目标完成记录仓储接口
+type GoalCompletionRepository interface {
+	FindByGoalAndPeriod(ctx context.Context, goalID uint64, periodStart time.Time) (*entity.GoalCompletion, error)
+	FindByGoalID(ctx context.Context, goalID uint64, page, size int) ([]*entity.GoalCompletion, int64, error)
+	FindLatestSettled(ctx context.Context, goalID uint64) (*entity.GoalCompletion, error)
+	Save(ctx context.Context, completion *entity.GoalCompletion) error
+	Update(ctx context.Context, completion *entity.GoalCompletion) error
+}
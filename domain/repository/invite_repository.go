@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// InviteCodeRepository 好友邀请码仓储接口
+type InviteCodeRepository interface {
+	// FindActiveByUserID 查找用户当前有效的邀请码
+	FindActiveByUserID(ctx context.Context, userID uint64) (*entity.InviteCode, error)
+
+	// FindActiveByCode 根据邀请码查找，已失效的邀请码返回nil
+	FindActiveByCode(ctx context.Context, code string) (*entity.InviteCode, error)
+
+	// Rotate 使用户当前的邀请码失效并保存新的邀请码
+	Rotate(ctx context.Context, code *entity.InviteCode) error
+}
+
+// InviteRedemptionRepository 邀请记录仓储接口
+type InviteRedemptionRepository interface {
+	// Save 保存邀请记录，同一邀请人和被邀请人只记录一次，已存在时返回false
+	Save(ctx context.Context, redemption *entity.InviteRedemption) (bool, error)
+
+	// CountByInviterID 统计邀请人邀请成功的人数及其中的新用户人数
+	CountByInviterID(ctx context.Context, inviterID uint64) (total int64, newUsers int64, err error)
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// LeagueRepository 联赛赛季仓储接口
+type LeagueRepository interface {
+	FindByID(ctx context.Context, id uint64) (*entity.League, error)
+	FindByIDs(ctx context.Context, ids []uint64) (map[uint64]*entity.League, error)
+
+	// FindByPeriodStart 查找指定周期和开始时间的赛季
+	FindByPeriodStart(ctx context.Context, period string, startAt time.Time) (*entity.League, error)
+
+	// FindDueActive 查找已到结束时间但尚未结算的赛季
+	FindDueActive(ctx context.Context, period string, now time.Time) ([]*entity.League, error)
+
+	// FindLatestClosed 查找最近一个已结算的赛季
+	FindLatestClosed(ctx context.Context, period string) (*entity.League, error)
+
+	// CreateSeason 在同一事务中保存赛季、分组及分组成员，赛季已存在时返回错误
+	CreateSeason(ctx context.Context, league *entity.League, divisions []*entity.LeagueDivision) error
+
+	Update(ctx context.Context, league *entity.League) error
+}
+
+// LeagueDivisionRepository 联赛分组仓储接口
+type LeagueDivisionRepository interface {
+	FindByID(ctx context.Context, id uint64) (*entity.LeagueDivision, error)
+	FindByLeagueID(ctx context.Context, leagueID uint64) ([]*entity.LeagueDivision, error)
+	Save(ctx context.Context, division *entity.LeagueDivision) error
+}
+
+// LeagueMembershipRepository 联赛参赛信息仓储接口
+type LeagueMembershipRepository interface {
+	FindByLeagueAndUser(ctx context.Context, leagueID, userID uint64) (*entity.LeagueMembership, error)
+	FindByLeagueID(ctx context.Context, leagueID uint64) ([]*entity.LeagueMembership, error)
+	FindByDivisionID(ctx context.Context, divisionID uint64) ([]*entity.LeagueMembership, error)
+
+	// FindLatestSettledByUserID 查找用户最近一个已结算赛季的参赛信息
+	FindLatestSettledByUserID(ctx context.Context, userID uint64) (*entity.LeagueMembership, error)
+
+	// FindByUserID 分页查询用户的参赛历史（按赛季倒序）
+	FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.LeagueMembership, int64, error)
+
+	// CountByDivisionIDs 统计各分组的人数
+	CountByDivisionIDs(ctx context.Context, divisionIDs []uint64) (map[uint64]int64, error)
+
+	Save(ctx context.Context, membership *entity.LeagueMembership) error
+
+	// UpdateResults 批量保存赛季结算结果
+	UpdateResults(ctx context.Context, memberships []*entity.LeagueMembership) error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// NotificationRepository 站内通知仓储接口
+type NotificationRepository interface {
+	Save(ctx context.Context, notification *entity.Notification) error
+
+	// FindByUserID 分页查询用户收件箱中的通知（按时间倒序）
+	FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Notification, int64, error)
+
+	// CountUnread 统计用户的未读通知数
+	CountUnread(ctx context.Context, userID uint64) (int64, error)
+
+	// MarkRead 将用户的指定通知标记为已读，ids 为空时标记全部
+	MarkRead(ctx context.Context, userID uint64, ids []uint64, readAt time.Time) error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// NudgeRepository 戳一戳仓储接口
+type NudgeRepository interface {
+	// Save 保存戳一戳，发送者当天已戳过该好友时返回false
+	Save(ctx context.Context, nudge *entity.Nudge) (bool, error)
+}
+
+// NudgeSettingRepository 戳一戳设置仓储接口
+type NudgeSettingRepository interface {
+	// FindByUserID 查询用户的戳一戳设置，没有设置时返回nil
+	FindByUserID(ctx context.Context, userID uint64) (*entity.NudgeSetting, error)
+
+	// Save 保存用户的戳一戳设置，已存在时覆盖
+	Save(ctx context.Context, setting *entity.NudgeSetting) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// RankingSettingRepository 排行榜设置仓储接口
+type RankingSettingRepository interface {
+	// FindByUserID 查询用户的排行榜设置，没有设置时返回nil
+	FindByUserID(ctx context.Context, userID uint64) (*entity.RankingSetting, error)
+
+	// FindByUserIDs 批量查询用户的排行榜设置，没有设置的用户不在结果中
+	FindByUserIDs(ctx context.Context, userIDs []uint64) (map[uint64]*entity.RankingSetting, error)
+
+	// Save 保存用户的排行榜设置，已存在时覆盖
+	Save(ctx context.Context, setting *entity.RankingSetting) error
+}
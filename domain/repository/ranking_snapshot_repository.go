@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// RankingSnapshotRepository 排行榜快照仓储接口
+type RankingSnapshotRepository interface {
+	// SaveBatch 批量保存快照，可以包含多个榜单
+	SaveBatch(ctx context.Context, snapshots []*entity.RankingSnapshot) error
+
+	// Exists 判断某个榜单在某个周期是否已生成快照
+	Exists(ctx context.Context, scope string, ownerID uint64, period string, periodStart time.Time, metric string) (bool, error)
+
+	// FindOwnerIDs 查询某类榜单在某个周期已生成快照的所有者
+	FindOwnerIDs(ctx context.Context, scope string, period string, periodStart time.Time, metric string) (map[uint64]bool, error)
+
+	// FindRanks 查询榜单在某个周期中指定用户的快照
+	FindRanks(ctx context.Context, scope string, ownerID uint64, period string, periodStart time.Time, metric string, userIDs []uint64) (map[uint64]*entity.RankingSnapshot, error)
+
+	// FindUserHistory 查询用户在某个榜单中最近limit个周期的快照（按周期升序）
+	FindUserHistory(ctx context.Context, scope string, ownerID uint64, period, metric string, userID uint64, limit int) ([]*entity.RankingSnapshot, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// RecapRepository 回顾仓储接口，回顾生成后只读，因此不提供更新方法
+type RecapRepository interface {
+	FindByID(ctx context.Context, id uint64) (*entity.Recap, error)
+	FindByUserAndPeriod(ctx context.Context, userID uint64, periodType string, periodStart time.Time) (*entity.Recap, error)
+	FindByUserID(ctx context.Context, userID uint64, periodType string, page, size int) ([]*entity.Recap, int64, error)
+	// FindUserIDsWithoutRecap 查找注册时间早于registeredBefore、还没有该周期回顾的正常状态用户
+	FindUserIDsWithoutRecap(ctx context.Context, periodType string, periodStart, registeredBefore time.Time) ([]uint64, error)
+	Save(ctx context.Context, recap *entity.Recap) error
+}
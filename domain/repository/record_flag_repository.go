@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// RecordFlagRepository 记录反作弊标记仓储接口
+type RecordFlagRepository interface {
+	// FindByID 根据ID查找标记，不存在时返回nil
+	FindByID(ctx context.Context, id uint64) (*entity.RecordFlag, error)
+
+	// FindByRecordID 查找记录的标记，不存在时返回nil
+	FindByRecordID(ctx context.Context, recordID uint64) (*entity.RecordFlag, error)
+
+	// FindByUserID 分页查找用户的标记（按时间倒序）
+	FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.RecordFlag, int64, error)
+
+	// FindByStatuses 分页查找指定状态的标记（按时间升序，先进先审）
+	FindByStatuses(ctx context.Context, statuses []string, page, size int) ([]*entity.RecordFlag, int64, error)
+
+	// Save 保存标记
+	Save(ctx context.Context, flag *entity.RecordFlag) error
+
+	// Update 更新标记
+	Update(ctx context.Context, flag *entity.RecordFlag) error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"time"
+)
+
+// RecordReactionRepository 记录表情回应仓储接口
+type RecordReactionRepository interface {
+	// Add 添加回应，已回应过同一表情时返回false
+	Add(ctx context.Context, reaction *entity.RecordReaction) (bool, error)
+
+	// Remove 取消回应，没有该回应时返回false
+	Remove(ctx context.Context, recordID, userID uint64, emoji string) (bool, error)
+
+	// FindByRecordID 查询记录的全部回应（按时间倒序）
+	FindByRecordID(ctx context.Context, recordID uint64) ([]*entity.RecordReaction, error)
+
+	// CountByRecordIDs 统计各记录每种表情的回应数
+	CountByRecordIDs(ctx context.Context, recordIDs []uint64) (map[uint64]map[string]int64, error)
+
+	// FindEmojisByUser 查询用户对各记录回应过的表情
+	FindEmojisByUser(ctx context.Context, recordIDs []uint64, userID uint64) (map[uint64][]string, error)
+
+	// CountByUserSince 统计用户在某个时间之后添加的回应数
+	CountByUserSince(ctx context.Context, userID uint64, since time.Time) (int64, error)
+
+	// DeleteByRecordID 删除记录的全部回应
+	DeleteByRecordID(ctx context.Context, recordID uint64) error
+}
+
+// RecordCommentRepository 记录评论仓储接口
+type RecordCommentRepository interface {
+	FindByID(ctx context.Context, id uint64) (*entity.RecordComment, error)
+	Save(ctx context.Context, comment *entity.RecordComment) error
+
+	// Delete 删除评论，一级评论的回复一并删除
+	Delete(ctx context.Context, id uint64) error
+
+	// FindRootsByRecordID 分页查询记录的一级评论（按时间升序）
+	FindRootsByRecordID(ctx context.Context, recordID uint64, page, size int) ([]*entity.RecordComment, int64, error)
+
+	// FindRepliesByParentIDs 查询一级评论下的全部回复（按时间升序），按一级评论分组
+	FindRepliesByParentIDs(ctx context.Context, parentIDs []uint64) (map[uint64][]*entity.RecordComment, error)
+
+	// CountByRecordIDs 统计各记录的评论数（含回复）
+	CountByRecordIDs(ctx context.Context, recordIDs []uint64) (map[uint64]int64, error)
+
+	// CountByUserSince 统计用户在某个时间之后发表的评论数
+	CountByUserSince(ctx context.Context, userID uint64, since time.Time) (int64, error)
+
+	// DeleteByRecordID 删除记录的全部评论
+	DeleteByRecordID(ctx context.Context, recordID uint64) error
+}
@@ -9,15 +9,61 @@ import (
 // RecordRepository 记录仓储接口
 type RecordRepository interface {
 	FindByID(ctx context.Context, id uint64) (*entity.Record, error)
-	FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Record, int64, error)
-	FindByDateRange(ctx context.Context, userID uint64, start, end time.Time, page, size int) ([]*entity.Record, error)
+
+	// FindByIDs 根据ID列表查找多条记录
+	FindByIDs(ctx context.Context, ids []uint64) ([]*entity.Record, error)
+
+	// FindByUserID 分页查询用户的记录，access 不为空时只返回查看者可见的记录
+	FindByUserID(ctx context.Context, userID uint64, access *entity.RecordAccess, page, size int) ([]*entity.Record, int64, error)
+
+	// FindByDateRange 分页查询用户在日期范围内的记录，access 不为空时只返回查看者可见的记录
+	FindByDateRange(ctx context.Context, userID uint64, access *entity.RecordAccess, start, end time.Time, page, size int) ([]*entity.Record, error)
+
 	Save(ctx context.Context, record *entity.Record) error
 	Update(ctx context.Context, record *entity.Record) error
 	Delete(ctx context.Context, id uint64) error
 	CreateWithTags(ctx context.Context, record *entity.Record, tagIDs []uint64, recordTagRepo RecordTagRepository) error
 	UpdateWithTags(ctx context.Context, record *entity.Record, tagIDs []uint64, recordTagRepo RecordTagRepository) error
-	CountRecordsByDateRange(ctx context.Context, userID uint64, start time.Time, end time.Time) (int64, error)
-	GetGlobalRanking(ctx context.Context, start, end time.Time, limit int) ([]*entity.RankingItem, error)
-	GetFriendRanking(ctx context.Context, userIDs []uint64, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error)
-	GetUsersDailyRecordStats(ctx context.Context, userIDs []uint64, date time.Time) (map[uint64]*entity.DailyRecordStats, error)
+
+	// CountRecordsByDateRange 统计用户在日期范围内的记录数，access 不为空时只统计查看者可见的记录
+	CountRecordsByDateRange(ctx context.Context, userID uint64, access *entity.RecordAccess, start time.Time, end time.Time) (int64, error)
+
+	GetGlobalRanking(ctx context.Context, metric string, start, end time.Time, page, pageSize int) ([]*entity.RankingItem, int, error)
+	GetUserGlobalRank(ctx context.Context, metric string, userID uint64, start, end time.Time) (*entity.RankingPosition, error)
+	// GetFriendRanking 获取指定用户之间的排行榜，只统计可见范围属于 visibilities 的记录
+	GetFriendRanking(ctx context.Context, metric string, userIDs []uint64, visibilities []string, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error)
+
+	// GetRankingValues 计算时间段内所有有记录的用户的指标值，不排名也不按排行榜设置过滤，供批量生成榜单使用
+	GetRankingValues(ctx context.Context, metric string, start, end time.Time) ([]*entity.RankingItem, error)
+
+	// GetUserFriendRank 获取用户在指定用户之间的排行榜中的名次以及前后相邻的用户
+	GetUserFriendRank(ctx context.Context, metric string, userID uint64, userIDs []uint64, visibilities []string, start, end time.Time) (*entity.RankingPosition, error)
+
+	// GetUsersDailyRecordStats 批量获取用户当天的记录统计，只统计 accesses 中对应用户可见的记录
+	GetUsersDailyRecordStats(ctx context.Context, userIDs []uint64, accesses map[uint64]*entity.RecordAccess, date time.Time) (map[uint64]*entity.DailyRecordStats, error)
+
+	// FindAllByDateRange 查询用户在日期范围内的全部记录（不分页，按时间升序）
+	FindAllByDateRange(ctx context.Context, userID uint64, start, end time.Time) ([]*entity.Record, error)
+
+	// FindRecordTimes 查询用户在日期范围内的全部记录时间（按时间升序）
+	FindRecordTimes(ctx context.Context, userID uint64, start, end time.Time) ([]time.Time, error)
+
+	// CountCreatedBetween 统计用户在时间范围内提交的记录数（按创建时间，包含两端）
+	CountCreatedBetween(ctx context.Context, userID uint64, start, end time.Time) (int64, error)
+
+	// GetUserRecordStats 按用户汇总日期范围内的记录，userIDs为空时统计全部用户，被反作弊标记的记录不计入
+	// visibilities 不为空时只统计这些可见范围的记录，为空时统计全部记录
+	GetUserRecordStats(ctx context.Context, userIDs []uint64, visibilities []string, start, end time.Time) ([]*entity.UserRecordStats, error)
+
+	// GetDailyCounts 按天汇总指定用户在时间段内的记录，只返回有记录的日期
+	GetDailyCounts(ctx context.Context, userIDs []uint64, start, end time.Time) ([]*entity.DailyRecordCount, error)
+
+	// GetSquadRanking 分页获取小组排行榜，只包含时间段内有记录的小组
+	GetSquadRanking(ctx context.Context, metric string, start, end time.Time, page, pageSize int) ([]*entity.SquadRankingItem, int, error)
+
+	// FindAllCounted 查询用户计入统计的全部记录（排除被反作弊标记的记录，按时间升序）
+	FindAllCounted(ctx context.Context, userID uint64) ([]*entity.Record, error)
+
+	// FindSameMinuteUserIDs 查询与用户在[start, end)内的记录处于同一分钟的其他用户，只考虑双方不是仅自己可见的记录
+	FindSameMinuteUserIDs(ctx context.Context, userID uint64, otherIDs []uint64, start, end time.Time) ([]uint64, error)
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// RecordVisibilitySettingRepository 记录默认可见范围设置仓储接口
+type RecordVisibilitySettingRepository interface {
+	// FindByUserID 查询用户的默认可见范围设置，没有设置时返回nil
+	FindByUserID(ctx context.Context, userID uint64) (*entity.RecordVisibilitySetting, error)
+
+	// Save 保存用户的默认可见范围设置，已存在时覆盖
+	Save(ctx context.Context, setting *entity.RecordVisibilitySetting) error
+}
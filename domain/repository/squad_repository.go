@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// SquadRepository 小组仓储接口
+type SquadRepository interface {
+	// FindByID 查找小组并统计成员数
+	FindByID(ctx context.Context, id uint64) (*entity.Squad, error)
+
+	// FindByInviteCode 根据邀请码查找小组并统计成员数
+	FindByInviteCode(ctx context.Context, code string) (*entity.Squad, error)
+
+	// FindByMemberID 查询用户加入的小组（按加入时间倒序），并填写用户在各小组中的角色
+	FindByMemberID(ctx context.Context, userID uint64) ([]*entity.Squad, error)
+
+	// CountByMemberID 统计用户加入的小组数
+	CountByMemberID(ctx context.Context, userID uint64) (int64, error)
+
+	// Create 在同一事务中保存小组和创建者的成员信息，邀请码重复时返回错误
+	Create(ctx context.Context, squad *entity.Squad) error
+
+	Update(ctx context.Context, squad *entity.Squad) error
+
+	// UpdateInviteCode 更换邀请码，与其他小组重复时返回错误
+	UpdateInviteCode(ctx context.Context, squadID uint64, code string) error
+
+	// Delete 删除小组及其成员
+	Delete(ctx context.Context, id uint64) error
+
+	// FindMember 查询用户在小组中的成员信息，不是成员时返回nil
+	FindMember(ctx context.Context, squadID, userID uint64) (*entity.SquadMember, error)
+
+	// FindMembers 查询小组的全部成员（按角色和加入时间）
+	FindMembers(ctx context.Context, squadID uint64) ([]*entity.SquadMember, error)
+
+	// FindMemberIDs 查询小组的全部成员ID
+	FindMemberIDs(ctx context.Context, squadID uint64) ([]uint64, error)
+
+	// AddMember 加入小组，锁定小组后检查成员上限，已满时返回false
+	AddMember(ctx context.Context, member *entity.SquadMember) (bool, error)
+
+	// UpdateMemberRole 修改成员角色
+	UpdateMemberRole(ctx context.Context, squadID, userID uint64, role string) error
+
+	// RemoveMember 移除成员，不是成员时返回false
+	RemoveMember(ctx context.Context, squadID, userID uint64) (bool, error)
+}
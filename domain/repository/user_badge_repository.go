@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+)
+
+// UserBadgeRepository 用户徽章仓储接口
+type UserBadgeRepository interface {
+	// Award 保存获得的徽章，已获得过时返回false
+	Award(ctx context.Context, badge *entity.UserBadge) (bool, error)
+
+	// FindByUserID 查询用户获得的全部徽章（按获得时间倒序）
+	FindByUserID(ctx context.Context, userID uint64) ([]*entity.UserBadge, error)
+
+	// FindCodesByUserID 查询用户已获得的徽章代码
+	FindCodesByUserID(ctx context.Context, userID uint64) (map[string]bool, error)
+}
@@ -19,4 +19,7 @@ type UserRepository interface {
 	
 	// SearchUsersExcludeFriends 搜索用户（排除好友）
 	SearchUsersExcludeFriends(ctx context.Context, keyword string, page, size int, excludeUserID uint64, friendIDs []uint64) ([]*entity.User, int64, error)
-}
+
+	// FindAllIDs 获取所有正常状态用户的ID
+	FindAllIDs(ctx context.Context) ([]uint64, error)
+}
\ No newline at end of file
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// LeaderboardEntry 排行榜缓存中单个用户的条目
+type LeaderboardEntry struct {
+	UserID        uint64  `json:"user_id"`
+	MetricValue   float64 `json:"metric_value"`
+	RecordCount   int64   `json:"record_count"`
+	TotalDuration int64   `json:"total_duration"`
+}
+
+// RankedEntry 带名次的排行榜条目，指标相同的用户名次相同（与DENSE_RANK一致）
+type RankedEntry struct {
+	LeaderboardEntry
+	Rank uint64
+}
+
+// LeaderboardStore 排行榜缓存存储接口
+// 每个榜单以key区分，条目按排行榜排序规则保存，分页和名次查询都在存储中完成
+// 过期时间到达后整个榜单失效；榜单不存在或已过期时各读取方法返回ok=false
+type LeaderboardStore interface {
+	// Exists 判断榜单是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// Count 获取榜单人数
+	Count(ctx context.Context, key string) (count int, ok bool, err error)
+
+	// Range 按排名顺序读取从offset开始的最多limit个条目
+	Range(ctx context.Context, key string, offset, limit int) (entries []RankedEntry, ok bool, err error)
+
+	// Position 获取用户在榜单中的位置（从0开始），用户不在榜单中时返回-1
+	Position(ctx context.Context, key string, userID uint64) (position int, ok bool, err error)
+
+	// Get 读取指定用户的条目，不在榜单中的用户不返回
+	Get(ctx context.Context, key string, userIDs []uint64) (entries []LeaderboardEntry, ok bool, err error)
+
+	// Replace 整体替换榜单并设置过期时间
+	Replace(ctx context.Context, key string, entries []LeaderboardEntry, expireAt time.Time) error
+
+	// Upsert 更新单个用户的条目，榜单不存在时忽略
+	Upsert(ctx context.Context, key string, entry LeaderboardEntry) error
+
+	// Remove 从榜单中移除单个用户
+	Remove(ctx context.Context, key string, userID uint64) error
+
+	// Delete 删除整个榜单
+	Delete(ctx context.Context, key string) error
+
+	// LockBuild 获取重建榜单的锁，多个实例之间同一时间只有一个能重建同一个榜单
+	// 获取成功时返回用于释放锁的token，锁在ttl后自动失效
+	LockBuild(ctx context.Context, key string, ttl time.Duration) (token string, ok bool, err error)
+
+	// MarkDirty 榜单正在重建时记下需要重新计算的用户，没有在重建时返回false
+	MarkDirty(ctx context.Context, key string, userID uint64) (marked bool, err error)
+
+	// UnlockBuild 释放重建锁，并取出重建期间被标记的用户；锁已失效或被其他实例持有时不返回用户
+	UnlockBuild(ctx context.Context, key, token string) (dirty []uint64, err error)
+}
+
+// entryLess 排行榜排序规则：指标值降序、记录次数降序、用户ID升序，与数据库排序保持一致
+func entryLess(a, b LeaderboardEntry) bool {
+	if a.MetricValue != b.MetricValue {
+		return a.MetricValue > b.MetricValue
+	}
+	if a.RecordCount != b.RecordCount {
+		return a.RecordCount > b.RecordCount
+	}
+	return a.UserID < b.UserID
+}
+
+// SortEntries 按排行榜排序规则排序
+func SortEntries(entries []LeaderboardEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entryLess(entries[i], entries[j])
+	})
+}
+
+// RankEntries 为已排序的条目计算名次，firstRank为第一个条目的名次
+func RankEntries(entries []LeaderboardEntry, firstRank uint64) []RankedEntry {
+	ranked := make([]RankedEntry, len(entries))
+	rank := firstRank
+	for i, entry := range entries {
+		if i > 0 && entry.MetricValue != entries[i-1].MetricValue {
+			rank++
+		}
+		ranked[i] = RankedEntry{LeaderboardEntry: entry, Rank: rank}
+	}
+	return ranked
+}
@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryBoard 进程内榜单，条目始终保持有序
+type memoryBoard struct {
+	entries  []LeaderboardEntry
+	expireAt time.Time
+}
+
+// indexOf 查找用户在榜单中的位置，不存在时返回-1
+func (b *memoryBoard) indexOf(userID uint64) int {
+	for i, entry := range b.entries {
+		if entry.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// rankAt 计算指定位置的名次，即排在前面的不同指标值的个数加一
+func (b *memoryBoard) rankAt(pos int) uint64 {
+	rank := uint64(1)
+	for i := 1; i <= pos; i++ {
+		if b.entries[i].MetricValue != b.entries[i-1].MetricValue {
+			rank++
+		}
+	}
+	return rank
+}
+
+// memoryBuildLock 进程内的重建锁以及重建期间被标记的用户
+type memoryBuildLock struct {
+	token    string
+	expireAt time.Time
+	dirty    map[uint64]bool
+}
+
+// memoryStore 进程内排行榜缓存，适用于单实例部署
+type memoryStore struct {
+	mu     sync.RWMutex
+	boards map[string]*memoryBoard
+	locks  map[string]*memoryBuildLock
+	// lockSeq 生成重建锁的token
+	lockSeq uint64
+}
+
+// NewMemoryStore 创建进程内排行榜缓存
+func NewMemoryStore() LeaderboardStore {
+	return &memoryStore{
+		boards: make(map[string]*memoryBoard),
+		locks:  make(map[string]*memoryBuildLock),
+	}
+}
+
+// buildLock 获取未过期的重建锁，调用方需持有锁
+func (s *memoryStore) buildLock(key string) (*memoryBuildLock, bool) {
+	lock, ok := s.locks[key]
+	if !ok || !time.Now().Before(lock.expireAt) {
+		return nil, false
+	}
+	return lock, true
+}
+
+// board 获取未过期的榜单，调用方需持有锁
+func (s *memoryStore) board(key string) (*memoryBoard, bool) {
+	board, ok := s.boards[key]
+	if !ok || !time.Now().Before(board.expireAt) {
+		return nil, false
+	}
+	return board, true
+}
+
+// Exists 判断榜单是否存在
+func (s *memoryStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.board(key)
+	return ok, nil
+}
+
+// Count 获取榜单人数
+func (s *memoryStore) Count(ctx context.Context, key string) (int, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	board, ok := s.board(key)
+	if !ok {
+		return 0, false, nil
+	}
+	return len(board.entries), true, nil
+}
+
+// Range 按排名顺序读取从offset开始的最多limit个条目
+func (s *memoryStore) Range(ctx context.Context, key string, offset, limit int) ([]RankedEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	board, ok := s.board(key)
+	if !ok {
+		return nil, false, nil
+	}
+	if offset >= len(board.entries) || limit <= 0 {
+		return []RankedEntry{}, true, nil
+	}
+	end := offset + limit
+	if end > len(board.entries) {
+		end = len(board.entries)
+	}
+	return RankEntries(board.entries[offset:end], board.rankAt(offset)), true, nil
+}
+
+// Position 获取用户在榜单中的位置
+func (s *memoryStore) Position(ctx context.Context, key string, userID uint64) (int, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	board, ok := s.board(key)
+	if !ok {
+		return -1, false, nil
+	}
+	return board.indexOf(userID), true, nil
+}
+
+// Get 读取指定用户的条目
+func (s *memoryStore) Get(ctx context.Context, key string, userIDs []uint64) ([]LeaderboardEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	board, ok := s.board(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	wanted := make(map[uint64]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	entries := make([]LeaderboardEntry, 0, len(wanted))
+	for _, entry := range board.entries {
+		if wanted[entry.UserID] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, true, nil
+}
+
+// Replace 整体替换榜单，同时清理已过期的榜单
+func (s *memoryStore) Replace(ctx context.Context, key string, entries []LeaderboardEntry, expireAt time.Time) error {
+	sorted := append([]LeaderboardEntry(nil), entries...)
+	SortEntries(sorted)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, board := range s.boards {
+		if !now.Before(board.expireAt) {
+			delete(s.boards, k)
+		}
+	}
+	s.boards[key] = &memoryBoard{entries: sorted, expireAt: expireAt}
+	return nil
+}
+
+// Upsert 更新单个用户的条目并移动到新的位置
+func (s *memoryStore) Upsert(ctx context.Context, key string, entry LeaderboardEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	board, ok := s.board(key)
+	if !ok {
+		return nil
+	}
+
+	if i := board.indexOf(entry.UserID); i >= 0 {
+		board.entries = append(board.entries[:i], board.entries[i+1:]...)
+	}
+	pos := sort.Search(len(board.entries), func(i int) bool {
+		return entryLess(entry, board.entries[i])
+	})
+	board.entries = append(board.entries, LeaderboardEntry{})
+	copy(board.entries[pos+1:], board.entries[pos:])
+	board.entries[pos] = entry
+	return nil
+}
+
+// Remove 从榜单中移除单个用户
+func (s *memoryStore) Remove(ctx context.Context, key string, userID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	board, ok := s.board(key)
+	if !ok {
+		return nil
+	}
+	if i := board.indexOf(userID); i >= 0 {
+		board.entries = append(board.entries[:i], board.entries[i+1:]...)
+	}
+	return nil
+}
+
+// Delete 删除整个榜单
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.boards, key)
+	return nil
+}
+
+// LockBuild 获取重建榜单的锁
+func (s *memoryStore) LockBuild(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.buildLock(key); ok {
+		return "", false, nil
+	}
+	s.lockSeq++
+	token := strconv.FormatUint(s.lockSeq, 10)
+	s.locks[key] = &memoryBuildLock{token: token, expireAt: time.Now().Add(ttl), dirty: make(map[uint64]bool)}
+	return token, true, nil
+}
+
+// MarkDirty 榜单正在重建时记下需要重新计算的用户
+func (s *memoryStore) MarkDirty(ctx context.Context, key string, userID uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.buildLock(key)
+	if !ok {
+		return false, nil
+	}
+	lock.dirty[userID] = true
+	return true, nil
+}
+
+// UnlockBuild 释放重建锁并取出重建期间被标记的用户
+func (s *memoryStore) UnlockBuild(ctx context.Context, key, token string) ([]uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.buildLock(key)
+	if !ok || lock.token != token {
+		return nil, nil
+	}
+	delete(s.locks, key)
+
+	dirty := make([]uint64, 0, len(lock.dirty))
+	for userID := range lock.dirty {
+		dirty = append(dirty, userID)
+	}
+	return dirty, nil
+}
@@ -0,0 +1,402 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPlaceholderField 用户Hash中的占位字段，保证空榜单也能被缓存，同时作为榜单是否存在的标记
+const redisPlaceholderField = "_"
+
+// redisWriteBatch 重建榜单时每条命令写入的条目数
+const redisWriteBatch = 1000
+
+// redisRemoveLua 从榜单中移除用户的旧条目，该指标值不再有用户时同时从指标值集合中移除
+const redisRemoveLua = `
+local function remove(rankKey, userKey, scoreKey, userID)
+	local old = redis.call('HGET', userKey, userID)
+	if not old then
+		return
+	end
+	redis.call('ZREM', rankKey, old)
+	redis.call('HDEL', userKey, userID)
+	local value = string.match(old, '[^:]*$')
+	if redis.call('ZCOUNT', rankKey, value, value) == 0 then
+		redis.call('ZREM', scoreKey, value)
+	end
+end
+`
+
+// redisUpsertScript 仅在榜单存在时更新条目，新建的有序集合沿用榜单的过期时间
+var redisUpsertScript = redis.NewScript(redisRemoveLua + `
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return 0
+end
+remove(KEYS[1], KEYS[2], KEYS[3], ARGV[1])
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[2])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[3])
+local ttl = redis.call('PTTL', KEYS[2])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[3], ttl)
+end
+return 1
+`)
+
+// redisRemoveScript 从榜单中移除用户
+var redisRemoveScript = redis.NewScript(redisRemoveLua + `
+remove(KEYS[1], KEYS[2], KEYS[3], ARGV[1])
+return 1
+`)
+
+// redisRangeScript 读取一页条目以及第一个条目的名次（排在前面的不同指标值个数加一），榜单不存在时返回nil
+var redisRangeScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return false
+end
+local members = redis.call('ZREVRANGE', KEYS[1], ARGV[1], ARGV[2])
+if #members == 0 then
+	return {0, members}
+end
+local value = string.match(members[1], '[^:]*$')
+return {redis.call('ZCOUNT', KEYS[3], '(' .. value, '+inf') + 1, members}
+`)
+
+// redisPositionScript 获取用户在榜单中的位置，用户不在榜单中时返回-1，榜单不存在时返回nil
+var redisPositionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return false
+end
+local member = redis.call('HGET', KEYS[2], ARGV[1])
+if not member then
+	return -1
+end
+return redis.call('ZREVRANK', KEYS[1], member)
+`)
+
+// redisCountScript 获取榜单人数，榜单不存在时返回nil
+var redisCountScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return false
+end
+return redis.call('ZCARD', KEYS[1])
+`)
+
+// redisMarkDirtyScript 重建锁存在时记下用户，标记集合与锁同时过期
+var redisMarkDirtyScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl <= 0 then
+	return 0
+end
+redis.call('SADD', KEYS[2], ARGV[1])
+redis.call('PEXPIRE', KEYS[2], ttl)
+return 1
+`)
+
+// redisUnlockBuildScript 锁仍由自己持有时释放锁并取出被标记的用户
+var redisUnlockBuildScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return {}
+end
+local dirty = redis.call('SMEMBERS', KEYS[2])
+redis.call('DEL', KEYS[1], KEYS[2])
+return dirty
+`)
+
+// RedisConfig Redis连接配置
+type RedisConfig struct {
+	Addr      string
+	Password  string
+	DB        int
+	KeyPrefix string
+	Timeout   time.Duration
+	PoolSize  int // 连接池大小，为0时使用go-redis的默认值
+}
+
+// redisStore 基于Redis有序集合的排行榜缓存，适用于多实例部署
+// 每个榜单由三个键组成，使用相同的hash tag保证在集群中位于同一个slot：
+//   - rank: 有序集合，分数为指标值，成员为编码后的条目，指标值相同时按成员倒序即记录次数降序、用户ID升序
+//   - user: Hash，用户ID到rank中成员的映射，用于更新和查找单个用户
+//   - score: 有序集合，保存榜单中出现过的不同指标值，用于计算与DENSE_RANK一致的名次
+type redisStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisStore 创建基于Redis的排行榜缓存，使用连接池访问Redis
+func NewRedisStore(cfg RedisConfig) LeaderboardStore {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	return &redisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:         cfg.Addr,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			DialTimeout:  cfg.Timeout,
+			ReadTimeout:  cfg.Timeout,
+			WriteTimeout: cfg.Timeout,
+			PoolSize:     cfg.PoolSize,
+		}),
+		keyPrefix: cfg.KeyPrefix,
+	}
+}
+
+// keys 获取榜单的三个键
+func (s *redisStore) keys(key string) []string {
+	base := s.keyPrefix + "{" + key + "}"
+	return []string{base + ":rank", base + ":user", base + ":score"}
+}
+
+// buildKeys 获取重建锁和重建期间标记集合的键，与榜单位于同一个slot
+func (s *redisStore) buildKeys(key string) []string {
+	base := s.keyPrefix + "{" + key + "}"
+	return []string{base + ":lock", base + ":dirty"}
+}
+
+// Exists 判断榜单是否存在
+func (s *redisStore) Exists(ctx context.Context, key string) (bool, error) {
+	count, err := s.client.Exists(ctx, s.keys(key)[1]).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Count 获取榜单人数
+func (s *redisStore) Count(ctx context.Context, key string) (int, bool, error) {
+	count, err := redisCountScript.Run(ctx, s.client, s.keys(key)).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+// Range 按排名顺序读取从offset开始的最多limit个条目
+func (s *redisStore) Range(ctx context.Context, key string, offset, limit int) ([]RankedEntry, bool, error) {
+	if limit <= 0 {
+		return []RankedEntry{}, true, nil
+	}
+
+	reply, err := redisRangeScript.Run(ctx, s.client, s.keys(key), offset, offset+limit-1).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(reply) != 2 {
+		return nil, false, fmt.Errorf("读取排行榜缓存失败: 无效的回复 %v", reply)
+	}
+
+	firstRank, _ := reply[0].(int64)
+	members, _ := reply[1].([]interface{})
+	entries := make([]LeaderboardEntry, 0, len(members))
+	for _, member := range members {
+		entry, err := decodeRedisMember(fmt.Sprint(member))
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+	return RankEntries(entries, uint64(firstRank)), true, nil
+}
+
+// Position 获取用户在榜单中的位置
+func (s *redisStore) Position(ctx context.Context, key string, userID uint64) (int, bool, error) {
+	position, err := redisPositionScript.Run(ctx, s.client, s.keys(key), userID).Int()
+	if errors.Is(err, redis.Nil) {
+		return -1, false, nil
+	}
+	if err != nil {
+		return -1, false, err
+	}
+	return position, true, nil
+}
+
+// Get 读取指定用户的条目，占位字段一并读取用于判断榜单是否存在
+func (s *redisStore) Get(ctx context.Context, key string, userIDs []uint64) ([]LeaderboardEntry, bool, error) {
+	fields := make([]string, 0, len(userIDs)+1)
+	fields = append(fields, redisPlaceholderField)
+	seen := make(map[uint64]bool, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			fields = append(fields, strconv.FormatUint(id, 10))
+		}
+	}
+
+	values, err := s.client.HMGet(ctx, s.keys(key)[1], fields...).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if values[0] == nil {
+		return nil, false, nil
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(fields)-1)
+	for _, value := range values[1:] {
+		member, ok := value.(string)
+		if !ok {
+			continue
+		}
+		entry, err := decodeRedisMember(member)
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, true, nil
+}
+
+// Replace 在事务中整体替换榜单并设置过期时间
+func (s *redisStore) Replace(ctx context.Context, key string, entries []LeaderboardEntry, expireAt time.Time) error {
+	keys := s.keys(key)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		pipe.HSet(ctx, keys[1], redisPlaceholderField, "")
+
+		seen := make(map[string]bool)
+		for start := 0; start < len(entries); start += redisWriteBatch {
+			end := start + redisWriteBatch
+			if end > len(entries) {
+				end = len(entries)
+			}
+
+			members := make([]redis.Z, 0, end-start)
+			scores := make([]redis.Z, 0, end-start)
+			users := make([]interface{}, 0, (end-start)*2)
+			for _, entry := range entries[start:end] {
+				member := encodeRedisMember(entry)
+				value := formatRedisScore(entry.MetricValue)
+				members = append(members, redis.Z{Score: entry.MetricValue, Member: member})
+				users = append(users, strconv.FormatUint(entry.UserID, 10), member)
+				if !seen[value] {
+					seen[value] = true
+					scores = append(scores, redis.Z{Score: entry.MetricValue, Member: value})
+				}
+			}
+			pipe.ZAdd(ctx, keys[0], members...)
+			pipe.HSet(ctx, keys[1], users...)
+			if len(scores) > 0 {
+				pipe.ZAdd(ctx, keys[2], scores...)
+			}
+		}
+
+		for _, k := range keys {
+			pipe.ExpireAt(ctx, k, expireAt)
+		}
+		return nil
+	})
+	return err
+}
+
+// Upsert 更新单个用户的条目，榜单不存在时忽略
+func (s *redisStore) Upsert(ctx context.Context, key string, entry LeaderboardEntry) error {
+	return redisUpsertScript.Run(ctx, s.client, s.keys(key),
+		entry.UserID, encodeRedisMember(entry), formatRedisScore(entry.MetricValue)).Err()
+}
+
+// Remove 从榜单中移除单个用户
+func (s *redisStore) Remove(ctx context.Context, key string, userID uint64) error {
+	return redisRemoveScript.Run(ctx, s.client, s.keys(key), userID).Err()
+}
+
+// Delete 删除整个榜单
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.keys(key)...).Err()
+}
+
+// LockBuild 获取重建榜单的锁，token为随机值，释放时校验避免误删其他实例的锁
+func (s *redisStore) LockBuild(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	token := hex.EncodeToString(buf)
+
+	keys := s.buildKeys(key)
+	ok, err := s.client.SetNX(ctx, keys[0], token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	// 清理上一次重建遗留的标记
+	if err := s.client.Del(ctx, keys[1]).Err(); err != nil {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// MarkDirty 榜单正在重建时记下需要重新计算的用户
+func (s *redisStore) MarkDirty(ctx context.Context, key string, userID uint64) (bool, error) {
+	marked, err := redisMarkDirtyScript.Run(ctx, s.client, s.buildKeys(key), userID).Int()
+	if err != nil {
+		return false, err
+	}
+	return marked == 1, nil
+}
+
+// UnlockBuild 释放重建锁并取出重建期间被标记的用户
+func (s *redisStore) UnlockBuild(ctx context.Context, key, token string) ([]uint64, error) {
+	members, err := redisUnlockBuildScript.Run(ctx, s.client, s.buildKeys(key), token).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	dirty := make([]uint64, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析排行榜缓存失败: 无效的用户ID %q", member)
+		}
+		dirty = append(dirty, userID)
+	}
+	return dirty, nil
+}
+
+// encodeRedisMember 将条目编码为有序集合的成员：记录次数:反转的用户ID:总时长:指标值
+// 前两段定长补零，分数相同时按成员倒序排列即为记录次数降序、用户ID升序；指标值放在最后便于脚本读取
+func encodeRedisMember(entry LeaderboardEntry) string {
+	return fmt.Sprintf("%020d:%020d:%d:%s", entry.RecordCount, math.MaxUint64-entry.UserID, entry.TotalDuration, formatRedisScore(entry.MetricValue))
+}
+
+// decodeRedisMember 解析有序集合的成员
+func decodeRedisMember(member string) (LeaderboardEntry, error) {
+	parts := strings.Split(member, ":")
+	if len(parts) != 4 {
+		return LeaderboardEntry{}, fmt.Errorf("解析排行榜缓存失败: 无效的条目 %q", member)
+	}
+
+	recordCount, err1 := strconv.ParseInt(parts[0], 10, 64)
+	reversedID, err2 := strconv.ParseUint(parts[1], 10, 64)
+	totalDuration, err3 := strconv.ParseInt(parts[2], 10, 64)
+	metricValue, err4 := strconv.ParseFloat(parts[3], 64)
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return LeaderboardEntry{}, fmt.Errorf("解析排行榜缓存失败: %w", err)
+	}
+
+	return LeaderboardEntry{
+		UserID:        math.MaxUint64 - reversedID,
+		MetricValue:   metricValue,
+		RecordCount:   recordCount,
+		TotalDuration: totalDuration,
+	}, nil
+}
+
+// formatRedisScore 格式化指标值，使用最短的可还原表示，保证同一个值在各处的字符串一致
+func formatRedisScore(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,6 +15,12 @@ type Config struct {
 	Wechat WechatConfig
 	Aliyun AliyunConfig // 新增阿里云配置
 	JWT    JWTConfig    // 新增JWT配置
+	Cache  CacheConfig  // 排行榜缓存配置
+
+	Moderation ModerationConfig // 内容审核配置
+	League     LeagueConfig     // 赛季联赛配置
+	Friend     FriendConfig     // 好友申请配置
+	Squad      SquadConfig      // 小组配置
 }
 
 // ServerConfig 服务器配置
@@ -41,8 +48,10 @@ func (c *DBConfig) GetDSN() string {
 
 // WechatConfig 微信配置
 type WechatConfig struct {
-	AppID     string
-	AppSecret string
+	AppID      string
+	AppSecret  string
+	APIBaseURL string // 微信接口地址，测试时可指向本地模拟服务
+	InvitePage string // 邀请小程序码打开的页面
 }
 
 // AliyunConfig 阿里云配置
@@ -60,6 +69,40 @@ type JWTConfig struct {
 	ExpirationHours int
 }
 
+// CacheConfig 排行榜缓存配置
+type CacheConfig struct {
+	Driver         string // memory-进程内缓存（默认），redis-Redis缓存（多实例部署时使用）
+	RedisAddr      string
+	RedisPassword  string
+	RedisDB        int
+	RedisKeyPrefix string
+	RedisPoolSize  int // Redis连接池大小，为0时使用默认值
+}
+
+// ModerationConfig 内容审核配置
+type ModerationConfig struct {
+	ModeratorIDs []uint64 // 审核员用户ID
+}
+
+// LeagueConfig 赛季联赛配置，修改后从下一个赛季开始生效
+type LeagueConfig struct {
+	Period string // 赛季周期: weekly-周（默认）, monthly-月
+	Metric string // 排名指标，取值与排行榜指标相同
+}
+
+// FriendConfig 好友申请配置
+type FriendConfig struct {
+	MaxPendingRequests int // 每个用户最多同时存在的待确认申请数，0表示不限制
+	RequestExpiryDays  int // 好友申请的有效天数，超过后自动删除，0表示永不过期
+}
+
+// SquadConfig 小组配置
+type SquadConfig struct {
+	MaxMembers int    // 每个小组的成员上限，创建者可以在此范围内调低
+	MaxJoined  int    // 每个用户最多加入的小组数（含自己创建的）
+	InvitePage string // 小组邀请链接打开的小程序页面
+}
+
 // LoadConfig 加载配置
 func LoadConfig() *Config {
 	return &Config{
@@ -81,8 +124,10 @@ func LoadConfig() *Config {
 			MaxLifetime:  time.Duration(getEnvAsInt("DB_MAX_LIFETIME", 3600)) * time.Second,
 		},
 		Wechat: WechatConfig{
-			AppID:     getEnv("WECHAT_APPID", ""),
-			AppSecret: getEnv("WECHAT_SECRET", ""),
+			AppID:      getEnv("WECHAT_APPID", ""),
+			AppSecret:  getEnv("WECHAT_SECRET", ""),
+			APIBaseURL: getEnv("WECHAT_API_BASE_URL", "https://api.weixin.qq.com"),
+			InvitePage: getEnv("WECHAT_INVITE_PAGE", "pages/index/index"),
 		},
 		Aliyun: AliyunConfig{
 			Endpoint:        getEnv("ALIYUN_ENDPOINT", ""),
@@ -91,6 +136,30 @@ func LoadConfig() *Config {
 			BucketName:      getEnv("ALIYUN_BUCKET_NAME", ""),
 			URLPrefix:       getEnv("ALIYUN_URL_PREFIX", ""),
 		},
+		Cache: CacheConfig{
+			Driver:         getEnv("CACHE_DRIVER", "memory"),
+			RedisAddr:      getEnv("REDIS_ADDR", "127.0.0.1:6379"),
+			RedisPassword:  getEnv("REDIS_PASSWORD", ""),
+			RedisDB:        getEnvAsInt("REDIS_DB", 0),
+			RedisKeyPrefix: getEnv("REDIS_KEY_PREFIX", "record:"),
+			RedisPoolSize:  getEnvAsInt("REDIS_POOL_SIZE", 0),
+		},
+		Moderation: ModerationConfig{
+			ModeratorIDs: getEnvAsUint64List("MODERATOR_USER_IDS"),
+		},
+		League: LeagueConfig{
+			Period: getEnv("LEAGUE_PERIOD", "weekly"),
+			Metric: getEnv("LEAGUE_METRIC", "count"),
+		},
+		Friend: FriendConfig{
+			MaxPendingRequests: getEnvAsInt("FRIEND_MAX_PENDING_REQUESTS", 50),
+			RequestExpiryDays:  getEnvAsInt("FRIEND_REQUEST_EXPIRY_DAYS", 30),
+		},
+		Squad: SquadConfig{
+			MaxMembers: getEnvAsInt("SQUAD_MAX_MEMBERS", 50),
+			MaxJoined:  getEnvAsInt("SQUAD_MAX_JOINED", 10),
+			InvitePage: getEnv("SQUAD_INVITE_PAGE", "pages/squad/join"),
+		},
 	}
 }
 
@@ -115,3 +184,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return value
 }
+
+// getEnvAsUint64List 获取逗号分隔的整数列表环境变量，无法解析的项会被忽略
+func getEnvAsUint64List(key string) []uint64 {
+	var values []uint64
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		value, err := strconv.ParseUint(strings.TrimSpace(item), 10, 64)
+		if err == nil {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,49 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+	"record-project/domain/event"
+	"sync"
+)
+
+// Bus 进程内同步事件总线
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]event.Handler
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string][]event.Handler),
+	}
+}
+
+// Subscribe 订阅事件
+func (b *Bus) Subscribe(name string, handler event.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish 同步发布事件，单个处理函数出错不影响其他处理函数和发布方
+func (b *Bus) Publish(ctx context.Context, e event.Event) {
+	b.mu.RLock()
+	handlers := append([]event.Handler(nil), b.handlers[e.EventName()]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.dispatch(ctx, e, handler)
+	}
+}
+
+// dispatch 执行单个处理函数并捕获panic
+func (b *Bus) dispatch(ctx context.Context, e event.Event, handler event.Handler) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("处理事件[%s]发生panic: %v", e.EventName(), r)
+		}
+	}()
+	handler(ctx, e)
+}
@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"record-project/domain/entity"
+)
+
+// Notifier 通知推送接口
+// 通知已先保存到接收者的收件箱，推送只负责即时触达（如微信订阅消息），失败不影响收件箱
+type Notifier interface {
+	Notify(ctx context.Context, notification *entity.Notification) error
+}
+
+// logNotifier 只记录日志的推送实现，没有接入推送渠道时使用
+type logNotifier struct{}
+
+// NewLogNotifier 创建只记录日志的推送实现
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+// Notify 记录通知日志
+func (n *logNotifier) Notify(ctx context.Context, notification *entity.Notification) error {
+	log.Printf("推送通知%d给用户%d(%s): %s", notification.ID, notification.UserID, notification.Type, notification.Content)
+	return nil
+}
@@ -56,10 +56,52 @@ func NewDatabase(cfg *config.Config) (*Database, error) {
 
 // InitData 初始化数据
 func (d *Database) InitData() error {
+	// 自动创建新增的数据表
+	if err := d.migrate(); err != nil {
+		return err
+	}
+
 	// 初始化屎的类型数据
 	return d.initPoopTypes()
 }
 
+// migrate 自动迁移新增功能的数据表
+func (d *Database) migrate() error {
+	if err := d.DB.AutoMigrate(
+		&model.Recap{},
+		&model.Goal{},
+		&model.GoalCompletion{},
+		&model.RankingSnapshot{},
+		&model.RankingSetting{},
+		&model.RecordFlag{},
+		&model.League{},
+		&model.LeagueDivision{},
+		&model.LeagueMembership{},
+		&model.Block{},
+		&model.InviteCode{},
+		&model.InviteRedemption{},
+		&model.Friend{},
+		&model.Circle{},
+		&model.CircleMember{},
+		&model.Record{},
+		&model.RecordVisibilitySetting{},
+		&model.Activity{},
+		&model.RecordReaction{},
+		&model.RecordComment{},
+		&model.Notification{},
+		&model.Nudge{},
+		&model.NudgeSetting{},
+		&model.Squad{},
+		&model.SquadMember{},
+		&model.Challenge{},
+		&model.ChallengeParticipant{},
+		&model.UserBadge{},
+	); err != nil {
+		return fmt.Errorf("迁移数据表失败: %w", err)
+	}
+	return nil
+}
+
 // initPoopTypes 初始化屎的类型数据
 func (d *Database) initPoopTypes() error {
 	// 检查是否已有数据
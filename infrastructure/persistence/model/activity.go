@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"record-project/domain/entity"
+	"time"
+)
+
+// Activity 好友动态数据库模型
+type Activity struct {
+	ID         uint64    `gorm:"primaryKey;column:id"`
+	ActorID    uint64    `gorm:"not null;uniqueIndex:idx_activity_actor_ref;index:idx_activity_actor_occurred,priority:1;column:actor_id;comment:产生动态的用户ID"`
+	Type       string    `gorm:"type:varchar(20);not null;column:type;comment:动态类型: record, streak_milestone, goal_met, rank_change, badge"`
+	RefKey     string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_activity_actor_ref;column:ref_key;comment:去重键"`
+	RecordID   uint64    `gorm:"index;column:record_id;comment:关联的记录ID"`
+	Visibility string    `gorm:"type:varchar(10);not null;default:friends;column:visibility;comment:可见范围: private, friends, circle, public"`
+	CircleID   uint64    `gorm:"column:circle_id;comment:可见范围为分组时可见的好友分组"`
+	Data       string    `gorm:"type:text;column:data;comment:附加信息(JSON)"`
+	OccurredAt time.Time `gorm:"not null;index:idx_activity_actor_occurred,priority:2;column:occurred_at;comment:发生时间"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (Activity) TableName() string {
+	return "activities"
+}
+
+// ToEntity 转换为领域实体
+func (a *Activity) ToEntity() *entity.Activity {
+	activity := &entity.Activity{
+		ID:         a.ID,
+		ActorID:    a.ActorID,
+		Type:       a.Type,
+		RefKey:     a.RefKey,
+		RecordID:   a.RecordID,
+		Visibility: a.Visibility,
+		CircleID:   a.CircleID,
+		OccurredAt: a.OccurredAt,
+		CreatedAt:  a.CreatedAt,
+	}
+
+	// JSON字段解析失败时保留空值，不影响动态展示
+	if a.Data != "" {
+		var data entity.ActivityData
+		if err := json.Unmarshal([]byte(a.Data), &data); err == nil {
+			activity.Data = &data
+		}
+	}
+
+	return activity
+}
+
+// FromEntity 从领域实体转换
+func (a *Activity) FromEntity(activity *entity.Activity) error {
+	a.Data = ""
+	if activity.Data != nil {
+		data, err := json.Marshal(activity.Data)
+		if err != nil {
+			return err
+		}
+		a.Data = string(data)
+	}
+
+	a.ID = activity.ID
+	a.ActorID = activity.ActorID
+	a.Type = activity.Type
+	a.RefKey = activity.RefKey
+	a.RecordID = activity.RecordID
+	a.Visibility = activity.Visibility
+	a.CircleID = activity.CircleID
+	a.OccurredAt = activity.OccurredAt
+	a.CreatedAt = activity.CreatedAt
+	return nil
+}
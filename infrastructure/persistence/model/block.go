@@ -0,0 +1,37 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// Block 屏蔽关系数据库模型
+type Block struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:idx_user_blocked;column:user_id;comment:屏蔽者ID"`
+	BlockedID uint64    `gorm:"not null;uniqueIndex:idx_user_blocked;index;column:blocked_id;comment:被屏蔽者ID"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (Block) TableName() string {
+	return "user_blocks"
+}
+
+// ToEntity 转换为领域实体
+func (b *Block) ToEntity() *entity.Block {
+	return &entity.Block{
+		ID:        b.ID,
+		UserID:    b.UserID,
+		BlockedID: b.BlockedID,
+		CreatedAt: b.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (b *Block) FromEntity(block *entity.Block) {
+	b.ID = block.ID
+	b.UserID = block.UserID
+	b.BlockedID = block.BlockedID
+	b.CreatedAt = block.CreatedAt
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// Challenge 好友挑战数据库模型
+type Challenge struct {
+	ID        uint64     `gorm:"primaryKey;column:id"`
+	CreatorID uint64     `gorm:"not null;index;column:creator_id;comment:创建者ID"`
+	Title     string     `gorm:"type:varchar(50);not null;column:title;comment:挑战标题"`
+	Goal      string     `gorm:"type:varchar(20);not null;column:goal;comment:挑战目标: most_records-记录最多, longest_streak-连续最久, most_healthy-3-4型最多"`
+	StartAt   time.Time  `gorm:"not null;column:start_at;comment:开始时间"`
+	EndAt     time.Time  `gorm:"not null;index:idx_challenge_status_end,priority:2;column:end_at;comment:结束时间(不含)"`
+	Status    string     `gorm:"type:varchar(10);not null;index:idx_challenge_status_end,priority:1;column:status;comment:状态: active-未结算, settled-已结算"`
+	SettledAt *time.Time `gorm:"column:settled_at;comment:结算时间"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (Challenge) TableName() string {
+	return "challenges"
+}
+
+// ToEntity 转换为领域实体
+func (c *Challenge) ToEntity() *entity.Challenge {
+	return &entity.Challenge{
+		ID:        c.ID,
+		CreatorID: c.CreatorID,
+		Title:     c.Title,
+		Goal:      c.Goal,
+		StartAt:   c.StartAt,
+		EndAt:     c.EndAt,
+		Status:    c.Status,
+		SettledAt: c.SettledAt,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (c *Challenge) FromEntity(challenge *entity.Challenge) {
+	c.ID = challenge.ID
+	c.CreatorID = challenge.CreatorID
+	c.Title = challenge.Title
+	c.Goal = challenge.Goal
+	c.StartAt = challenge.StartAt
+	c.EndAt = challenge.EndAt
+	c.Status = challenge.Status
+	c.SettledAt = challenge.SettledAt
+	c.CreatedAt = challenge.CreatedAt
+	c.UpdatedAt = challenge.UpdatedAt
+}
+
+// ChallengeParticipant 挑战参与者数据库模型
+type ChallengeParticipant struct {
+	ID          uint64     `gorm:"primaryKey;column:id"`
+	ChallengeID uint64     `gorm:"not null;uniqueIndex:idx_challenge_participant;column:challenge_id;comment:挑战ID"`
+	UserID      uint64     `gorm:"not null;uniqueIndex:idx_challenge_participant;index;column:user_id;comment:用户ID"`
+	Status      string     `gorm:"type:varchar(10);not null;column:status;comment:状态: invited-已邀请, joined-已参加, declined-已拒绝"`
+	FinalRank   uint64     `gorm:"not null;default:0;column:final_rank;comment:最终名次，结算前为0"`
+	Score       float64    `gorm:"not null;default:0;column:score;comment:最终成绩"`
+	Winner      bool       `gorm:"not null;default:false;column:winner;comment:是否获胜"`
+	RespondedAt *time.Time `gorm:"column:responded_at;comment:回应邀请的时间"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (ChallengeParticipant) TableName() string {
+	return "challenge_participants"
+}
+
+// ToEntity 转换为领域实体
+func (p *ChallengeParticipant) ToEntity() *entity.ChallengeParticipant {
+	return &entity.ChallengeParticipant{
+		ID:          p.ID,
+		ChallengeID: p.ChallengeID,
+		UserID:      p.UserID,
+		Status:      p.Status,
+		FinalRank:   p.FinalRank,
+		Score:       p.Score,
+		Winner:      p.Winner,
+		RespondedAt: p.RespondedAt,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (p *ChallengeParticipant) FromEntity(participant *entity.ChallengeParticipant) {
+	p.ID = participant.ID
+	p.ChallengeID = participant.ChallengeID
+	p.UserID = participant.UserID
+	p.Status = participant.Status
+	p.FinalRank = participant.FinalRank
+	p.Score = participant.Score
+	p.Winner = participant.Winner
+	p.RespondedAt = participant.RespondedAt
+	p.CreatedAt = participant.CreatedAt
+}
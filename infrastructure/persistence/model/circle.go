@@ -0,0 +1,74 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// Circle 好友分组数据库模型
+type Circle struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	UserID    uint64    `gorm:"not null;index;column:user_id;comment:创建者ID"`
+	Name      string    `gorm:"type:varchar(50);not null;column:name;comment:分组名称"`
+	SortOrder int       `gorm:"not null;default:0;column:sort_order;comment:排序，越小越靠前"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (Circle) TableName() string {
+	return "friend_circles"
+}
+
+// ToEntity 转换为领域实体
+func (c *Circle) ToEntity() *entity.Circle {
+	return &entity.Circle{
+		ID:        c.ID,
+		UserID:    c.UserID,
+		Name:      c.Name,
+		SortOrder: c.SortOrder,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (c *Circle) FromEntity(circle *entity.Circle) {
+	c.ID = circle.ID
+	c.UserID = circle.UserID
+	c.Name = circle.Name
+	c.SortOrder = circle.SortOrder
+	c.CreatedAt = circle.CreatedAt
+	c.UpdatedAt = circle.UpdatedAt
+}
+
+// CircleMember 好友分组成员数据库模型
+type CircleMember struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	CircleID  uint64    `gorm:"not null;uniqueIndex:idx_circle_member;column:circle_id;comment:分组ID"`
+	MemberID  uint64    `gorm:"not null;uniqueIndex:idx_circle_member;index;column:member_id;comment:成员用户ID"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (CircleMember) TableName() string {
+	return "friend_circle_members"
+}
+
+// ToEntity 转换为领域实体
+func (m *CircleMember) ToEntity() *entity.CircleMember {
+	return &entity.CircleMember{
+		ID:        m.ID,
+		CircleID:  m.CircleID,
+		MemberID:  m.MemberID,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (m *CircleMember) FromEntity(member *entity.CircleMember) {
+	m.ID = member.ID
+	m.CircleID = member.CircleID
+	m.MemberID = member.MemberID
+	m.CreatedAt = member.CreatedAt
+}
@@ -7,12 +7,16 @@ import (
 
 // Friend 好友关系数据库模型
 type Friend struct {
-	ID        uint64    `gorm:"primaryKey;column:id"`
-	UserID    uint64    `gorm:"index:idx_user_friend,unique;column:user_id;comment:用户ID"`
-	FriendID  uint64    `gorm:"index:idx_user_friend,unique;column:friend_id;comment:好友ID"`
-	Status    int8      `gorm:"type:tinyint;default:0;column:status;comment:关系状态: 0-待确认, 1-已确认, 2-已拒绝, 3-已删除"`
-	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+	ID          uint64     `gorm:"primaryKey;column:id"`
+	UserID      uint64     `gorm:"index:idx_user_friend,unique;column:user_id;comment:用户ID"`
+	FriendID    uint64     `gorm:"index:idx_user_friend,unique;column:friend_id;comment:好友ID"`
+	Status      int8       `gorm:"type:tinyint;default:0;column:status;comment:关系状态: 0-待确认, 1-已确认, 2-已拒绝, 3-已删除"`
+	RequestedAt *time.Time `gorm:"column:requested_at;comment:最近一次发起申请的时间"`
+	AcceptedAt  *time.Time `gorm:"column:accepted_at;comment:确认时间"`
+	RejectedAt  *time.Time `gorm:"column:rejected_at;comment:拒绝时间"`
+	RemovedAt   *time.Time `gorm:"column:removed_at;comment:删除或撤回时间"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
 }
 
 // TableName 指定表名
@@ -23,12 +27,16 @@ func (Friend) TableName() string {
 // ToEntity 转换为实体
 func (f *Friend) ToEntity() *entity.Friend {
 	return &entity.Friend{
-		ID:        f.ID,
-		UserID:    f.UserID,
-		FriendID:  f.FriendID,
-		Status:    f.Status,
-		CreatedAt: f.CreatedAt,
-		UpdatedAt: f.UpdatedAt,
+		ID:          f.ID,
+		UserID:      f.UserID,
+		FriendID:    f.FriendID,
+		Status:      entity.FriendStatus(f.Status),
+		RequestedAt: f.RequestedAt,
+		AcceptedAt:  f.AcceptedAt,
+		RejectedAt:  f.RejectedAt,
+		RemovedAt:   f.RemovedAt,
+		CreatedAt:   f.CreatedAt,
+		UpdatedAt:   f.UpdatedAt,
 	}
 }
 
@@ -37,7 +45,11 @@ func (f *Friend) FromEntity(friend *entity.Friend) {
 	f.ID = friend.ID
 	f.UserID = friend.UserID
 	f.FriendID = friend.FriendID
-	f.Status = friend.Status
+	f.Status = int8(friend.Status)
+	f.RequestedAt = friend.RequestedAt
+	f.AcceptedAt = friend.AcceptedAt
+	f.RejectedAt = friend.RejectedAt
+	f.RemovedAt = friend.RemovedAt
 	f.CreatedAt = friend.CreatedAt
 	f.UpdatedAt = friend.UpdatedAt
 }
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// Goal 个人目标数据库模型
+type Goal struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	UserID    uint64    `gorm:"not null;index;column:user_id;comment:用户ID"`
+	Name      string    `gorm:"type:varchar(50);not null;column:name;comment:目标名称"`
+	Metric    string    `gorm:"type:varchar(30);not null;column:metric;comment:目标指标"`
+	Target    float64   `gorm:"not null;column:target;comment:目标值"`
+	Window    string    `gorm:"type:varchar(10);not null;column:window;comment:统计周期: day-天, week-周, month-月"`
+	Status    int8      `gorm:"type:tinyint;default:1;column:status;comment:目标状态: 1-进行中, 0-已归档"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (Goal) TableName() string {
+	return "goals"
+}
+
+// ToEntity 转换为领域实体
+func (g *Goal) ToEntity() *entity.Goal {
+	return &entity.Goal{
+		ID:        g.ID,
+		UserID:    g.UserID,
+		Name:      g.Name,
+		Metric:    g.Metric,
+		Target:    g.Target,
+		Window:    g.Window,
+		Status:    g.Status,
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (g *Goal) FromEntity(goal *entity.Goal) {
+	g.ID = goal.ID
+	g.UserID = goal.UserID
+	g.Name = goal.Name
+	g.Metric = goal.Metric
+	g.Target = goal.Target
+	g.Window = goal.Window
+	g.Status = goal.Status
+	g.CreatedAt = goal.CreatedAt
+	g.UpdatedAt = goal.UpdatedAt
+}
+
+// GoalCompletion 目标完成记录数据库模型
+type GoalCompletion struct {
+	ID          uint64     `gorm:"primaryKey;column:id"`
+	GoalID      uint64     `gorm:"not null;uniqueIndex:idx_goal_period;column:goal_id;comment:目标ID"`
+	UserID      uint64     `gorm:"not null;index;column:user_id;comment:用户ID"`
+	PeriodStart time.Time  `gorm:"not null;uniqueIndex:idx_goal_period;column:period_start;comment:周期开始时间"`
+	PeriodEnd   time.Time  `gorm:"not null;column:period_end;comment:周期结束时间"`
+	Value       *float64   `gorm:"column:value;comment:周期内的指标值"`
+	Met         bool       `gorm:"column:met;comment:是否达成"`
+	MetAt       *time.Time `gorm:"column:met_at;comment:达成时间"`
+	Settled     bool       `gorm:"column:settled;comment:是否已结算"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (GoalCompletion) TableName() string {
+	return "goal_completions"
+}
+
+// ToEntity 转换为领域实体
+func (gc *GoalCompletion) ToEntity() *entity.GoalCompletion {
+	return &entity.GoalCompletion{
+		ID:          gc.ID,
+		GoalID:      gc.GoalID,
+		UserID:      gc.UserID,
+		PeriodStart: gc.PeriodStart,
+		PeriodEnd:   gc.PeriodEnd,
+		Value:       gc.Value,
+		Met:         gc.Met,
+		MetAt:       gc.MetAt,
+		Settled:     gc.Settled,
+		CreatedAt:   gc.CreatedAt,
+		UpdatedAt:   gc.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (gc *GoalCompletion) FromEntity(completion *entity.GoalCompletion) {
+	gc.ID = completion.ID
+	gc.GoalID = completion.GoalID
+	gc.UserID = completion.UserID
+	gc.PeriodStart = completion.PeriodStart
+	gc.PeriodEnd = completion.PeriodEnd
+	gc.Value = completion.Value
+	gc.Met = completion.Met
+	gc.MetAt = completion.MetAt
+	gc.Settled = completion.Settled
+	gc.CreatedAt = completion.CreatedAt
+	gc.UpdatedAt = completion.UpdatedAt
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// InviteCode 好友邀请码数据库模型
+type InviteCode struct {
+	ID        uint64     `gorm:"primaryKey;column:id"`
+	UserID    uint64     `gorm:"not null;index;column:user_id;comment:用户ID"`
+	Code      string     `gorm:"type:varchar(16);not null;uniqueIndex;column:code;comment:邀请码"`
+	RevokedAt *time.Time `gorm:"column:revoked_at;comment:失效时间"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (InviteCode) TableName() string {
+	return "invite_codes"
+}
+
+// ToEntity 转换为领域实体
+func (c *InviteCode) ToEntity() *entity.InviteCode {
+	return &entity.InviteCode{
+		ID:        c.ID,
+		UserID:    c.UserID,
+		Code:      c.Code,
+		RevokedAt: c.RevokedAt,
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (c *InviteCode) FromEntity(code *entity.InviteCode) {
+	c.ID = code.ID
+	c.UserID = code.UserID
+	c.Code = code.Code
+	c.RevokedAt = code.RevokedAt
+	c.CreatedAt = code.CreatedAt
+}
+
+// InviteRedemption 邀请记录数据库模型
+type InviteRedemption struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	InviterID uint64    `gorm:"not null;uniqueIndex:idx_inviter_invitee;column:inviter_id;comment:邀请人ID"`
+	InviteeID uint64    `gorm:"not null;uniqueIndex:idx_inviter_invitee;index;column:invitee_id;comment:被邀请人ID"`
+	Code      string    `gorm:"type:varchar(16);not null;column:code;comment:使用的邀请码"`
+	NewUser   bool      `gorm:"column:new_user;comment:是否为通过邀请注册的新用户"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (InviteRedemption) TableName() string {
+	return "invite_redemptions"
+}
+
+// ToEntity 转换为领域实体
+func (r *InviteRedemption) ToEntity() *entity.InviteRedemption {
+	return &entity.InviteRedemption{
+		ID:        r.ID,
+		InviterID: r.InviterID,
+		InviteeID: r.InviteeID,
+		Code:      r.Code,
+		NewUser:   r.NewUser,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (r *InviteRedemption) FromEntity(redemption *entity.InviteRedemption) {
+	r.ID = redemption.ID
+	r.InviterID = redemption.InviterID
+	r.InviteeID = redemption.InviteeID
+	r.Code = redemption.Code
+	r.NewUser = redemption.NewUser
+	r.CreatedAt = redemption.CreatedAt
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// League 联赛赛季数据库模型
+type League struct {
+	ID        uint64     `gorm:"primaryKey;column:id"`
+	Period    string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_league_period_start;column:period;comment:赛季周期: weekly-周, monthly-月"`
+	Metric    string     `gorm:"type:varchar(30);not null;column:metric;comment:排名指标"`
+	StartAt   time.Time  `gorm:"not null;uniqueIndex:idx_league_period_start;column:start_at;comment:赛季开始时间"`
+	EndAt     time.Time  `gorm:"not null;column:end_at;comment:赛季结束时间(不含)"`
+	Status    string     `gorm:"type:varchar(10);not null;index;column:status;comment:赛季状态: active-进行中, closed-已结算"`
+	ClosedAt  *time.Time `gorm:"column:closed_at;comment:结算时间"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (League) TableName() string {
+	return "leagues"
+}
+
+// ToEntity 转换为领域实体
+func (l *League) ToEntity() *entity.League {
+	return &entity.League{
+		ID:        l.ID,
+		Period:    l.Period,
+		Metric:    l.Metric,
+		StartAt:   l.StartAt,
+		EndAt:     l.EndAt,
+		Status:    l.Status,
+		ClosedAt:  l.ClosedAt,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (l *League) FromEntity(league *entity.League) {
+	l.ID = league.ID
+	l.Period = league.Period
+	l.Metric = league.Metric
+	l.StartAt = league.StartAt
+	l.EndAt = league.EndAt
+	l.Status = league.Status
+	l.ClosedAt = league.ClosedAt
+	l.CreatedAt = league.CreatedAt
+	l.UpdatedAt = league.UpdatedAt
+}
+
+// LeagueDivision 联赛分组数据库模型
+type LeagueDivision struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	LeagueID  uint64    `gorm:"not null;index;column:league_id;comment:赛季ID"`
+	Tier      int       `gorm:"not null;column:tier;comment:段位"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (LeagueDivision) TableName() string {
+	return "league_divisions"
+}
+
+// ToEntity 转换为领域实体
+func (d *LeagueDivision) ToEntity() *entity.LeagueDivision {
+	return &entity.LeagueDivision{
+		ID:        d.ID,
+		LeagueID:  d.LeagueID,
+		Tier:      d.Tier,
+		TierName:  entity.LeagueTierName(d.Tier),
+		CreatedAt: d.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (d *LeagueDivision) FromEntity(division *entity.LeagueDivision) {
+	d.ID = division.ID
+	d.LeagueID = division.LeagueID
+	d.Tier = division.Tier
+	d.CreatedAt = division.CreatedAt
+}
+
+// LeagueMembership 联赛参赛信息数据库模型
+type LeagueMembership struct {
+	ID          uint64    `gorm:"primaryKey;column:id"`
+	LeagueID    uint64    `gorm:"not null;uniqueIndex:idx_league_user;column:league_id;comment:赛季ID"`
+	DivisionID  uint64    `gorm:"not null;index;column:division_id;comment:分组ID"`
+	UserID      uint64    `gorm:"not null;uniqueIndex:idx_league_user;index;column:user_id;comment:用户ID"`
+	Tier        int       `gorm:"not null;column:tier;comment:本赛季段位"`
+	FinalRank   uint64    `gorm:"column:final_rank;comment:赛季结束时的名次"`
+	MetricValue float64   `gorm:"column:metric_value;comment:赛季结束时的指标值"`
+	Outcome     string    `gorm:"type:varchar(10);column:outcome;comment:升降级结果: promoted-升级, relegated-降级, stayed-保级"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (LeagueMembership) TableName() string {
+	return "league_memberships"
+}
+
+// ToEntity 转换为领域实体
+func (m *LeagueMembership) ToEntity() *entity.LeagueMembership {
+	return &entity.LeagueMembership{
+		ID:          m.ID,
+		LeagueID:    m.LeagueID,
+		DivisionID:  m.DivisionID,
+		UserID:      m.UserID,
+		Tier:        m.Tier,
+		TierName:    entity.LeagueTierName(m.Tier),
+		FinalRank:   m.FinalRank,
+		MetricValue: m.MetricValue,
+		Outcome:     m.Outcome,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (m *LeagueMembership) FromEntity(membership *entity.LeagueMembership) {
+	m.ID = membership.ID
+	m.LeagueID = membership.LeagueID
+	m.DivisionID = membership.DivisionID
+	m.UserID = membership.UserID
+	m.Tier = membership.Tier
+	m.FinalRank = membership.FinalRank
+	m.MetricValue = membership.MetricValue
+	m.Outcome = membership.Outcome
+	m.CreatedAt = membership.CreatedAt
+	m.UpdatedAt = membership.UpdatedAt
+}
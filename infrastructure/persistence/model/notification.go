@@ -0,0 +1,49 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// Notification 站内通知数据库模型
+type Notification struct {
+	ID        uint64     `gorm:"primaryKey;column:id"`
+	UserID    uint64     `gorm:"not null;index:idx_notification_user_read,priority:1;column:user_id;comment:接收者ID"`
+	ActorID   uint64     `gorm:"not null;default:0;column:actor_id;comment:触发通知的用户ID，系统通知为0"`
+	Type      string     `gorm:"type:varchar(20);not null;column:type;comment:通知类型: nudge-戳一戳, challenge_invite-挑战邀请, challenge_result-挑战结果, badge-获得徽章"`
+	Content   string     `gorm:"type:varchar(255);not null;column:content;comment:通知内容"`
+	RefID     uint64     `gorm:"column:ref_id;comment:关联对象ID"`
+	ReadAt    *time.Time `gorm:"index:idx_notification_user_read,priority:2;column:read_at;comment:已读时间"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// ToEntity 转换为领域实体
+func (n *Notification) ToEntity() *entity.Notification {
+	return &entity.Notification{
+		ID:        n.ID,
+		UserID:    n.UserID,
+		ActorID:   n.ActorID,
+		Type:      n.Type,
+		Content:   n.Content,
+		RefID:     n.RefID,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (n *Notification) FromEntity(notification *entity.Notification) {
+	n.ID = notification.ID
+	n.UserID = notification.UserID
+	n.ActorID = notification.ActorID
+	n.Type = notification.Type
+	n.Content = notification.Content
+	n.RefID = notification.RefID
+	n.ReadAt = notification.ReadAt
+	n.CreatedAt = notification.CreatedAt
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// Nudge 戳一戳数据库模型
+type Nudge struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	SenderID  uint64    `gorm:"not null;uniqueIndex:idx_nudge_sender_target_day;column:sender_id;comment:发送者ID"`
+	TargetID  uint64    `gorm:"not null;uniqueIndex:idx_nudge_sender_target_day;index;column:target_id;comment:被提醒的好友ID"`
+	Day       time.Time `gorm:"type:date;not null;uniqueIndex:idx_nudge_sender_target_day;column:day;comment:发送日期"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (Nudge) TableName() string {
+	return "nudges"
+}
+
+// ToEntity 转换为领域实体
+func (n *Nudge) ToEntity() *entity.Nudge {
+	return &entity.Nudge{
+		ID:        n.ID,
+		SenderID:  n.SenderID,
+		TargetID:  n.TargetID,
+		Day:       n.Day,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (n *Nudge) FromEntity(nudge *entity.Nudge) {
+	n.ID = nudge.ID
+	n.SenderID = nudge.SenderID
+	n.TargetID = nudge.TargetID
+	n.Day = nudge.Day
+	n.CreatedAt = nudge.CreatedAt
+}
+
+// NudgeSetting 戳一戳设置数据库模型
+type NudgeSetting struct {
+	UserID    uint64    `gorm:"primaryKey;autoIncrement:false;column:user_id;comment:用户ID"`
+	Muted     bool      `gorm:"not null;default:false;column:muted;comment:是否不再接收戳一戳"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (NudgeSetting) TableName() string {
+	return "nudge_settings"
+}
+
+// ToEntity 转换为领域实体
+func (s *NudgeSetting) ToEntity() *entity.NudgeSetting {
+	return &entity.NudgeSetting{
+		UserID:    s.UserID,
+		Muted:     s.Muted,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (s *NudgeSetting) FromEntity(setting *entity.NudgeSetting) {
+	s.UserID = setting.UserID
+	s.Muted = setting.Muted
+	s.UpdatedAt = setting.UpdatedAt
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// RankingSetting 排行榜设置数据库模型
+type RankingSetting struct {
+	UserID     uint64    `gorm:"primaryKey;autoIncrement:false;column:user_id;comment:用户ID"`
+	Visibility string    `gorm:"type:varchar(20);not null;default:visible;index;column:visibility;comment:排行榜可见性: visible-公开, anonymous-匿名, friends_only-仅好友, excluded-不参与"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (RankingSetting) TableName() string {
+	return "ranking_settings"
+}
+
+// ToEntity 转换为领域实体
+func (rs *RankingSetting) ToEntity() *entity.RankingSetting {
+	return &entity.RankingSetting{
+		UserID:     rs.UserID,
+		Visibility: rs.Visibility,
+		UpdatedAt:  rs.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (rs *RankingSetting) FromEntity(setting *entity.RankingSetting) {
+	rs.UserID = setting.UserID
+	rs.Visibility = setting.Visibility
+	rs.UpdatedAt = setting.UpdatedAt
+}
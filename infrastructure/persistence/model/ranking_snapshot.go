@@ -0,0 +1,64 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// RankingSnapshot 排行榜快照数据库模型
+type RankingSnapshot struct {
+	ID          uint64    `gorm:"primaryKey;column:id"`
+	Scope       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_snapshot_board_user;column:scope;comment:榜单范围: global-全局, friends-好友"`
+	OwnerID     uint64    `gorm:"not null;default:0;uniqueIndex:idx_snapshot_board_user;column:owner_id;comment:好友榜所属用户ID，全局榜为0"`
+	Period      string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_snapshot_board_user;column:period;comment:快照周期: daily-日, weekly-周, monthly-月"`
+	PeriodStart time.Time `gorm:"not null;uniqueIndex:idx_snapshot_board_user;column:period_start;comment:周期开始时间"`
+	PeriodEnd   time.Time `gorm:"not null;column:period_end;comment:周期结束时间"`
+	Metric      string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_snapshot_board_user;column:metric;comment:排行指标"`
+	UserID      uint64    `gorm:"not null;uniqueIndex:idx_snapshot_board_user;index:idx_snapshot_user;column:user_id;comment:用户ID"`
+	Rank        uint64    `gorm:"not null;column:rank;comment:名次"`
+	MetricValue float64   `gorm:"column:metric_value;comment:指标值"`
+	RecordCount int64     `gorm:"column:record_count;comment:记录次数"`
+	Total       int       `gorm:"column:total;comment:榜单总人数"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (RankingSnapshot) TableName() string {
+	return "ranking_snapshots"
+}
+
+// ToEntity 转换为领域实体
+func (rs *RankingSnapshot) ToEntity() *entity.RankingSnapshot {
+	return &entity.RankingSnapshot{
+		ID:          rs.ID,
+		Scope:       rs.Scope,
+		OwnerID:     rs.OwnerID,
+		Period:      rs.Period,
+		PeriodStart: rs.PeriodStart,
+		PeriodEnd:   rs.PeriodEnd,
+		Metric:      rs.Metric,
+		UserID:      rs.UserID,
+		Rank:        rs.Rank,
+		MetricValue: rs.MetricValue,
+		RecordCount: rs.RecordCount,
+		Total:       rs.Total,
+		CreatedAt:   rs.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (rs *RankingSnapshot) FromEntity(snapshot *entity.RankingSnapshot) {
+	rs.ID = snapshot.ID
+	rs.Scope = snapshot.Scope
+	rs.OwnerID = snapshot.OwnerID
+	rs.Period = snapshot.Period
+	rs.PeriodStart = snapshot.PeriodStart
+	rs.PeriodEnd = snapshot.PeriodEnd
+	rs.Metric = snapshot.Metric
+	rs.UserID = snapshot.UserID
+	rs.Rank = snapshot.Rank
+	rs.MetricValue = snapshot.MetricValue
+	rs.RecordCount = snapshot.RecordCount
+	rs.Total = snapshot.Total
+	rs.CreatedAt = snapshot.CreatedAt
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"encoding/json"
+	"record-project/domain/entity"
+	"time"
+)
+
+// Recap 回顾数据库模型
+type Recap struct {
+	ID                 uint64     `gorm:"primaryKey;column:id"`
+	UserID             uint64     `gorm:"not null;uniqueIndex:idx_user_period;column:user_id;comment:用户ID"`
+	PeriodType         string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_user_period;column:period_type;comment:周期类型: month-月度, year-年度"`
+	PeriodStart        time.Time  `gorm:"not null;uniqueIndex:idx_user_period;column:period_start;comment:周期开始时间"`
+	PeriodEnd          time.Time  `gorm:"not null;column:period_end;comment:周期结束时间"`
+	TotalCount         int64      `gorm:"column:total_count;comment:记录总数"`
+	TotalDuration      int64      `gorm:"column:total_duration;comment:总时长(秒)"`
+	BusiestDay         *time.Time `gorm:"column:busiest_day;comment:记录最多的一天"`
+	BusiestDayCount    int        `gorm:"column:busiest_day_count;comment:最忙一天的记录数"`
+	FavoriteHour       int        `gorm:"column:favorite_hour;comment:最常记录的小时"`
+	LongestSessionID   uint64     `gorm:"column:longest_session_id;comment:时长最长的记录ID"`
+	LongestSession     int        `gorm:"column:longest_session;comment:最长单次时长(秒)"`
+	TypeDistribution   string     `gorm:"type:text;column:type_distribution;comment:类型分布(JSON)"`
+	FriendRankHistory  string     `gorm:"type:text;column:friend_rank_history;comment:好友排名变化(JSON)"`
+	LongestStreak      int        `gorm:"column:longest_streak;comment:周期内最长连续天数"`
+	PreviousBestStreak int        `gorm:"column:previous_best_streak;comment:周期前历史最长连续天数"`
+	NewStreakRecord    bool       `gorm:"column:new_streak_record;comment:是否刷新连续记录"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (Recap) TableName() string {
+	return "recaps"
+}
+
+// ToEntity 转换为领域实体
+func (r *Recap) ToEntity() *entity.Recap {
+	recap := &entity.Recap{
+		ID:                 r.ID,
+		UserID:             r.UserID,
+		PeriodType:         r.PeriodType,
+		PeriodStart:        r.PeriodStart,
+		PeriodEnd:          r.PeriodEnd,
+		TotalCount:         r.TotalCount,
+		TotalDuration:      r.TotalDuration,
+		BusiestDay:         r.BusiestDay,
+		BusiestDayCount:    r.BusiestDayCount,
+		FavoriteHour:       r.FavoriteHour,
+		LongestSessionID:   r.LongestSessionID,
+		LongestSession:     r.LongestSession,
+		TypeDistribution:   map[uint64]int64{},
+		FriendRankHistory:  []*entity.RecapRankPoint{},
+		LongestStreak:      r.LongestStreak,
+		PreviousBestStreak: r.PreviousBestStreak,
+		NewStreakRecord:    r.NewStreakRecord,
+		CreatedAt:          r.CreatedAt,
+	}
+
+	// JSON字段解析失败时保留空值，不影响其余数据展示
+	if r.TypeDistribution != "" {
+		_ = json.Unmarshal([]byte(r.TypeDistribution), &recap.TypeDistribution)
+	}
+	if r.FriendRankHistory != "" {
+		_ = json.Unmarshal([]byte(r.FriendRankHistory), &recap.FriendRankHistory)
+	}
+
+	return recap
+}
+
+// FromEntity 从领域实体转换
+func (r *Recap) FromEntity(recap *entity.Recap) error {
+	typeDistribution, err := json.Marshal(recap.TypeDistribution)
+	if err != nil {
+		return err
+	}
+	friendRankHistory, err := json.Marshal(recap.FriendRankHistory)
+	if err != nil {
+		return err
+	}
+
+	r.ID = recap.ID
+	r.UserID = recap.UserID
+	r.PeriodType = recap.PeriodType
+	r.PeriodStart = recap.PeriodStart
+	r.PeriodEnd = recap.PeriodEnd
+	r.TotalCount = recap.TotalCount
+	r.TotalDuration = recap.TotalDuration
+	r.BusiestDay = recap.BusiestDay
+	r.BusiestDayCount = recap.BusiestDayCount
+	r.FavoriteHour = recap.FavoriteHour
+	r.LongestSessionID = recap.LongestSessionID
+	r.LongestSession = recap.LongestSession
+	r.TypeDistribution = string(typeDistribution)
+	r.FriendRankHistory = string(friendRankHistory)
+	r.LongestStreak = recap.LongestStreak
+	r.PreviousBestStreak = recap.PreviousBestStreak
+	r.NewStreakRecord = recap.NewStreakRecord
+	r.CreatedAt = recap.CreatedAt
+	return nil
+}
@@ -13,6 +13,8 @@ type Record struct {
 	Duration   int       `gorm:"column:duration;comment:持续时间(秒)"`
 	PoopTypeID uint64    `gorm:"column:poop_type_id;comment:屎的类型ID"`
 	Note       string    `gorm:"type:text;column:note;comment:备注"`
+	Visibility string    `gorm:"type:varchar(10);not null;default:friends;column:visibility;comment:可见范围: private-仅自己, friends-好友, circle-指定分组, public-所有人"`
+	CircleID   uint64    `gorm:"not null;default:0;column:circle_id;comment:可见范围为分组时的好友分组ID"`
 	CreatedAt  time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
 	UpdatedAt  time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
 }
@@ -31,6 +33,8 @@ func (r *Record) ToEntity() *entity.Record {
 		Duration:   r.Duration,
 		PoopTypeID: r.PoopTypeID,
 		Note:       r.Note,
+		Visibility: r.Visibility,
+		CircleID:   r.CircleID,
 		CreatedAt:  r.CreatedAt,
 		UpdatedAt:  r.UpdatedAt,
 	}
@@ -44,6 +48,8 @@ func (r *Record) FromEntity(record *entity.Record) {
 	r.Duration = record.Duration
 	r.PoopTypeID = record.PoopTypeID
 	r.Note = record.Note
+	r.Visibility = record.Visibility
+	r.CircleID = record.CircleID
 	r.CreatedAt = record.CreatedAt
 	r.UpdatedAt = record.UpdatedAt
 }
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"strings"
+	"time"
+)
+
+// RecordFlag 记录反作弊标记数据库模型
+type RecordFlag struct {
+	ID           uint64     `gorm:"primaryKey;column:id"`
+	RecordID     uint64     `gorm:"not null;uniqueIndex;column:record_id;comment:记录ID"`
+	UserID       uint64     `gorm:"not null;index;column:user_id;comment:用户ID"`
+	Rules        string     `gorm:"type:varchar(100);not null;column:rules;comment:命中的规则，逗号分隔"`
+	Reason       string     `gorm:"type:varchar(500);column:reason;comment:命中规则的说明"`
+	Status       string     `gorm:"type:varchar(20);not null;index;column:status;comment:状态: pending-待审核, appealed-已申诉, dismissed-已恢复, confirmed-确认作弊"`
+	AppealReason string     `gorm:"type:varchar(500);column:appeal_reason;comment:申诉理由"`
+	AppealedAt   *time.Time `gorm:"column:appealed_at;comment:申诉时间"`
+	ReviewerID   uint64     `gorm:"column:reviewer_id;comment:审核人ID"`
+	ReviewNote   string     `gorm:"type:varchar(500);column:review_note;comment:审核备注"`
+	ReviewedAt   *time.Time `gorm:"column:reviewed_at;comment:审核时间"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (RecordFlag) TableName() string {
+	return "record_flags"
+}
+
+// ToEntity 转换为领域实体
+func (rf *RecordFlag) ToEntity() *entity.RecordFlag {
+	var rules []string
+	if rf.Rules != "" {
+		rules = strings.Split(rf.Rules, ",")
+	}
+	return &entity.RecordFlag{
+		ID:           rf.ID,
+		RecordID:     rf.RecordID,
+		UserID:       rf.UserID,
+		Rules:        rules,
+		Reason:       rf.Reason,
+		Status:       rf.Status,
+		AppealReason: rf.AppealReason,
+		AppealedAt:   rf.AppealedAt,
+		ReviewerID:   rf.ReviewerID,
+		ReviewNote:   rf.ReviewNote,
+		ReviewedAt:   rf.ReviewedAt,
+		CreatedAt:    rf.CreatedAt,
+		UpdatedAt:    rf.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (rf *RecordFlag) FromEntity(flag *entity.RecordFlag) {
+	rf.ID = flag.ID
+	rf.RecordID = flag.RecordID
+	rf.UserID = flag.UserID
+	rf.Rules = strings.Join(flag.Rules, ",")
+	rf.Reason = flag.Reason
+	rf.Status = flag.Status
+	rf.AppealReason = flag.AppealReason
+	rf.AppealedAt = flag.AppealedAt
+	rf.ReviewerID = flag.ReviewerID
+	rf.ReviewNote = flag.ReviewNote
+	rf.ReviewedAt = flag.ReviewedAt
+	rf.CreatedAt = flag.CreatedAt
+	rf.UpdatedAt = flag.UpdatedAt
+}
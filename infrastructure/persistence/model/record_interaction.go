@@ -0,0 +1,80 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// RecordReaction 记录表情回应数据库模型
+type RecordReaction struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	RecordID  uint64    `gorm:"not null;uniqueIndex:idx_reaction_record_user_emoji;column:record_id;comment:记录ID"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:idx_reaction_record_user_emoji;index:idx_reaction_user_created,priority:1;column:user_id;comment:回应的用户ID"`
+	Emoji     string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_reaction_record_user_emoji;column:emoji;comment:表情"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_reaction_user_created,priority:2;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (RecordReaction) TableName() string {
+	return "record_reactions"
+}
+
+// ToEntity 转换为领域实体
+func (r *RecordReaction) ToEntity() *entity.RecordReaction {
+	return &entity.RecordReaction{
+		ID:        r.ID,
+		RecordID:  r.RecordID,
+		UserID:    r.UserID,
+		Emoji:     r.Emoji,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (r *RecordReaction) FromEntity(reaction *entity.RecordReaction) {
+	r.ID = reaction.ID
+	r.RecordID = reaction.RecordID
+	r.UserID = reaction.UserID
+	r.Emoji = reaction.Emoji
+	r.CreatedAt = reaction.CreatedAt
+}
+
+// RecordComment 记录评论数据库模型
+type RecordComment struct {
+	ID            uint64    `gorm:"primaryKey;column:id"`
+	RecordID      uint64    `gorm:"not null;index;column:record_id;comment:记录ID"`
+	UserID        uint64    `gorm:"not null;index:idx_comment_user_created,priority:1;column:user_id;comment:评论的用户ID"`
+	ParentID      uint64    `gorm:"not null;default:0;index;column:parent_id;comment:所属一级评论ID，一级评论为0"`
+	ReplyToUserID uint64    `gorm:"column:reply_to_user_id;comment:回复的用户ID"`
+	Content       string    `gorm:"type:varchar(800);not null;column:content;comment:评论内容"`
+	CreatedAt     time.Time `gorm:"autoCreateTime;index:idx_comment_user_created,priority:2;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (RecordComment) TableName() string {
+	return "record_comments"
+}
+
+// ToEntity 转换为领域实体
+func (c *RecordComment) ToEntity() *entity.RecordComment {
+	return &entity.RecordComment{
+		ID:            c.ID,
+		RecordID:      c.RecordID,
+		UserID:        c.UserID,
+		ParentID:      c.ParentID,
+		ReplyToUserID: c.ReplyToUserID,
+		Content:       c.Content,
+		CreatedAt:     c.CreatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (c *RecordComment) FromEntity(comment *entity.RecordComment) {
+	c.ID = comment.ID
+	c.RecordID = comment.RecordID
+	c.UserID = comment.UserID
+	c.ParentID = comment.ParentID
+	c.ReplyToUserID = comment.ReplyToUserID
+	c.Content = comment.Content
+	c.CreatedAt = comment.CreatedAt
+}
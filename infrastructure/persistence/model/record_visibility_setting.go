@@ -0,0 +1,38 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// RecordVisibilitySetting 记录默认可见范围设置数据库模型
+type RecordVisibilitySetting struct {
+	UserID     uint64    `gorm:"primaryKey;autoIncrement:false;column:user_id;comment:用户ID"`
+	Visibility string    `gorm:"type:varchar(10);not null;default:friends;column:visibility;comment:新记录的默认可见范围: private-仅自己, friends-好友, circle-指定分组, public-所有人"`
+	CircleID   uint64    `gorm:"not null;default:0;column:circle_id;comment:默认可见范围为分组时的好友分组ID"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (RecordVisibilitySetting) TableName() string {
+	return "record_visibility_settings"
+}
+
+// ToEntity 转换为领域实体
+func (s *RecordVisibilitySetting) ToEntity() *entity.RecordVisibilitySetting {
+	return &entity.RecordVisibilitySetting{
+		UserID:     s.UserID,
+		Visibility: s.Visibility,
+		CircleID:   s.CircleID,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (s *RecordVisibilitySetting) FromEntity(setting *entity.RecordVisibilitySetting) {
+	s.UserID = setting.UserID
+	s.Visibility = setting.Visibility
+	s.CircleID = setting.CircleID
+	s.UpdatedAt = setting.UpdatedAt
+}
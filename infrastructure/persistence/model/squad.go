@@ -0,0 +1,80 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// Squad 小组数据库模型
+type Squad struct {
+	ID         uint64    `gorm:"primaryKey;column:id"`
+	OwnerID    uint64    `gorm:"not null;index;column:owner_id;comment:创建者ID"`
+	Name       string    `gorm:"type:varchar(80);not null;column:name;comment:小组名称"`
+	InviteCode string    `gorm:"type:varchar(16);not null;uniqueIndex;column:invite_code;comment:邀请码"`
+	MemberCap  int       `gorm:"not null;column:member_cap;comment:成员上限"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime;column:updated_at;comment:更新时间"`
+}
+
+// TableName 指定表名
+func (Squad) TableName() string {
+	return "squads"
+}
+
+// ToEntity 转换为领域实体
+func (s *Squad) ToEntity() *entity.Squad {
+	return &entity.Squad{
+		ID:         s.ID,
+		OwnerID:    s.OwnerID,
+		Name:       s.Name,
+		InviteCode: s.InviteCode,
+		MemberCap:  s.MemberCap,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (s *Squad) FromEntity(squad *entity.Squad) {
+	s.ID = squad.ID
+	s.OwnerID = squad.OwnerID
+	s.Name = squad.Name
+	s.InviteCode = squad.InviteCode
+	s.MemberCap = squad.MemberCap
+	s.CreatedAt = squad.CreatedAt
+	s.UpdatedAt = squad.UpdatedAt
+}
+
+// SquadMember 小组成员数据库模型
+type SquadMember struct {
+	ID       uint64    `gorm:"primaryKey;column:id"`
+	SquadID  uint64    `gorm:"not null;uniqueIndex:idx_squad_member;column:squad_id;comment:小组ID"`
+	UserID   uint64    `gorm:"not null;uniqueIndex:idx_squad_member;index;column:user_id;comment:成员ID"`
+	Role     string    `gorm:"type:varchar(10);not null;column:role;comment:角色: owner-创建者, admin-管理员, member-成员"`
+	JoinedAt time.Time `gorm:"autoCreateTime;column:joined_at;comment:加入时间"`
+}
+
+// TableName 指定表名
+func (SquadMember) TableName() string {
+	return "squad_members"
+}
+
+// ToEntity 转换为领域实体
+func (m *SquadMember) ToEntity() *entity.SquadMember {
+	return &entity.SquadMember{
+		ID:       m.ID,
+		SquadID:  m.SquadID,
+		UserID:   m.UserID,
+		Role:     m.Role,
+		JoinedAt: m.JoinedAt,
+	}
+}
+
+// FromEntity 从领域实体转换
+func (m *SquadMember) FromEntity(member *entity.SquadMember) {
+	m.ID = member.ID
+	m.SquadID = member.SquadID
+	m.UserID = member.UserID
+	m.Role = member.Role
+	m.JoinedAt = member.JoinedAt
+}
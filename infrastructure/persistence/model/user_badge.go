@@ -0,0 +1,41 @@
+package model
+
+import (
+	"record-project/domain/entity"
+	"time"
+)
+
+// UserBadge 用户徽章数据库模型
+type UserBadge struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:idx_user_badge;column:user_id;comment:用户ID"`
+	BadgeCode string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_badge;column:badge_code;comment:徽章代码"`
+	AwardedAt time.Time `gorm:"not null;column:awarded_at;comment:获得时间"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;comment:创建时间"`
+}
+
+// TableName 指定表名
+func (UserBadge) TableName() string {
+	return "user_badges"
+}
+
+// ToEntity 转换为领域实体
+func (b *UserBadge) ToEntity() *entity.UserBadge {
+	return &entity.UserBadge{
+		ID:        b.ID,
+		UserID:    b.UserID,
+		BadgeCode: b.BadgeCode,
+		AwardedAt: b.AwardedAt,
+		CreatedAt: b.CreatedAt,
+		Badge:     entity.FindBadge(b.BadgeCode),
+	}
+}
+
+// FromEntity 从领域实体转换
+func (b *UserBadge) FromEntity(badge *entity.UserBadge) {
+	b.ID = badge.ID
+	b.UserID = badge.UserID
+	b.BadgeCode = badge.BadgeCode
+	b.AwardedAt = badge.AwardedAt
+	b.CreatedAt = badge.CreatedAt
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activityRepository 好友动态仓储实现
+type activityRepository struct {
+	db *gorm.DB
+}
+
+// NewActivityRepository 创建好友动态仓储
+func NewActivityRepository(db *gorm.DB) repository.ActivityRepository {
+	return &activityRepository{db: db}
+}
+
+// Save 保存动态，同一用户的去重键已存在时忽略
+func (r *activityRepository) Save(ctx context.Context, activity *entity.Activity) error {
+	var activityModel model.Activity
+	if err := activityModel.FromEntity(activity); err != nil {
+		return err
+	}
+
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&activityModel).Error; err != nil {
+		return err
+	}
+
+	activity.ID = activityModel.ID
+	activity.CreatedAt = activityModel.CreatedAt
+	return nil
+}
+
+// SaveBatch 批量保存动态，去重键已存在的忽略
+func (r *activityRepository) SaveBatch(ctx context.Context, activities []*entity.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	activityModels := make([]model.Activity, len(activities))
+	for i, activity := range activities {
+		if err := activityModels[i].FromEntity(activity); err != nil {
+			return err
+		}
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&activityModels, 500).Error
+}
+
+// UpdateByRecord 将记录的可见范围和时间同步到与该记录相关的动态
+func (r *activityRepository) UpdateByRecord(ctx context.Context, record *entity.Record) error {
+	return r.db.WithContext(ctx).Model(&model.Activity{}).
+		Where("record_id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"visibility":  record.Visibility,
+			"circle_id":   record.CircleID,
+			"occurred_at": record.RecordTime,
+		}).Error
+}
+
+// DeleteByRecordID 删除与记录相关的动态
+func (r *activityRepository) DeleteByRecordID(ctx context.Context, recordID uint64) error {
+	return r.db.WithContext(ctx).Where("record_id = ?", recordID).Delete(&model.Activity{}).Error
+}
+
+// DeleteByRefKey 删除用户指定去重键的动态
+func (r *activityRepository) DeleteByRefKey(ctx context.Context, actorID uint64, refKey string) error {
+	return r.db.WithContext(ctx).Where("actor_id = ? AND ref_key = ?", actorID, refKey).Delete(&model.Activity{}).Error
+}
+
+// FindFeed 查询actorIDs产生的、查看者可见的动态（按发生时间倒序）
+// 查看者是这些用户的好友，因此好友可见和公开的动态都可见，分组可见的动态要求分组中包含查看者
+func (r *activityRepository) FindFeed(ctx context.Context, actorIDs, circleIDs []uint64, cursor *entity.FeedCursor, limit int) ([]*entity.Activity, error) {
+	if len(actorIDs) == 0 {
+		return []*entity.Activity{}, nil
+	}
+
+	query := r.db.WithContext(ctx).Where("actor_id IN ?", actorIDs)
+
+	visibilities := []string{entity.RecordVisibilityPublic, entity.RecordVisibilityFriends}
+	if len(circleIDs) == 0 {
+		query = query.Where("visibility IN ?", visibilities)
+	} else {
+		query = query.Where("visibility IN ? OR (visibility = ? AND circle_id IN ?)",
+			visibilities, entity.RecordVisibilityCircle, circleIDs)
+	}
+
+	if cursor != nil {
+		query = query.Where("occurred_at < ? OR (occurred_at = ? AND id < ?)",
+			cursor.OccurredAt, cursor.OccurredAt, cursor.ID)
+	}
+
+	var activityModels []model.Activity
+	if err := query.Order("occurred_at DESC, id DESC").Limit(limit).Find(&activityModels).Error; err != nil {
+		return nil, err
+	}
+
+	activities := make([]*entity.Activity, len(activityModels))
+	for i := range activityModels {
+		activities[i] = activityModels[i].ToEntity()
+	}
+	return activities, nil
+}
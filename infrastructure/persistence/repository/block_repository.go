@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// blockRepository 屏蔽关系仓储实现
+type blockRepository struct {
+	db *gorm.DB
+}
+
+// NewBlockRepository 创建屏蔽关系仓储
+func NewBlockRepository(db *gorm.DB) repository.BlockRepository {
+	return &blockRepository{db: db}
+}
+
+// blockedUserIDs 与用户存在屏蔽关系的用户ID子查询（双向），搜索用户和好友申请共用
+func blockedUserIDs(db *gorm.DB, userID uint64) *gorm.DB {
+	return db.Raw("SELECT blocked_id FROM user_blocks WHERE user_id = ? UNION SELECT user_id FROM user_blocks WHERE blocked_id = ?", userID, userID)
+}
+
+// Save 保存屏蔽关系，同时删除双方之间任意方向的好友关系
+func (r *blockRepository) Save(ctx context.Context, block *entity.Block) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var blockModel model.Block
+		blockModel.FromEntity(block)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&blockModel).Error; err != nil {
+			return err
+		}
+		block.ID = blockModel.ID
+		block.CreatedAt = blockModel.CreatedAt
+
+		return tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+			block.UserID, block.BlockedID, block.BlockedID, block.UserID).
+			Delete(&model.Friend{}).Error
+	})
+}
+
+// Delete 解除屏蔽
+func (r *blockRepository) Delete(ctx context.Context, userID, blockedID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND blocked_id = ?", userID, blockedID).Delete(&model.Block{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByUserID 分页查询用户屏蔽的人
+func (r *blockRepository) FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Block, int64, error) {
+	var blockModels []model.Block
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Block{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("id DESC").Offset(offset).Limit(size).Find(&blockModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	blockedIDs := make([]uint64, len(blockModels))
+	for i, blockModel := range blockModels {
+		blockedIDs[i] = blockModel.BlockedID
+	}
+	var userModels []model.User
+	if len(blockedIDs) > 0 {
+		if err := r.db.WithContext(ctx).Where("id IN ?", blockedIDs).Find(&userModels).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+	users := make(map[uint64]*entity.User, len(userModels))
+	for _, userModel := range userModels {
+		users[userModel.ID] = userModel.ToEntity()
+	}
+
+	blocks := make([]*entity.Block, len(blockModels))
+	for i, blockModel := range blockModels {
+		blocks[i] = blockModel.ToEntity()
+		blocks[i].BlockedUser = users[blockModel.BlockedID]
+	}
+	return blocks, total, nil
+}
+
+// IsBlocked 判断两个用户之间是否有任意一方屏蔽了另一方
+func (r *blockRepository) IsBlocked(ctx context.Context, userID, otherID uint64) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Block{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindBlockedIDs 获取与用户存在屏蔽关系的用户ID
+func (r *blockRepository) FindBlockedIDs(ctx context.Context, userID uint64) ([]uint64, error) {
+	var ids []uint64
+	if err := blockedUserIDs(r.db.WithContext(ctx), userID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// challengeRepository 好友挑战仓储实现
+type challengeRepository struct {
+	db *gorm.DB
+}
+
+// NewChallengeRepository 创建好友挑战仓储
+func NewChallengeRepository(db *gorm.DB) repository.ChallengeRepository {
+	return &challengeRepository{db: db}
+}
+
+// FindByID 根据ID查找挑战
+func (r *challengeRepository) FindByID(ctx context.Context, id uint64) (*entity.Challenge, error) {
+	var challengeModel model.Challenge
+	if err := r.db.WithContext(ctx).First(&challengeModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return challengeModel.ToEntity(), nil
+}
+
+// Create 在同一事务中保存挑战和参与者
+func (r *challengeRepository) Create(ctx context.Context, challenge *entity.Challenge, participants []*entity.ChallengeParticipant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var challengeModel model.Challenge
+		challengeModel.FromEntity(challenge)
+		if err := tx.Create(&challengeModel).Error; err != nil {
+			return err
+		}
+
+		participantModels := make([]model.ChallengeParticipant, len(participants))
+		for i, participant := range participants {
+			participant.ChallengeID = challengeModel.ID
+			participantModels[i].FromEntity(participant)
+		}
+		if len(participantModels) > 0 {
+			if err := tx.Create(&participantModels).Error; err != nil {
+				return err
+			}
+		}
+
+		challenge.ID = challengeModel.ID
+		challenge.CreatedAt = challengeModel.CreatedAt
+		challenge.UpdatedAt = challengeModel.UpdatedAt
+		for i, participant := range participants {
+			participant.ID = participantModels[i].ID
+			participant.CreatedAt = participantModels[i].CreatedAt
+		}
+		return nil
+	})
+}
+
+// FindByParticipant 分页查询用户参与的挑战
+func (r *challengeRepository) FindByParticipant(ctx context.Context, userID uint64, settled bool, page, size int) ([]*entity.Challenge, int64, error) {
+	query := r.db.WithContext(ctx).Table("challenges AS c").
+		Joins("JOIN challenge_participants AS p ON p.challenge_id = c.id").
+		Where("p.user_id = ?", userID)
+	order := "c.end_at ASC, c.id ASC"
+	if settled {
+		query = query.Where("c.status = ? AND p.status = ?", entity.ChallengeStatusSettled, entity.ChallengeParticipantJoined)
+		order = "c.end_at DESC, c.id DESC"
+	} else {
+		query = query.Where("c.status = ? AND p.status IN ?", entity.ChallengeStatusActive,
+			[]string{entity.ChallengeParticipantInvited, entity.ChallengeParticipantJoined})
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		model.Challenge
+		MyStatus    string
+		MyFinalRank uint64
+	}
+	if err := query.Select("c.*, p.status AS my_status, p.final_rank AS my_final_rank").
+		Order(order).
+		Offset((page - 1) * size).
+		Limit(size).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	challenges := make([]*entity.Challenge, len(rows))
+	for i := range rows {
+		challenges[i] = rows[i].Challenge.ToEntity()
+		challenges[i].MyStatus = rows[i].MyStatus
+		challenges[i].MyFinalRank = rows[i].MyFinalRank
+	}
+	return challenges, total, nil
+}
+
+// FindDueActive 查找已到结束时间但尚未结算的挑战
+func (r *challengeRepository) FindDueActive(ctx context.Context, now time.Time) ([]*entity.Challenge, error) {
+	var challengeModels []model.Challenge
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND end_at <= ?", entity.ChallengeStatusActive, now).
+		Order("end_at, id").
+		Find(&challengeModels).Error; err != nil {
+		return nil, err
+	}
+
+	challenges := make([]*entity.Challenge, len(challengeModels))
+	for i := range challengeModels {
+		challenges[i] = challengeModels[i].ToEntity()
+	}
+	return challenges, nil
+}
+
+// Settle 保存结算结果，按状态条件更新挑战，避免多个实例重复结算
+func (r *challengeRepository) Settle(ctx context.Context, challenge *entity.Challenge, participants []*entity.ChallengeParticipant) (bool, error) {
+	settled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Challenge{}).
+			Where("id = ? AND status = ?", challenge.ID, entity.ChallengeStatusActive).
+			Updates(map[string]interface{}{
+				"status":     challenge.Status,
+				"settled_at": challenge.SettledAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		for _, participant := range participants {
+			if err := tx.Model(&model.ChallengeParticipant{}).
+				Where("id = ?", participant.ID).
+				Updates(map[string]interface{}{
+					"final_rank": participant.FinalRank,
+					"score":      participant.Score,
+					"winner":     participant.Winner,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		settled = true
+		return nil
+	})
+	return settled, err
+}
+
+// FindParticipant 查询用户在挑战中的参与信息
+func (r *challengeRepository) FindParticipant(ctx context.Context, challengeID, userID uint64) (*entity.ChallengeParticipant, error) {
+	var participantModel model.ChallengeParticipant
+	if err := r.db.WithContext(ctx).
+		Where("challenge_id = ? AND user_id = ?", challengeID, userID).
+		First(&participantModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return participantModel.ToEntity(), nil
+}
+
+// FindParticipants 查询挑战的全部参与者
+func (r *challengeRepository) FindParticipants(ctx context.Context, challengeID uint64) ([]*entity.ChallengeParticipant, error) {
+	var participantModels []model.ChallengeParticipant
+	if err := r.db.WithContext(ctx).Where("challenge_id = ?", challengeID).Order("id").Find(&participantModels).Error; err != nil {
+		return nil, err
+	}
+
+	participants := make([]*entity.ChallengeParticipant, len(participantModels))
+	for i := range participantModels {
+		participants[i] = participantModels[i].ToEntity()
+	}
+	return participants, nil
+}
+
+// Respond 回应挑战邀请
+func (r *challengeRepository) Respond(ctx context.Context, challengeID, userID uint64, status string, respondedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.ChallengeParticipant{}).
+		Where("challenge_id = ? AND user_id = ? AND status = ?", challengeID, userID, entity.ChallengeParticipantInvited).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"responded_at": respondedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// circleRepository 好友分组仓储实现
+type circleRepository struct {
+	db *gorm.DB
+}
+
+// NewCircleRepository 创建好友分组仓储
+func NewCircleRepository(db *gorm.DB) repository.CircleRepository {
+	return &circleRepository{db: db}
+}
+
+// FindByID 通过ID查找分组
+func (r *circleRepository) FindByID(ctx context.Context, id uint64) (*entity.Circle, error) {
+	var circleModel model.Circle
+	if err := r.db.WithContext(ctx).First(&circleModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return circleModel.ToEntity(), nil
+}
+
+// FindByUserID 获取用户创建的分组
+// 成员数只统计仍是好友的成员，解除好友后留在分组中的记录不计入
+func (r *circleRepository) FindByUserID(ctx context.Context, userID uint64) ([]*entity.Circle, error) {
+	var circleModels []model.Circle
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("sort_order ASC, id ASC").Find(&circleModels).Error; err != nil {
+		return nil, err
+	}
+	if len(circleModels) == 0 {
+		return []*entity.Circle{}, nil
+	}
+
+	circleIDs := make([]uint64, len(circleModels))
+	for i, circleModel := range circleModels {
+		circleIDs[i] = circleModel.ID
+	}
+
+	var counts []struct {
+		CircleID uint64
+		Total    int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.CircleMember{}).
+		Select("circle_id, COUNT(*) AS total").
+		Where("circle_id IN ? AND member_id IN (?)", circleIDs, confirmedFriendIDs(r.db.WithContext(ctx), userID)).
+		Group("circle_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	countMap := make(map[uint64]int64, len(counts))
+	for _, count := range counts {
+		countMap[count.CircleID] = count.Total
+	}
+
+	circles := make([]*entity.Circle, len(circleModels))
+	for i, circleModel := range circleModels {
+		circles[i] = circleModel.ToEntity()
+		circles[i].MemberCount = countMap[circleModel.ID]
+	}
+	return circles, nil
+}
+
+// CountByUserID 统计用户创建的分组数
+func (r *circleRepository) CountByUserID(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Circle{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Save 保存分组
+func (r *circleRepository) Save(ctx context.Context, circle *entity.Circle) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var maxOrder *int
+		if err := tx.Model(&model.Circle{}).Where("user_id = ?", circle.UserID).
+			Select("MAX(sort_order)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		if maxOrder != nil {
+			circle.SortOrder = *maxOrder + 1
+		}
+
+		var circleModel model.Circle
+		circleModel.FromEntity(circle)
+		if err := tx.Create(&circleModel).Error; err != nil {
+			return err
+		}
+		circle.ID = circleModel.ID
+		circle.CreatedAt = circleModel.CreatedAt
+		circle.UpdatedAt = circleModel.UpdatedAt
+		return nil
+	})
+}
+
+// Update 更新分组名称
+func (r *circleRepository) Update(ctx context.Context, circle *entity.Circle) error {
+	return r.db.WithContext(ctx).Model(&model.Circle{}).Where("id = ?", circle.ID).Updates(map[string]interface{}{
+		"name": circle.Name,
+	}).Error
+}
+
+// Delete 删除分组及其成员
+func (r *circleRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("circle_id = ?", id).Delete(&model.CircleMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Circle{}, id).Error
+	})
+}
+
+// Reorder 按给定顺序重排用户的分组
+func (r *circleRepository) Reorder(ctx context.Context, userID uint64, circleIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, circleID := range circleIDs {
+			result := tx.Model(&model.Circle{}).Where("id = ? AND user_id = ?", circleID, userID).Update("sort_order", i)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+}
+
+// AddMembers 添加分组成员
+func (r *circleRepository) AddMembers(ctx context.Context, circleID uint64, memberIDs []uint64) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+
+	memberModels := make([]model.CircleMember, len(memberIDs))
+	for i, memberID := range memberIDs {
+		memberModels[i] = model.CircleMember{CircleID: circleID, MemberID: memberID}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&memberModels).Error
+}
+
+// RemoveMember 移除分组成员
+func (r *circleRepository) RemoveMember(ctx context.Context, circleID, memberID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Where("circle_id = ? AND member_id = ?", circleID, memberID).Delete(&model.CircleMember{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindIDsContainingMember 查找指定用户创建的、包含某个成员的分组ID
+func (r *circleRepository) FindIDsContainingMember(ctx context.Context, ownerIDs []uint64, memberID uint64) (map[uint64][]uint64, error) {
+	result := make(map[uint64][]uint64)
+	if len(ownerIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		ID     uint64
+		UserID uint64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Circle{}).
+		Select("friend_circles.id, friend_circles.user_id").
+		Joins("JOIN friend_circle_members AS m ON m.circle_id = friend_circles.id").
+		Where("friend_circles.user_id IN ? AND m.member_id = ?", ownerIDs, memberID).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.UserID] = append(result[row.UserID], row.ID)
+	}
+	return result, nil
+}
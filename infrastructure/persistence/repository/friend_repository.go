@@ -55,13 +55,21 @@ func (r *friendRepository) FindByUserID(ctx context.Context, userID uint64) ([]*
 }
 
 // FindByUserIDWithPagination 分页查询用户的好友列表
-func (r *friendRepository) FindByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string) ([]*entity.Friend, int64, error) {
+// circleID 大于0时只查询该分组中的好友
+func (r *friendRepository) FindByUserIDWithPagination(ctx context.Context, userID uint64, page, size int, keyword string, circleID uint64) ([]*entity.Friend, int64, error) {
 	var friendModels []model.Friend
 	var total int64
 
 	// 构建查询 - 同时查询user_id和friend_id字段
 	query := r.db.WithContext(ctx).Model(&model.Friend{}).Where("(user_id = ? OR friend_id = ?) AND status = ?", userID, userID, 1)
 
+	// 按分组筛选
+	if circleID > 0 {
+		members := circleMemberIDs(r.db.WithContext(ctx), circleID)
+		query = query.Where("(user_id IN (?) AND friend_id = ?) OR (friend_id IN (?) AND user_id = ?)",
+			members, userID, members, userID)
+	}
+
 	// 计算总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -140,36 +148,54 @@ func (r *friendRepository) FindFriendIDs(ctx context.Context, userID uint64) ([]
 	return friendIDs, nil
 }
 
+// FindAllFriendIDs 获取所有用户的好友ID列表，每条已确认的关系同时计入双方
+func (r *friendRepository) FindAllFriendIDs(ctx context.Context) (map[uint64][]uint64, error) {
+	var pairs []struct {
+		UserID   uint64
+		FriendID uint64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Friend{}).
+		Select("user_id, friend_id").
+		Where("status = ?", 1).
+		Scan(&pairs).Error; err != nil {
+		return nil, err
+	}
+
+	friendIDs := make(map[uint64][]uint64)
+	for _, pair := range pairs {
+		friendIDs[pair.UserID] = append(friendIDs[pair.UserID], pair.FriendID)
+		friendIDs[pair.FriendID] = append(friendIDs[pair.FriendID], pair.UserID)
+	}
+	return friendIDs, nil
+}
+
 // Save 保存好友关系
 func (r *friendRepository) Save(ctx context.Context, friend *entity.Friend) error {
 	// 开启事务
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 先检查是否已存在好友关系
-		var existingFriend model.Friend
-		err := tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
-			friend.UserID, friend.FriendID, friend.FriendID, friend.UserID).First(&existingFriend).Error
-
-		if err == nil {
-			// 已存在关系，根据状态处理
-			if existingFriend.Status == 1 {
-				return errors.New("已经是好友关系")
-			} else if existingFriend.Status == 0 {
-				// 如果是待确认状态，可以更新为已确认
-				if existingFriend.UserID == friend.FriendID && existingFriend.FriendID == friend.UserID {
-					// 对方发起的申请，我方同意
-					existingFriend.Status = 1
-					return tx.Model(&existingFriend).Update("status", 1).Error
-				} else {
-					return errors.New("已发送过好友申请，等待对方确认")
-				}
-			} else if existingFriend.Status == 2 {
-				// 如果是已拒绝状态，可以重新申请
-				existingFriend.Status = 0
-				return tx.Model(&existingFriend).Update("status", 0).Error
-			}
-		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		// 任意一方屏蔽了另一方时不能发送好友申请
+		var blocked int64
+		if err := tx.Model(&model.Block{}).
+			Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)",
+				friend.UserID, friend.FriendID, friend.FriendID, friend.UserID).
+			Count(&blocked).Error; err != nil {
 			return err
 		}
+		if blocked > 0 {
+			return errors.New("无法向该用户发送好友申请")
+		}
+
+		// 两个用户之间只保留一条关系记录，已存在时由服务层按状态机变更
+		var existing int64
+		if err := tx.Model(&model.Friend{}).
+			Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+				friend.UserID, friend.FriendID, friend.FriendID, friend.UserID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errors.New("好友关系已存在")
+		}
 
 		// 不存在关系，创建新的
 		var friendModel model.Friend
@@ -183,20 +209,30 @@ func (r *friendRepository) Save(ctx context.Context, friend *entity.Friend) erro
 }
 
 // Update 更新好友状态
-func (r *friendRepository) Update(ctx context.Context, friend *entity.Friend) error {
+// 以变更前的状态作为条件，并发的撤回、同意等请求只有一个能生效
+func (r *friendRepository) Update(ctx context.Context, friend *entity.Friend, from entity.FriendStatus) (bool, error) {
+	var updated bool
 	// 开启事务
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 更新状态
-		result := tx.Model(&model.Friend{}).Where("id = ?", friend.ID).Updates(map[string]interface{}{
-			"status":     friend.Status,
-			"updated_at": time.Now(),
+		result := tx.Model(&model.Friend{}).Where("id = ? AND status = ?", friend.ID, from).Updates(map[string]interface{}{
+			"user_id":      friend.UserID,
+			"friend_id":    friend.FriendID,
+			"status":       friend.Status,
+			"requested_at": friend.RequestedAt,
+			"accepted_at":  friend.AcceptedAt,
+			"rejected_at":  friend.RejectedAt,
+			"removed_at":   friend.RemovedAt,
+			"updated_at":   time.Now(),
 		})
 		if result.Error != nil {
 			return result.Error
 		}
 
+		updated = result.RowsAffected > 0
 		return nil
 	})
+	return updated, err
 }
 
 // Delete 删除好友关系
@@ -291,3 +327,129 @@ func (r *friendRepository) FindByID(ctx context.Context, id uint64) (*entity.Fri
 
 	return friendModel.ToEntity(), nil
 }
+
+// FindSentRequestsByUserID 查询用户发出的待确认好友申请
+func (r *friendRepository) FindSentRequestsByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Friend, int64, error) {
+	var friendModels []model.Friend
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Friend{}).
+		Where("user_id = ? AND status = ?", userID, 0) // status=0 表示待确认状态
+
+	// 计算总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * size
+	if err := query.Order("updated_at DESC").Offset(offset).Limit(size).Find(&friendModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 转换为实体
+	friends := make([]*entity.Friend, len(friendModels))
+	for i, friendModel := range friendModels {
+		friends[i] = friendModel.ToEntity()
+
+		// 查询接收者的用户信息
+		var userModel model.User
+		if err := r.db.WithContext(ctx).First(&userModel, friendModel.FriendID).Error; err == nil {
+			friends[i].FriendUser = userModel.ToEntity()
+		}
+	}
+
+	return friends, total, nil
+}
+
+// CountPendingSent 统计用户发出的待确认好友申请数量
+func (r *friendRepository) CountPendingSent(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Friend{}).
+		Where("user_id = ? AND status = ?", userID, 0).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteExpiredRequests 删除过期的待确认好友申请
+// 按最近一次发起申请的时间判断是否过期，其他字段的更新不影响有效期；没有申请时间的旧数据按创建时间判断
+func (r *friendRepository) DeleteExpiredRequests(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("status = ? AND COALESCE(requested_at, created_at) < ?", 0, before).Delete(&model.Friend{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// confirmedFriendIDs 用户已确认好友ID的子查询（双向）
+func confirmedFriendIDs(db *gorm.DB, userID uint64) *gorm.DB {
+	return db.Raw("SELECT friend_id AS id FROM friends WHERE user_id = ? AND status = 1 UNION SELECT user_id FROM friends WHERE friend_id = ? AND status = 1", userID, userID)
+}
+
+// circleMemberIDs 分组成员ID的子查询
+func circleMemberIDs(db *gorm.DB, circleID uint64) *gorm.DB {
+	return db.Raw("SELECT member_id FROM friend_circle_members WHERE circle_id = ?", circleID)
+}
+
+// relatedUserIDs 与用户存在任意状态好友关系的用户ID子查询（双向）
+func relatedUserIDs(db *gorm.DB, userID uint64) *gorm.DB {
+	return db.Raw("SELECT friend_id AS id FROM friends WHERE user_id = ? UNION SELECT user_id FROM friends WHERE friend_id = ?", userID, userID)
+}
+
+// FindMutualFriendIDs 获取两个用户的共同好友ID
+func (r *friendRepository) FindMutualFriendIDs(ctx context.Context, userID, otherID uint64) ([]uint64, error) {
+	var ids []uint64
+	if err := r.db.WithContext(ctx).
+		Raw("SELECT a.id FROM (?) AS a JOIN (?) AS b ON b.id = a.id ORDER BY a.id",
+			confirmedFriendIDs(r.db.WithContext(ctx), userID), confirmedFriendIDs(r.db.WithContext(ctx), otherID)).
+		Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindRelatedUserIDs 获取与用户存在任意状态好友关系的用户ID
+func (r *friendRepository) FindRelatedUserIDs(ctx context.Context, userID uint64) ([]uint64, error) {
+	var ids []uint64
+	if err := relatedUserIDs(r.db.WithContext(ctx), userID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindSuggestionCandidates 获取与用户有共同好友的非好友用户及共同好友数
+// 将好友关系展开为双向的边，从用户的好友出发走一步即为好友的好友
+func (r *friendRepository) FindSuggestionCandidates(ctx context.Context, userID uint64, limit int) ([]*entity.MutualFriendCount, error) {
+	edges := r.db.WithContext(ctx).Raw("SELECT user_id AS id, friend_id AS other_id FROM friends WHERE status = 1 " +
+		"UNION ALL SELECT friend_id, user_id FROM friends WHERE status = 1")
+
+	var candidates []*entity.MutualFriendCount
+	if err := r.db.WithContext(ctx).
+		Raw("SELECT e.other_id AS user_id, COUNT(*) AS mutual_count "+
+			"FROM (?) AS e "+
+			"JOIN (?) AS mf ON mf.id = e.id "+
+			"JOIN users AS u ON u.id = e.other_id AND u.status = 1 "+
+			"WHERE e.other_id <> ? AND e.other_id NOT IN (?) AND e.other_id NOT IN (?) "+
+			"GROUP BY e.other_id "+
+			"ORDER BY mutual_count DESC, e.other_id ASC "+
+			"LIMIT ?",
+			edges, confirmedFriendIDs(r.db.WithContext(ctx), userID), userID, relatedUserIDs(r.db.WithContext(ctx), userID), blockedUserIDs(r.db.WithContext(ctx), userID), limit).
+		Scan(&candidates).Error; err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// FindCircleFriendIDs 获取分组中仍是用户好友的成员ID
+func (r *friendRepository) FindCircleFriendIDs(ctx context.Context, userID, circleID uint64) ([]uint64, error) {
+	var ids []uint64
+	if err := r.db.WithContext(ctx).
+		Raw("SELECT f.id FROM (?) AS f WHERE f.id IN (?) ORDER BY f.id",
+			confirmedFriendIDs(r.db.WithContext(ctx), userID), circleMemberIDs(r.db.WithContext(ctx), circleID)).
+		Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// goalRepository 个人目标仓储实现
+type goalRepository struct {
+	db *gorm.DB
+}
+
+// NewGoalRepository 创建个人目标仓储
+func NewGoalRepository(db *gorm.DB) repository.GoalRepository {
+	return &goalRepository{db: db}
+}
+
+// FindByID 根据ID查找目标
+func (r *goalRepository) FindByID(ctx context.Context, id uint64) (*entity.Goal, error) {
+	var goalModel model.Goal
+	if err := r.db.WithContext(ctx).First(&goalModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return goalModel.ToEntity(), nil
+}
+
+// FindByUserID 查找用户的目标列表
+func (r *goalRepository) FindByUserID(ctx context.Context, userID uint64, includeArchived bool) ([]*entity.Goal, error) {
+	var goalModels []model.Goal
+
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if !includeArchived {
+		query = query.Where("status = ?", entity.GoalStatusActive)
+	}
+
+	if err := query.Order("id ASC").Find(&goalModels).Error; err != nil {
+		return nil, err
+	}
+
+	goals := make([]*entity.Goal, len(goalModels))
+	for i, goalModel := range goalModels {
+		goals[i] = goalModel.ToEntity()
+	}
+	return goals, nil
+}
+
+// FindAllActive 获取所有进行中的目标
+func (r *goalRepository) FindAllActive(ctx context.Context) ([]*entity.Goal, error) {
+	var goalModels []model.Goal
+	if err := r.db.WithContext(ctx).Where("status = ?", entity.GoalStatusActive).Order("id ASC").Find(&goalModels).Error; err != nil {
+		return nil, err
+	}
+
+	goals := make([]*entity.Goal, len(goalModels))
+	for i, goalModel := range goalModels {
+		goals[i] = goalModel.ToEntity()
+	}
+	return goals, nil
+}
+
+// Save 保存目标
+func (r *goalRepository) Save(ctx context.Context, goal *entity.Goal) error {
+	var goalModel model.Goal
+	goalModel.FromEntity(goal)
+	if err := r.db.WithContext(ctx).Create(&goalModel).Error; err != nil {
+		return err
+	}
+	goal.ID = goalModel.ID
+	goal.CreatedAt = goalModel.CreatedAt
+	goal.UpdatedAt = goalModel.UpdatedAt
+	return nil
+}
+
+// Update 更新目标
+func (r *goalRepository) Update(ctx context.Context, goal *entity.Goal) error {
+	return r.db.WithContext(ctx).Model(&model.Goal{}).Where("id = ?", goal.ID).Updates(map[string]interface{}{
+		"name":       goal.Name,
+		"target":     goal.Target,
+		"status":     goal.Status,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// Delete 删除目标及其完成记录
+func (r *goalRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", id).Delete(&model.GoalCompletion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Goal{}, id).Error
+	})
+}
+
+// goalCompletionRepository 目标完成记录仓储实现
+type goalCompletionRepository struct {
+	db *gorm.DB
+}
+
+// NewGoalCompletionRepository 创建目标完成记录仓储
+func NewGoalCompletionRepository(db *gorm.DB) repository.GoalCompletionRepository {
+	return &goalCompletionRepository{db: db}
+}
+
+// FindByGoalAndPeriod 查找目标在某个周期的完成记录
+func (r *goalCompletionRepository) FindByGoalAndPeriod(ctx context.Context, goalID uint64, periodStart time.Time) (*entity.GoalCompletion, error) {
+	var completionModel model.GoalCompletion
+	err := r.db.WithContext(ctx).Where("goal_id = ? AND period_start = ?", goalID, periodStart).First(&completionModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return completionModel.ToEntity(), nil
+}
+
+// FindByGoalID 分页查询目标的完成记录
+func (r *goalCompletionRepository) FindByGoalID(ctx context.Context, goalID uint64, page, size int) ([]*entity.GoalCompletion, int64, error) {
+	var completionModels []model.GoalCompletion
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.GoalCompletion{}).Where("goal_id = ?", goalID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("period_start DESC").Offset(offset).Limit(size).Find(&completionModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	completions := make([]*entity.GoalCompletion, len(completionModels))
+	for i, completionModel := range completionModels {
+		completions[i] = completionModel.ToEntity()
+	}
+	return completions, total, nil
+}
+
+// FindLatestSettled 查找目标最近一次已结算的完成记录
+func (r *goalCompletionRepository) FindLatestSettled(ctx context.Context, goalID uint64) (*entity.GoalCompletion, error) {
+	var completionModel model.GoalCompletion
+	err := r.db.WithContext(ctx).Where("goal_id = ? AND settled = ?", goalID, true).
+		Order("period_start DESC").First(&completionModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return completionModel.ToEntity(), nil
+}
+
+// Save 保存完成记录
+func (r *goalCompletionRepository) Save(ctx context.Context, completion *entity.GoalCompletion) error {
+	var completionModel model.GoalCompletion
+	completionModel.FromEntity(completion)
+	if err := r.db.WithContext(ctx).Create(&completionModel).Error; err != nil {
+		return err
+	}
+	completion.ID = completionModel.ID
+	return nil
+}
+
+// Update 更新完成记录
+func (r *goalCompletionRepository) Update(ctx context.Context, completion *entity.GoalCompletion) error {
+	return r.db.WithContext(ctx).Model(&model.GoalCompletion{}).Where("id = ?", completion.ID).Updates(map[string]interface{}{
+		"value":      completion.Value,
+		"met":        completion.Met,
+		"met_at":     completion.MetAt,
+		"settled":    completion.Settled,
+		"updated_at": time.Now(),
+	}).Error
+}
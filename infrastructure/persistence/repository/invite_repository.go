@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// inviteCodeRepository 好友邀请码仓储实现
+type inviteCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewInviteCodeRepository 创建好友邀请码仓储
+func NewInviteCodeRepository(db *gorm.DB) repository.InviteCodeRepository {
+	return &inviteCodeRepository{db: db}
+}
+
+// FindActiveByUserID 查找用户当前有效的邀请码
+func (r *inviteCodeRepository) FindActiveByUserID(ctx context.Context, userID uint64) (*entity.InviteCode, error) {
+	return r.findActive(r.db.WithContext(ctx).Where("user_id = ?", userID))
+}
+
+// FindActiveByCode 根据邀请码查找
+func (r *inviteCodeRepository) FindActiveByCode(ctx context.Context, code string) (*entity.InviteCode, error) {
+	return r.findActive(r.db.WithContext(ctx).Where("code = ?", code))
+}
+
+// findActive 按条件查找未失效的邀请码
+func (r *inviteCodeRepository) findActive(query *gorm.DB) (*entity.InviteCode, error) {
+	var codeModel model.InviteCode
+	if err := query.Where("revoked_at IS NULL").Order("id DESC").First(&codeModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return codeModel.ToEntity(), nil
+}
+
+// Rotate 使用户当前的邀请码失效并保存新的邀请码
+func (r *inviteCodeRepository) Rotate(ctx context.Context, code *entity.InviteCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.InviteCode{}).
+			Where("user_id = ? AND revoked_at IS NULL", code.UserID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		var codeModel model.InviteCode
+		codeModel.FromEntity(code)
+		if err := tx.Create(&codeModel).Error; err != nil {
+			return err
+		}
+		code.ID = codeModel.ID
+		code.CreatedAt = codeModel.CreatedAt
+		return nil
+	})
+}
+
+// inviteRedemptionRepository 邀请记录仓储实现
+type inviteRedemptionRepository struct {
+	db *gorm.DB
+}
+
+// NewInviteRedemptionRepository 创建邀请记录仓储
+func NewInviteRedemptionRepository(db *gorm.DB) repository.InviteRedemptionRepository {
+	return &inviteRedemptionRepository{db: db}
+}
+
+// Save 保存邀请记录，已存在时忽略
+func (r *inviteRedemptionRepository) Save(ctx context.Context, redemption *entity.InviteRedemption) (bool, error) {
+	var redemptionModel model.InviteRedemption
+	redemptionModel.FromEntity(redemption)
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&redemptionModel)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	redemption.ID = redemptionModel.ID
+	redemption.CreatedAt = redemptionModel.CreatedAt
+	return result.RowsAffected > 0, nil
+}
+
+// CountByInviterID 统计邀请人邀请成功的人数及其中的新用户人数
+func (r *inviteRedemptionRepository) CountByInviterID(ctx context.Context, inviterID uint64) (int64, int64, error) {
+	var row struct {
+		Total    int64
+		NewUsers int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.InviteRedemption{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(new_user), 0) AS new_users").
+		Where("inviter_id = ?", inviterID).
+		Scan(&row).Error; err != nil {
+		return 0, 0, err
+	}
+	return row.Total, row.NewUsers, nil
+}
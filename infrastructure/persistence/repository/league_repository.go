@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// leagueRepository 联赛赛季仓储实现
+type leagueRepository struct {
+	db *gorm.DB
+}
+
+// NewLeagueRepository 创建联赛赛季仓储
+func NewLeagueRepository(db *gorm.DB) repository.LeagueRepository {
+	return &leagueRepository{db: db}
+}
+
+// FindByID 根据ID查找赛季
+func (r *leagueRepository) FindByID(ctx context.Context, id uint64) (*entity.League, error) {
+	var leagueModel model.League
+	if err := r.db.WithContext(ctx).First(&leagueModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return leagueModel.ToEntity(), nil
+}
+
+// FindByIDs 批量查找赛季
+func (r *leagueRepository) FindByIDs(ctx context.Context, ids []uint64) (map[uint64]*entity.League, error) {
+	leagues := make(map[uint64]*entity.League, len(ids))
+	if len(ids) == 0 {
+		return leagues, nil
+	}
+
+	var leagueModels []model.League
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&leagueModels).Error; err != nil {
+		return nil, err
+	}
+	for _, leagueModel := range leagueModels {
+		leagues[leagueModel.ID] = leagueModel.ToEntity()
+	}
+	return leagues, nil
+}
+
+// FindByPeriodStart 查找指定周期和开始时间的赛季
+func (r *leagueRepository) FindByPeriodStart(ctx context.Context, period string, startAt time.Time) (*entity.League, error) {
+	var leagueModel model.League
+	err := r.db.WithContext(ctx).Where("period = ? AND start_at = ?", period, startAt).First(&leagueModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return leagueModel.ToEntity(), nil
+}
+
+// FindDueActive 查找已到结束时间但尚未结算的赛季
+func (r *leagueRepository) FindDueActive(ctx context.Context, period string, now time.Time) ([]*entity.League, error) {
+	var leagueModels []model.League
+	if err := r.db.WithContext(ctx).
+		Where("period = ? AND status = ? AND end_at <= ?", period, entity.LeagueStatusActive, now).
+		Order("start_at ASC").
+		Find(&leagueModels).Error; err != nil {
+		return nil, err
+	}
+
+	leagues := make([]*entity.League, len(leagueModels))
+	for i, leagueModel := range leagueModels {
+		leagues[i] = leagueModel.ToEntity()
+	}
+	return leagues, nil
+}
+
+// FindLatestClosed 查找最近一个已结算的赛季
+func (r *leagueRepository) FindLatestClosed(ctx context.Context, period string) (*entity.League, error) {
+	var leagueModel model.League
+	err := r.db.WithContext(ctx).Where("period = ? AND status = ?", period, entity.LeagueStatusClosed).
+		Order("start_at DESC").First(&leagueModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return leagueModel.ToEntity(), nil
+}
+
+// CreateSeason 在同一事务中保存赛季、分组及分组成员
+func (r *leagueRepository) CreateSeason(ctx context.Context, league *entity.League, divisions []*entity.LeagueDivision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var leagueModel model.League
+		leagueModel.FromEntity(league)
+		if err := tx.Create(&leagueModel).Error; err != nil {
+			return err
+		}
+		league.ID = leagueModel.ID
+		league.CreatedAt = leagueModel.CreatedAt
+		league.UpdatedAt = leagueModel.UpdatedAt
+
+		for _, division := range divisions {
+			division.LeagueID = league.ID
+			var divisionModel model.LeagueDivision
+			divisionModel.FromEntity(division)
+			if err := tx.Create(&divisionModel).Error; err != nil {
+				return err
+			}
+			division.ID = divisionModel.ID
+			division.CreatedAt = divisionModel.CreatedAt
+
+			if len(division.Members) == 0 {
+				continue
+			}
+			membershipModels := make([]model.LeagueMembership, len(division.Members))
+			for i, membership := range division.Members {
+				membership.LeagueID = league.ID
+				membership.DivisionID = division.ID
+				membership.Tier = division.Tier
+				membershipModels[i].FromEntity(membership)
+			}
+			if err := tx.Create(&membershipModels).Error; err != nil {
+				return err
+			}
+			for i, membership := range division.Members {
+				membership.ID = membershipModels[i].ID
+			}
+		}
+		return nil
+	})
+}
+
+// Update 更新赛季状态
+func (r *leagueRepository) Update(ctx context.Context, league *entity.League) error {
+	return r.db.WithContext(ctx).Model(&model.League{}).Where("id = ?", league.ID).Updates(map[string]interface{}{
+		"status":     league.Status,
+		"closed_at":  league.ClosedAt,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// leagueDivisionRepository 联赛分组仓储实现
+type leagueDivisionRepository struct {
+	db *gorm.DB
+}
+
+// NewLeagueDivisionRepository 创建联赛分组仓储
+func NewLeagueDivisionRepository(db *gorm.DB) repository.LeagueDivisionRepository {
+	return &leagueDivisionRepository{db: db}
+}
+
+// FindByID 根据ID查找分组
+func (r *leagueDivisionRepository) FindByID(ctx context.Context, id uint64) (*entity.LeagueDivision, error) {
+	var divisionModel model.LeagueDivision
+	if err := r.db.WithContext(ctx).First(&divisionModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return divisionModel.ToEntity(), nil
+}
+
+// FindByLeagueID 查找赛季的所有分组
+func (r *leagueDivisionRepository) FindByLeagueID(ctx context.Context, leagueID uint64) ([]*entity.LeagueDivision, error) {
+	var divisionModels []model.LeagueDivision
+	if err := r.db.WithContext(ctx).Where("league_id = ?", leagueID).Order("id ASC").Find(&divisionModels).Error; err != nil {
+		return nil, err
+	}
+
+	divisions := make([]*entity.LeagueDivision, len(divisionModels))
+	for i, divisionModel := range divisionModels {
+		divisions[i] = divisionModel.ToEntity()
+	}
+	return divisions, nil
+}
+
+// Save 保存分组
+func (r *leagueDivisionRepository) Save(ctx context.Context, division *entity.LeagueDivision) error {
+	var divisionModel model.LeagueDivision
+	divisionModel.FromEntity(division)
+	if err := r.db.WithContext(ctx).Create(&divisionModel).Error; err != nil {
+		return err
+	}
+	division.ID = divisionModel.ID
+	division.TierName = entity.LeagueTierName(division.Tier)
+	division.CreatedAt = divisionModel.CreatedAt
+	return nil
+}
+
+// leagueMembershipRepository 联赛参赛信息仓储实现
+type leagueMembershipRepository struct {
+	db *gorm.DB
+}
+
+// NewLeagueMembershipRepository 创建联赛参赛信息仓储
+func NewLeagueMembershipRepository(db *gorm.DB) repository.LeagueMembershipRepository {
+	return &leagueMembershipRepository{db: db}
+}
+
+// FindByLeagueAndUser 查找用户在某个赛季的参赛信息
+func (r *leagueMembershipRepository) FindByLeagueAndUser(ctx context.Context, leagueID, userID uint64) (*entity.LeagueMembership, error) {
+	var membershipModel model.LeagueMembership
+	err := r.db.WithContext(ctx).Where("league_id = ? AND user_id = ?", leagueID, userID).First(&membershipModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return membershipModel.ToEntity(), nil
+}
+
+// FindByLeagueID 查找赛季的所有参赛信息
+func (r *leagueMembershipRepository) FindByLeagueID(ctx context.Context, leagueID uint64) ([]*entity.LeagueMembership, error) {
+	return r.find(ctx, r.db.WithContext(ctx).Where("league_id = ?", leagueID))
+}
+
+// FindByDivisionID 查找分组的所有参赛信息
+func (r *leagueMembershipRepository) FindByDivisionID(ctx context.Context, divisionID uint64) ([]*entity.LeagueMembership, error) {
+	return r.find(ctx, r.db.WithContext(ctx).Where("division_id = ?", divisionID))
+}
+
+// find 按条件查询参赛信息
+func (r *leagueMembershipRepository) find(ctx context.Context, query *gorm.DB) ([]*entity.LeagueMembership, error) {
+	var membershipModels []model.LeagueMembership
+	if err := query.Order("id ASC").Find(&membershipModels).Error; err != nil {
+		return nil, err
+	}
+
+	memberships := make([]*entity.LeagueMembership, len(membershipModels))
+	for i, membershipModel := range membershipModels {
+		memberships[i] = membershipModel.ToEntity()
+	}
+	return memberships, nil
+}
+
+// FindLatestSettledByUserID 查找用户最近一个已结算赛季的参赛信息
+func (r *leagueMembershipRepository) FindLatestSettledByUserID(ctx context.Context, userID uint64) (*entity.LeagueMembership, error) {
+	var membershipModel model.LeagueMembership
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND outcome <> ''", userID).
+		Order("league_id DESC").
+		First(&membershipModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return membershipModel.ToEntity(), nil
+}
+
+// FindByUserID 分页查询用户的参赛历史
+func (r *leagueMembershipRepository) FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.LeagueMembership, int64, error) {
+	var membershipModels []model.LeagueMembership
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.LeagueMembership{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("league_id DESC").Offset(offset).Limit(size).Find(&membershipModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	memberships := make([]*entity.LeagueMembership, len(membershipModels))
+	for i, membershipModel := range membershipModels {
+		memberships[i] = membershipModel.ToEntity()
+	}
+	return memberships, total, nil
+}
+
+// CountByDivisionIDs 统计各分组的人数
+func (r *leagueMembershipRepository) CountByDivisionIDs(ctx context.Context, divisionIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(divisionIDs))
+	if len(divisionIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		DivisionID uint64
+		Count      int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.LeagueMembership{}).
+		Select("division_id, COUNT(*) AS count").
+		Where("division_id IN ?", divisionIDs).
+		Group("division_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.DivisionID] = row.Count
+	}
+	return counts, nil
+}
+
+// Save 保存参赛信息
+func (r *leagueMembershipRepository) Save(ctx context.Context, membership *entity.LeagueMembership) error {
+	var membershipModel model.LeagueMembership
+	membershipModel.FromEntity(membership)
+	if err := r.db.WithContext(ctx).Create(&membershipModel).Error; err != nil {
+		return err
+	}
+	membership.ID = membershipModel.ID
+	membership.TierName = entity.LeagueTierName(membership.Tier)
+	membership.CreatedAt = membershipModel.CreatedAt
+	membership.UpdatedAt = membershipModel.UpdatedAt
+	return nil
+}
+
+// UpdateResults 批量保存赛季结算结果
+func (r *leagueMembershipRepository) UpdateResults(ctx context.Context, memberships []*entity.LeagueMembership) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, membership := range memberships {
+			if err := tx.Model(&model.LeagueMembership{}).Where("id = ?", membership.ID).Updates(map[string]interface{}{
+				"final_rank":   membership.FinalRank,
+				"metric_value": membership.MetricValue,
+				"outcome":      membership.Outcome,
+				"updated_at":   now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
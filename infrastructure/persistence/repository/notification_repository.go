@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// notificationRepository 站内通知仓储实现
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建站内通知仓储
+func NewNotificationRepository(db *gorm.DB) repository.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Save 保存通知
+func (r *notificationRepository) Save(ctx context.Context, notification *entity.Notification) error {
+	var notificationModel model.Notification
+	notificationModel.FromEntity(notification)
+	if err := r.db.WithContext(ctx).Create(&notificationModel).Error; err != nil {
+		return err
+	}
+
+	notification.ID = notificationModel.ID
+	notification.CreatedAt = notificationModel.CreatedAt
+	return nil
+}
+
+// FindByUserID 分页查询用户收件箱中的通知
+func (r *notificationRepository) FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Notification, int64, error) {
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notificationModels []model.Notification
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * size).Limit(size).Find(&notificationModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	notifications := make([]*entity.Notification, len(notificationModels))
+	for i := range notificationModels {
+		notifications[i] = notificationModels[i].ToEntity()
+	}
+	return notifications, total, nil
+}
+
+// CountUnread 统计用户的未读通知数
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 将用户的指定通知标记为已读，ids 为空时标记全部
+func (r *notificationRepository) MarkRead(ctx context.Context, userID uint64, ids []uint64, readAt time.Time) error {
+	query := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	return query.Update("read_at", readAt).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// nudgeRepository 戳一戳仓储实现
+type nudgeRepository struct {
+	db *gorm.DB
+}
+
+// NewNudgeRepository 创建戳一戳仓储
+func NewNudgeRepository(db *gorm.DB) repository.NudgeRepository {
+	return &nudgeRepository{db: db}
+}
+
+// Save 保存戳一戳，依赖唯一索引保证每个发送者每天对同一好友只保存一次
+func (r *nudgeRepository) Save(ctx context.Context, nudge *entity.Nudge) (bool, error) {
+	var nudgeModel model.Nudge
+	nudgeModel.FromEntity(nudge)
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&nudgeModel)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	nudge.ID = nudgeModel.ID
+	nudge.CreatedAt = nudgeModel.CreatedAt
+	return result.RowsAffected > 0, nil
+}
+
+// nudgeSettingRepository 戳一戳设置仓储实现
+type nudgeSettingRepository struct {
+	db *gorm.DB
+}
+
+// NewNudgeSettingRepository 创建戳一戳设置仓储
+func NewNudgeSettingRepository(db *gorm.DB) repository.NudgeSettingRepository {
+	return &nudgeSettingRepository{db: db}
+}
+
+// FindByUserID 查询用户的戳一戳设置
+func (r *nudgeSettingRepository) FindByUserID(ctx context.Context, userID uint64) (*entity.NudgeSetting, error) {
+	var settingModel model.NudgeSetting
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settingModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return settingModel.ToEntity(), nil
+}
+
+// Save 保存用户的戳一戳设置，已存在时覆盖
+func (r *nudgeSettingRepository) Save(ctx context.Context, setting *entity.NudgeSetting) error {
+	setting.UpdatedAt = time.Now()
+
+	var settingModel model.NudgeSetting
+	settingModel.FromEntity(setting)
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"muted", "updated_at"}),
+	}).Create(&settingModel).Error
+}
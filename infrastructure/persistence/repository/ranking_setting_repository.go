@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rankingSettingRepository 排行榜设置仓储实现
+type rankingSettingRepository struct {
+	db *gorm.DB
+}
+
+// NewRankingSettingRepository 创建排行榜设置仓储
+func NewRankingSettingRepository(db *gorm.DB) repository.RankingSettingRepository {
+	return &rankingSettingRepository{db: db}
+}
+
+// FindByUserID 查询用户的排行榜设置
+func (r *rankingSettingRepository) FindByUserID(ctx context.Context, userID uint64) (*entity.RankingSetting, error) {
+	var settingModel model.RankingSetting
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settingModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return settingModel.ToEntity(), nil
+}
+
+// FindByUserIDs 批量查询用户的排行榜设置
+func (r *rankingSettingRepository) FindByUserIDs(ctx context.Context, userIDs []uint64) (map[uint64]*entity.RankingSetting, error) {
+	result := make(map[uint64]*entity.RankingSetting)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var settingModels []model.RankingSetting
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&settingModels).Error; err != nil {
+		return nil, err
+	}
+
+	for _, settingModel := range settingModels {
+		result[settingModel.UserID] = settingModel.ToEntity()
+	}
+	return result, nil
+}
+
+// Save 保存用户的排行榜设置，已存在时覆盖
+func (r *rankingSettingRepository) Save(ctx context.Context, setting *entity.RankingSetting) error {
+	setting.UpdatedAt = time.Now()
+
+	var settingModel model.RankingSetting
+	settingModel.FromEntity(setting)
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"visibility", "updated_at"}),
+	}).Create(&settingModel).Error
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rankingSnapshotRepository 排行榜快照仓储实现
+type rankingSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewRankingSnapshotRepository 创建排行榜快照仓储
+func NewRankingSnapshotRepository(db *gorm.DB) repository.RankingSnapshotRepository {
+	return &rankingSnapshotRepository{db: db}
+}
+
+// SaveBatch 批量保存快照，重复生成时忽略已存在的数据
+func (r *rankingSnapshotRepository) SaveBatch(ctx context.Context, snapshots []*entity.RankingSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	snapshotModels := make([]model.RankingSnapshot, len(snapshots))
+	for i, snapshot := range snapshots {
+		snapshotModels[i].FromEntity(snapshot)
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&snapshotModels, 500).Error
+}
+
+// Exists 判断某个榜单在某个周期是否已生成快照
+func (r *rankingSnapshotRepository) Exists(ctx context.Context, scope string, ownerID uint64, period string, periodStart time.Time, metric string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RankingSnapshot{}).
+		Where("scope = ? AND owner_id = ? AND period = ? AND period_start = ? AND metric = ?", scope, ownerID, period, periodStart, metric).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindOwnerIDs 查询某类榜单在某个周期已生成快照的所有者
+func (r *rankingSnapshotRepository) FindOwnerIDs(ctx context.Context, scope string, period string, periodStart time.Time, metric string) (map[uint64]bool, error) {
+	var ownerIDs []uint64
+	if err := r.db.WithContext(ctx).Model(&model.RankingSnapshot{}).
+		Distinct("owner_id").
+		Where("scope = ? AND period = ? AND period_start = ? AND metric = ?", scope, period, periodStart, metric).
+		Pluck("owner_id", &ownerIDs).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]bool, len(ownerIDs))
+	for _, id := range ownerIDs {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// FindRanks 查询榜单在某个周期中指定用户的快照
+func (r *rankingSnapshotRepository) FindRanks(ctx context.Context, scope string, ownerID uint64, period string, periodStart time.Time, metric string, userIDs []uint64) (map[uint64]*entity.RankingSnapshot, error) {
+	result := make(map[uint64]*entity.RankingSnapshot)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var snapshotModels []model.RankingSnapshot
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND owner_id = ? AND period = ? AND period_start = ? AND metric = ? AND user_id IN ?",
+			scope, ownerID, period, periodStart, metric, userIDs).
+		Find(&snapshotModels).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshotModel := range snapshotModels {
+		result[snapshotModel.UserID] = snapshotModel.ToEntity()
+	}
+	return result, nil
+}
+
+// FindUserHistory 查询用户在某个榜单中最近limit个周期的快照（按周期升序）
+func (r *rankingSnapshotRepository) FindUserHistory(ctx context.Context, scope string, ownerID uint64, period, metric string, userID uint64, limit int) ([]*entity.RankingSnapshot, error) {
+	var snapshotModels []model.RankingSnapshot
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND owner_id = ? AND period = ? AND metric = ? AND user_id = ?", scope, ownerID, period, metric, userID).
+		Order("period_start DESC").
+		Limit(limit).
+		Find(&snapshotModels).Error
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*entity.RankingSnapshot, len(snapshotModels))
+	for i, snapshotModel := range snapshotModels {
+		snapshots[len(snapshotModels)-1-i] = snapshotModel.ToEntity()
+	}
+	return snapshots, nil
+}
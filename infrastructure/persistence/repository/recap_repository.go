@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recapRepository 回顾仓储实现
+type recapRepository struct {
+	db *gorm.DB
+}
+
+// NewRecapRepository 创建回顾仓储
+func NewRecapRepository(db *gorm.DB) repository.RecapRepository {
+	return &recapRepository{db: db}
+}
+
+// FindByID 根据ID查找回顾
+func (r *recapRepository) FindByID(ctx context.Context, id uint64) (*entity.Recap, error) {
+	var recapModel model.Recap
+	if err := r.db.WithContext(ctx).First(&recapModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return recapModel.ToEntity(), nil
+}
+
+// FindByUserAndPeriod 查找用户某个周期的回顾
+func (r *recapRepository) FindByUserAndPeriod(ctx context.Context, userID uint64, periodType string, periodStart time.Time) (*entity.Recap, error) {
+	var recapModel model.Recap
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND period_type = ? AND period_start = ?", userID, periodType, periodStart).
+		First(&recapModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return recapModel.ToEntity(), nil
+}
+
+// FindUserIDsWithoutRecap 查找注册时间早于registeredBefore、还没有该周期回顾的正常状态用户
+func (r *recapRepository) FindUserIDsWithoutRecap(ctx context.Context, periodType string, periodStart, registeredBefore time.Time) ([]uint64, error) {
+	generated := r.db.WithContext(ctx).Model(&model.Recap{}).
+		Select("user_id").
+		Where("period_type = ? AND period_start = ?", periodType, periodStart)
+
+	var ids []uint64
+	if err := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("status = ? AND created_at < ?", 1, registeredBefore).
+		Where("id NOT IN (?)", generated).
+		Order("id ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindByUserID 分页查询用户的回顾，periodType为空时返回所有类型
+func (r *recapRepository) FindByUserID(ctx context.Context, userID uint64, periodType string, page, size int) ([]*entity.Recap, int64, error) {
+	var recapModels []model.Recap
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Recap{}).Where("user_id = ?", userID)
+	if periodType != "" {
+		query = query.Where("period_type = ?", periodType)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("period_start DESC").Offset(offset).Limit(size).Find(&recapModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	recaps := make([]*entity.Recap, len(recapModels))
+	for i, recapModel := range recapModels {
+		recaps[i] = recapModel.ToEntity()
+	}
+
+	return recaps, total, nil
+}
+
+// Save 保存回顾，同一用户同一周期只会保存一次
+func (r *recapRepository) Save(ctx context.Context, recap *entity.Recap) error {
+	var recapModel model.Recap
+	if err := recapModel.FromEntity(recap); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(&recapModel).Error; err != nil {
+		return err
+	}
+	recap.ID = recapModel.ID
+	recap.CreatedAt = recapModel.CreatedAt
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordFlagRepository 记录反作弊标记仓储实现
+type recordFlagRepository struct {
+	db *gorm.DB
+}
+
+// NewRecordFlagRepository 创建记录反作弊标记仓储
+func NewRecordFlagRepository(db *gorm.DB) repository.RecordFlagRepository {
+	return &recordFlagRepository{db: db}
+}
+
+// FindByID 根据ID查找标记
+func (r *recordFlagRepository) FindByID(ctx context.Context, id uint64) (*entity.RecordFlag, error) {
+	var flagModel model.RecordFlag
+	if err := r.db.WithContext(ctx).First(&flagModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return flagModel.ToEntity(), nil
+}
+
+// FindByRecordID 查找记录的标记
+func (r *recordFlagRepository) FindByRecordID(ctx context.Context, recordID uint64) (*entity.RecordFlag, error) {
+	var flagModel model.RecordFlag
+	if err := r.db.WithContext(ctx).Where("record_id = ?", recordID).First(&flagModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return flagModel.ToEntity(), nil
+}
+
+// FindByUserID 分页查找用户的标记
+func (r *recordFlagRepository) FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.RecordFlag, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.RecordFlag{}).Where("user_id = ?", userID)
+	return r.findPage(query, "id DESC", page, size)
+}
+
+// FindByStatuses 分页查找指定状态的标记
+func (r *recordFlagRepository) FindByStatuses(ctx context.Context, statuses []string, page, size int) ([]*entity.RecordFlag, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.RecordFlag{}).Where("status IN ?", statuses)
+	return r.findPage(query, "id ASC", page, size)
+}
+
+// findPage 分页查询标记
+func (r *recordFlagRepository) findPage(query *gorm.DB, order string, page, size int) ([]*entity.RecordFlag, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var flagModels []model.RecordFlag
+	offset := (page - 1) * size
+	if err := query.Order(order).Offset(offset).Limit(size).Find(&flagModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	flags := make([]*entity.RecordFlag, len(flagModels))
+	for i, flagModel := range flagModels {
+		flags[i] = flagModel.ToEntity()
+	}
+	return flags, total, nil
+}
+
+// Save 保存标记
+func (r *recordFlagRepository) Save(ctx context.Context, flag *entity.RecordFlag) error {
+	var flagModel model.RecordFlag
+	flagModel.FromEntity(flag)
+	if err := r.db.WithContext(ctx).Create(&flagModel).Error; err != nil {
+		return err
+	}
+	flag.ID = flagModel.ID
+	flag.CreatedAt = flagModel.CreatedAt
+	flag.UpdatedAt = flagModel.UpdatedAt
+	return nil
+}
+
+// Update 更新标记的规则、状态、申诉和审核信息
+func (r *recordFlagRepository) Update(ctx context.Context, flag *entity.RecordFlag) error {
+	flag.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(&model.RecordFlag{}).Where("id = ?", flag.ID).Updates(map[string]interface{}{
+		"rules":         strings.Join(flag.Rules, ","),
+		"reason":        flag.Reason,
+		"status":        flag.Status,
+		"appeal_reason": flag.AppealReason,
+		"appealed_at":   flag.AppealedAt,
+		"reviewer_id":   flag.ReviewerID,
+		"review_note":   flag.ReviewNote,
+		"reviewed_at":   flag.ReviewedAt,
+		"updated_at":    flag.UpdatedAt,
+	}).Error
+}
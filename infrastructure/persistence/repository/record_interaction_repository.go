@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordReactionRepository 记录表情回应仓储实现
+type recordReactionRepository struct {
+	db *gorm.DB
+}
+
+// NewRecordReactionRepository 创建记录表情回应仓储
+func NewRecordReactionRepository(db *gorm.DB) repository.RecordReactionRepository {
+	return &recordReactionRepository{db: db}
+}
+
+// Add 添加回应，已回应过同一表情时返回false
+func (r *recordReactionRepository) Add(ctx context.Context, reaction *entity.RecordReaction) (bool, error) {
+	var reactionModel model.RecordReaction
+	reactionModel.FromEntity(reaction)
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&reactionModel)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	reaction.ID = reactionModel.ID
+	reaction.CreatedAt = reactionModel.CreatedAt
+	return result.RowsAffected > 0, nil
+}
+
+// Remove 取消回应
+func (r *recordReactionRepository) Remove(ctx context.Context, recordID, userID uint64, emoji string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("record_id = ? AND user_id = ? AND emoji = ?", recordID, userID, emoji).
+		Delete(&model.RecordReaction{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByRecordID 查询记录的全部回应
+func (r *recordReactionRepository) FindByRecordID(ctx context.Context, recordID uint64) ([]*entity.RecordReaction, error) {
+	var reactionModels []model.RecordReaction
+	if err := r.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Order("created_at DESC, id DESC").
+		Find(&reactionModels).Error; err != nil {
+		return nil, err
+	}
+
+	reactions := make([]*entity.RecordReaction, len(reactionModels))
+	for i := range reactionModels {
+		reactions[i] = reactionModels[i].ToEntity()
+	}
+	return reactions, nil
+}
+
+// CountByRecordIDs 统计各记录每种表情的回应数
+func (r *recordReactionRepository) CountByRecordIDs(ctx context.Context, recordIDs []uint64) (map[uint64]map[string]int64, error) {
+	counts := make(map[uint64]map[string]int64, len(recordIDs))
+	if len(recordIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RecordID uint64
+		Emoji    string
+		Count    int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.RecordReaction{}).
+		Select("record_id, emoji, COUNT(*) AS count").
+		Where("record_id IN ?", recordIDs).
+		Group("record_id, emoji").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if counts[row.RecordID] == nil {
+			counts[row.RecordID] = make(map[string]int64)
+		}
+		counts[row.RecordID][row.Emoji] = row.Count
+	}
+	return counts, nil
+}
+
+// FindEmojisByUser 查询用户对各记录回应过的表情
+func (r *recordReactionRepository) FindEmojisByUser(ctx context.Context, recordIDs []uint64, userID uint64) (map[uint64][]string, error) {
+	emojis := make(map[uint64][]string, len(recordIDs))
+	if len(recordIDs) == 0 {
+		return emojis, nil
+	}
+
+	var reactionModels []model.RecordReaction
+	if err := r.db.WithContext(ctx).
+		Where("record_id IN ? AND user_id = ?", recordIDs, userID).
+		Order("id").
+		Find(&reactionModels).Error; err != nil {
+		return nil, err
+	}
+	for _, reactionModel := range reactionModels {
+		emojis[reactionModel.RecordID] = append(emojis[reactionModel.RecordID], reactionModel.Emoji)
+	}
+	return emojis, nil
+}
+
+// CountByUserSince 统计用户在某个时间之后添加的回应数
+func (r *recordReactionRepository) CountByUserSince(ctx context.Context, userID uint64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RecordReaction{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteByRecordID 删除记录的全部回应
+func (r *recordReactionRepository) DeleteByRecordID(ctx context.Context, recordID uint64) error {
+	return r.db.WithContext(ctx).Where("record_id = ?", recordID).Delete(&model.RecordReaction{}).Error
+}
+
+// recordCommentRepository 记录评论仓储实现
+type recordCommentRepository struct {
+	db *gorm.DB
+}
+
+// NewRecordCommentRepository 创建记录评论仓储
+func NewRecordCommentRepository(db *gorm.DB) repository.RecordCommentRepository {
+	return &recordCommentRepository{db: db}
+}
+
+// FindByID 根据ID查找评论
+func (r *recordCommentRepository) FindByID(ctx context.Context, id uint64) (*entity.RecordComment, error) {
+	var commentModel model.RecordComment
+	if err := r.db.WithContext(ctx).First(&commentModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return commentModel.ToEntity(), nil
+}
+
+// Save 保存评论
+func (r *recordCommentRepository) Save(ctx context.Context, comment *entity.RecordComment) error {
+	var commentModel model.RecordComment
+	commentModel.FromEntity(comment)
+	if err := r.db.WithContext(ctx).Create(&commentModel).Error; err != nil {
+		return err
+	}
+
+	comment.ID = commentModel.ID
+	comment.CreatedAt = commentModel.CreatedAt
+	return nil
+}
+
+// Delete 删除评论，一级评论的回复一并删除
+func (r *recordCommentRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Where("id = ? OR parent_id = ?", id, id).Delete(&model.RecordComment{}).Error
+}
+
+// FindRootsByRecordID 分页查询记录的一级评论
+func (r *recordCommentRepository) FindRootsByRecordID(ctx context.Context, recordID uint64, page, size int) ([]*entity.RecordComment, int64, error) {
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.RecordComment{}).Where("record_id = ? AND parent_id = 0", recordID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var commentModels []model.RecordComment
+	if err := query.Order("created_at, id").Offset((page - 1) * size).Limit(size).Find(&commentModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	comments := make([]*entity.RecordComment, len(commentModels))
+	for i := range commentModels {
+		comments[i] = commentModels[i].ToEntity()
+	}
+	return comments, total, nil
+}
+
+// FindRepliesByParentIDs 查询一级评论下的全部回复，按一级评论分组
+func (r *recordCommentRepository) FindRepliesByParentIDs(ctx context.Context, parentIDs []uint64) (map[uint64][]*entity.RecordComment, error) {
+	replies := make(map[uint64][]*entity.RecordComment, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return replies, nil
+	}
+
+	var commentModels []model.RecordComment
+	if err := r.db.WithContext(ctx).
+		Where("parent_id IN ?", parentIDs).
+		Order("created_at, id").
+		Find(&commentModels).Error; err != nil {
+		return nil, err
+	}
+	for i := range commentModels {
+		reply := commentModels[i].ToEntity()
+		replies[reply.ParentID] = append(replies[reply.ParentID], reply)
+	}
+	return replies, nil
+}
+
+// CountByRecordIDs 统计各记录的评论数（含回复）
+func (r *recordCommentRepository) CountByRecordIDs(ctx context.Context, recordIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(recordIDs))
+	if len(recordIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RecordID uint64
+		Count    int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.RecordComment{}).
+		Select("record_id, COUNT(*) AS count").
+		Where("record_id IN ?", recordIDs).
+		Group("record_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.RecordID] = row.Count
+	}
+	return counts, nil
+}
+
+// CountByUserSince 统计用户在某个时间之后发表的评论数
+func (r *recordCommentRepository) CountByUserSince(ctx context.Context, userID uint64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RecordComment{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteByRecordID 删除记录的全部评论
+func (r *recordCommentRepository) DeleteByRecordID(ctx context.Context, recordID uint64) error {
+	return r.db.WithContext(ctx).Where("record_id = ?", recordID).Delete(&model.RecordComment{}).Error
+}
@@ -7,7 +7,6 @@ import (
 	"record-project/domain/entity"
 	"record-project/domain/repository"
 	"record-project/infrastructure/persistence/model"
-	"sort"
 	"time"
 
 	"gorm.io/gorm"
@@ -35,18 +34,57 @@ func (r *recordRepository) FindByID(ctx context.Context, id uint64) (*entity.Rec
 	return recordModel.ToEntity(), nil
 }
 
+// FindByIDs 根据ID列表查找多条记录
+func (r *recordRepository) FindByIDs(ctx context.Context, ids []uint64) ([]*entity.Record, error) {
+	if len(ids) == 0 {
+		return []*entity.Record{}, nil
+	}
+
+	var recordModels []model.Record
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&recordModels).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]*entity.Record, len(recordModels))
+	for i, recordModel := range recordModels {
+		records[i] = recordModel.ToEntity()
+	}
+	return records, nil
+}
+
+// visibleRecords 只保留查看者可见的记录，access 为空或查看自己的记录时不做限制
+func visibleRecords(db *gorm.DB, access *entity.RecordAccess) *gorm.DB {
+	if access == nil || access.Self {
+		return db
+	}
+	if access.Blocked {
+		return db.Where("1 = 0")
+	}
+
+	visibilities := []string{entity.RecordVisibilityPublic}
+	if !access.Friend {
+		return db.Where("visibility IN ?", visibilities)
+	}
+	visibilities = append(visibilities, entity.RecordVisibilityFriends)
+	if len(access.CircleIDs) == 0 {
+		return db.Where("visibility IN ?", visibilities)
+	}
+	return db.Where("visibility IN ? OR (visibility = ? AND circle_id IN ?)",
+		visibilities, entity.RecordVisibilityCircle, access.CircleIDs)
+}
+
 // FindByUserID 根据用户ID查找记录
-func (r *recordRepository) FindByUserID(ctx context.Context, userID uint64, page, size int) ([]*entity.Record, int64, error) {
+func (r *recordRepository) FindByUserID(ctx context.Context, userID uint64, access *entity.RecordAccess, page, size int) ([]*entity.Record, int64, error) {
 	var recordModels []model.Record
 	var total int64
 
 	offset := (page - 1) * size
 
-	if err := r.db.WithContext(ctx).Model(&model.Record{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := visibleRecords(r.db.WithContext(ctx).Model(&model.Record{}).Where("user_id = ?", userID), access).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("record_time DESC").Offset(offset).Limit(size).Find(&recordModels).Error; err != nil {
+	if err := visibleRecords(r.db.WithContext(ctx).Where("user_id = ?", userID), access).Order("record_time DESC").Offset(offset).Limit(size).Find(&recordModels).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -59,15 +97,15 @@ func (r *recordRepository) FindByUserID(ctx context.Context, userID uint64, page
 }
 
 // FindByDateRange 根据日期范围查找记录
-func (r *recordRepository) FindByDateRange(ctx context.Context, userID uint64, start, end time.Time, page, size int) ([]*entity.Record, error) {
+func (r *recordRepository) FindByDateRange(ctx context.Context, userID uint64, access *entity.RecordAccess, start, end time.Time, page, size int) ([]*entity.Record, error) {
 	var recordModels []model.Record
 
 	// 计算分页偏移量
 	offset := (page - 1) * size
 
 	// 添加分页参数
-	query := r.db.WithContext(ctx).
-		Where("user_id = ? AND record_time BETWEEN ? AND ?", userID, start, end).
+	query := visibleRecords(r.db.WithContext(ctx).
+		Where("user_id = ? AND record_time BETWEEN ? AND ?", userID, start, end), access).
 		Order("record_time DESC").
 		Offset(offset).
 		Limit(size)
@@ -84,11 +122,11 @@ func (r *recordRepository) FindByDateRange(ctx context.Context, userID uint64, s
 	return records, nil
 }
 
-func (r *recordRepository) CountRecordsByDateRange(ctx context.Context, userID uint64, start time.Time, end time.Time) (int64, error) {
+func (r *recordRepository) CountRecordsByDateRange(ctx context.Context, userID uint64, access *entity.RecordAccess, start time.Time, end time.Time) (int64, error) {
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&model.Record{}).
-		Where("user_id = ? AND record_time BETWEEN ? AND ?", userID, start, end)
+	query := visibleRecords(r.db.WithContext(ctx).Model(&model.Record{}).
+		Where("user_id = ? AND record_time BETWEEN ? AND ?", userID, start, end), access)
 
 	if err := query.Count(&total).Error; err != nil {
 		return 0, err
@@ -118,6 +156,8 @@ func (r *recordRepository) Update(ctx context.Context, record *entity.Record) er
 		"duration":     recordModel.Duration,
 		"poop_type_id": recordModel.PoopTypeID,
 		"note":         recordModel.Note,
+		"visibility":   recordModel.Visibility,
+		"circle_id":    recordModel.CircleID,
 		"updated_at":   time.Now(),
 	})
 	if result.Error != nil {
@@ -254,114 +294,317 @@ func (r *recordRepository) UpdateWithTags(ctx context.Context, record *entity.Re
 	})
 }
 
-// GetGlobalRanking 获取全局排行榜（按记录次数排序）
-func (r *recordRepository) GetGlobalRanking(ctx context.Context, start, end time.Time, limit int) ([]*entity.RankingItem, error) {
-	var rankingItems []*entity.RankingItem
+// rankingMetricExpr 排行榜指标的SQL表达式，基于统计子查询s和连续天数子查询st
+func rankingMetricExpr(metric string) (string, error) {
+	switch metric {
+	case entity.RankingMetricCount:
+		return "s.record_count", nil
+	case entity.RankingMetricTotalDuration:
+		return "s.total_duration", nil
+	case entity.RankingMetricAvgDuration:
+		return "ROUND(s.total_duration / s.record_count, 2)", nil
+	case entity.RankingMetricStreak:
+		return "st.longest_streak", nil
+	case entity.RankingMetricHealthScore:
+		// 健康分 = 健康类型占比 × 100，记录不足10次时按次数比例折算，避免少量记录刷高分
+		return "ROUND(100 * s.healthy_count / s.record_count * LEAST(s.record_count, 10) / 10, 2)", nil
+	case entity.RankingMetricHealthyCount:
+		return "s.healthy_count", nil
+	default:
+		return "", fmt.Errorf("不支持的排行榜指标: %s", metric)
+	}
+}
+
+// localDateExpr 按东八区计算record_time所在自然日的SQL表达式，参数为appZoneOffset()
+// DSN使用loc=Local，record_time保存的是应用所在时区的本地时间，与数据库会话时区无关；
+// 先从应用时区转换到+08:00再取日期，与服务层按Asia/Shanghai计算的自然日一致
+const localDateExpr = "DATE(CONVERT_TZ(record_time, ?, '+08:00'))"
 
-	err := r.db.WithContext(ctx).Model(&model.Record{}).
-		Select("user_id, COUNT(*) as record_count, SUM(duration) as total_duration").
+// appZoneOffset 应用所在时区当前的UTC偏移，格式为+08:00
+func appZoneOffset() string {
+	return time.Now().Format("-07:00")
+}
+
+// flaggedRecordIDs 被反作弊规则标记且未恢复的记录ID，这些记录不计入排行榜
+func (r *recordRepository) flaggedRecordIDs(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&model.RecordFlag{}).
+		Select("record_id").
+		Where("status IN ?", entity.RecordFlagStatusesExcluded)
+}
+
+// rankingStatsQuery 按用户汇总时间段内的记录，userIDs为空时统计全部用户
+// 所有排行榜都基于此查询，只统计可见范围属于visibilities的记录
+func (r *recordRepository) rankingStatsQuery(ctx context.Context, userIDs []uint64, visibilities []string, start, end time.Time) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Record{}).
+		Select("user_id, COUNT(*) AS record_count, COALESCE(SUM(duration), 0) AS total_duration, "+
+			"SUM(CASE WHEN poop_type_id IN ? THEN 1 ELSE 0 END) AS healthy_count", entity.HealthyPoopTypeIDs).
 		Where("record_time BETWEEN ? AND ?", start, end).
-		Group("user_id").
-		Order("record_count DESC").
-		Limit(limit).
-		Scan(&rankingItems).Error
+		Where("visibility IN ?", visibilities).
+		Where("id NOT IN (?)", r.flaggedRecordIDs(ctx))
+	if len(userIDs) > 0 {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+	return query.Group("user_id")
+}
+
+// streakQuery 计算时间段内每个用户的最长连续打卡天数，与rankingStatsQuery统计相同范围的记录
+// 按日期排序后用 日期 - 行号 得到分组键，同一分组内即为连续的日期
+func (r *recordRepository) streakQuery(ctx context.Context, userIDs []uint64, visibilities []string, start, end time.Time) *gorm.DB {
+	days := r.db.WithContext(ctx).Model(&model.Record{}).
+		Select("DISTINCT user_id, "+localDateExpr+" AS record_date", appZoneOffset()).
+		Where("record_time BETWEEN ? AND ?", start, end).
+		Where("visibility IN ?", visibilities).
+		Where("id NOT IN (?)", r.flaggedRecordIDs(ctx))
+	if len(userIDs) > 0 {
+		days = days.Where("user_id IN ?", userIDs)
+	}
+
+	grouped := r.db.WithContext(ctx).Table("(?) AS d", days).
+		Select("user_id, DATE_SUB(record_date, INTERVAL ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY record_date) DAY) AS grp")
+
+	islands := r.db.WithContext(ctx).Table("(?) AS g", grouped).
+		Select("user_id, COUNT(*) AS streak_length").
+		Group("user_id, grp")
+
+	return r.db.WithContext(ctx).Table("(?) AS i", islands).
+		Select("user_id, MAX(streak_length) AS longest_streak").
+		Group("user_id")
+}
+
+// globalStatsTable 全局排行榜的统计子查询s，排除设置为仅好友可见或不参与排行的用户
+func (r *recordRepository) globalStatsTable(ctx context.Context, start, end time.Time) *gorm.DB {
+	hidden := r.db.WithContext(ctx).Model(&model.RankingSetting{}).
+		Select("user_id").
+		Where("visibility IN ?", entity.RankingVisibilitiesHiddenFromGlobal)
+
+	return r.db.WithContext(ctx).Table("(?) AS s", r.rankingStatsQuery(ctx, nil, entity.RecordVisibilitiesPublic, start, end)).
+		Where("s.user_id NOT IN (?)", hidden)
+}
+
+// rankingOrder 排行榜排序：指标值降序，相同时记录次数多的在前，再按用户ID升序保证结果稳定
+const rankingOrder = "metric_value DESC, record_count DESC, user_id ASC"
+
+// globalRankingQuery 全局排行榜查询，只包含时间段内有记录的用户
+// 排名使用DENSE_RANK，指标相同的用户名次相同；row_num用于稳定排序、分页和查找相邻用户
+func (r *recordRepository) globalRankingQuery(ctx context.Context, metric string, start, end time.Time) (*gorm.DB, error) {
+	base, err := r.rankingValuesQuery(ctx, metric, r.globalStatsTable(ctx, start, end), start, end)
 	if err != nil {
 		return nil, err
 	}
 
-	// 为每个排名项设置排名
-	for i, item := range rankingItems {
-		item.Rank = uint64(i + 1)
+	ranked := r.db.WithContext(ctx).Table("(?) AS b", base).
+		Select("b.*, DENSE_RANK() OVER (ORDER BY metric_value DESC) AS `rank`, " +
+			"ROW_NUMBER() OVER (ORDER BY " + rankingOrder + ") AS row_num")
+
+	return r.db.WithContext(ctx).Table("(?) AS ranked", ranked), nil
+}
+
+// rankingValuesQuery 在统计全部用户公开记录的子查询s的基础上计算每个用户的指标值
+func (r *recordRepository) rankingValuesQuery(ctx context.Context, metric string, stats *gorm.DB, start, end time.Time) (*gorm.DB, error) {
+	metricExpr, err := rankingMetricExpr(metric)
+	if err != nil {
+		return nil, err
 	}
 
-	return rankingItems, nil
+	if metric == entity.RankingMetricStreak {
+		stats = stats.Joins("LEFT JOIN (?) AS st ON st.user_id = s.user_id", r.streakQuery(ctx, nil, entity.RecordVisibilitiesPublic, start, end))
+	}
+	return stats.Select("s.user_id AS user_id, s.record_count AS record_count, s.total_duration AS total_duration, COALESCE(" + metricExpr + ", 0) AS metric_value"), nil
 }
 
-// GetFriendRanking 获取好友排行榜（按记录次数排序，包含记录为0的用户）
-func (r *recordRepository) GetFriendRanking(ctx context.Context, userIDs []uint64, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
-	offset := (page - 1) * pageSize
+// GetRankingValues 计算时间段内所有有记录的用户的指标值，与全局排行榜一样只统计公开的记录
+func (r *recordRepository) GetRankingValues(ctx context.Context, metric string, start, end time.Time) ([]*entity.RankingItem, error) {
+	query, err := r.rankingValuesQuery(ctx, metric, r.db.WithContext(ctx).Table("(?) AS s", r.rankingStatsQuery(ctx, nil, entity.RecordVisibilitiesPublic, start, end)), start, end)
+	if err != nil {
+		return nil, err
+	}
 
-	// 1. 先获取所有好友在指定时间段内的记录统计
-	var recordStats []struct {
-		UserID        uint64
-		RecordCount   int64
-		TotalDuration int64
+	var items []*entity.RankingItem
+	if err := query.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		item.Metric = metric
 	}
+	return items, nil
+}
 
-	err := r.db.WithContext(ctx).Model(&model.Record{}).
-		Select("user_id, COUNT(*) as record_count, SUM(duration) as total_duration").
-		Where("user_id IN ? AND record_time BETWEEN ? AND ?", userIDs, startDate, endDate).
-		Group("user_id").
-		Scan(&recordStats).Error
+// rankedRow 带行号的排行榜项目
+type rankedRow struct {
+	entity.RankingItem
+	RowNum int64
+}
+
+// GetGlobalRanking 分页获取全局排行榜（按指定指标排序）
+func (r *recordRepository) GetGlobalRanking(ctx context.Context, metric string, start, end time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
+	query, err := r.globalRankingQuery(ctx, metric, start, end)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// 2. 创建用户ID到记录统计的映射
-	statsMap := make(map[uint64]struct {
-		RecordCount   int64
-		TotalDuration int64
+	// 1. 计算上榜总人数
+	var total int64
+	if err := r.globalStatsTable(ctx, start, end).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 2. 分页查询
+	offset := (page - 1) * pageSize
+	var rankingItems []*entity.RankingItem
+	if err := query.Order("row_num ASC").Offset(offset).Limit(pageSize).Scan(&rankingItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for _, item := range rankingItems {
+		item.Metric = metric
+	}
+
+	return rankingItems, int(total), nil
+}
+
+// GetUserGlobalRank 获取用户在全局排行榜中的名次以及前后相邻的用户
+func (r *recordRepository) GetUserGlobalRank(ctx context.Context, metric string, userID uint64, start, end time.Time) (*entity.RankingPosition, error) {
+	return r.rankPosition(metric, userID, func() (*gorm.DB, error) {
+		return r.globalRankingQuery(ctx, metric, start, end)
 	})
+}
 
-	for _, stat := range recordStats {
-		statsMap[stat.UserID] = struct {
-			RecordCount   int64
-			TotalDuration int64
-		}{
-			RecordCount:   stat.RecordCount,
-			TotalDuration: stat.TotalDuration,
-		}
+// rankPosition 在排行榜查询中查找用户的名次以及前后相邻的用户，newQuery每次调用返回新的排行榜查询
+func (r *recordRepository) rankPosition(metric string, userID uint64, newQuery func() (*gorm.DB, error)) (*entity.RankingPosition, error) {
+	query, err := newQuery()
+	if err != nil {
+		return nil, err
 	}
 
-	// 3. 为所有用户创建排行项（包括没有记录的用户）
-	allRankingItems := make([]*entity.RankingItem, 0, len(userIDs))
-	for _, userID := range userIDs {
-		item := &entity.RankingItem{
-			UserID:        userID,
-			RecordCount:   0,
-			TotalDuration: 0,
-		}
+	position := &entity.RankingPosition{
+		Me: &entity.RankingItem{UserID: userID, Metric: metric},
+	}
 
-		// 如果用户有记录，更新统计数据
-		if stat, exists := statsMap[userID]; exists {
-			item.RecordCount = stat.RecordCount
-			item.TotalDuration = stat.TotalDuration
+	var me []rankedRow
+	if err := query.Where("user_id = ?", userID).Scan(&me).Error; err != nil {
+		return nil, err
+	}
+	if len(me) == 0 {
+		// 不在榜上
+		return position, nil
+	}
+	position.Me = &me[0].RankingItem
+	position.Me.Metric = metric
+
+	query, err = newQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	var neighbors []rankedRow
+	if err := query.Where("row_num IN ?", []int64{me[0].RowNum - 1, me[0].RowNum + 1}).Scan(&neighbors).Error; err != nil {
+		return nil, err
+	}
+	for i := range neighbors {
+		item := &neighbors[i].RankingItem
+		item.Metric = metric
+		if neighbors[i].RowNum < me[0].RowNum {
+			position.Above = item
+		} else {
+			position.Below = item
 		}
+	}
+
+	return position, nil
+}
+
+// friendRankingQuery 好友排行榜查询，以用户表为主表左连接统计结果，没有记录的好友指标为0
+// 排名使用DENSE_RANK，与全局排行榜一致；row_num用于稳定排序、分页和查找相邻用户
+func (r *recordRepository) friendRankingQuery(ctx context.Context, metric string, userIDs []uint64, visibilities []string, start, end time.Time) (*gorm.DB, error) {
+	metricExpr, err := rankingMetricExpr(metric)
+	if err != nil {
+		return nil, err
+	}
 
-		allRankingItems = append(allRankingItems, item)
+	base := r.db.WithContext(ctx).Table("users AS u").
+		Joins("LEFT JOIN (?) AS s ON s.user_id = u.id", r.rankingStatsQuery(ctx, userIDs, visibilities, start, end))
+	if metric == entity.RankingMetricStreak {
+		base = base.Joins("LEFT JOIN (?) AS st ON st.user_id = u.id", r.streakQuery(ctx, userIDs, visibilities, start, end))
 	}
+	base = base.
+		Select("u.id AS user_id, COALESCE(s.record_count, 0) AS record_count, COALESCE(s.total_duration, 0) AS total_duration, COALESCE("+metricExpr+", 0) AS metric_value").
+		Where("u.id IN ?", userIDs)
 
-	// 4. 按记录次数排序
-	// 使用自定义排序
-	sort.Slice(allRankingItems, func(i, j int) bool {
-		return allRankingItems[i].RecordCount > allRankingItems[j].RecordCount
-	})
+	ranked := r.db.WithContext(ctx).Table("(?) AS b", base).
+		Select("b.*, DENSE_RANK() OVER (ORDER BY metric_value DESC) AS `rank`, " +
+			"ROW_NUMBER() OVER (ORDER BY " + rankingOrder + ") AS row_num")
 
-	// 5. 设置排名
-	for i := range allRankingItems {
-		allRankingItems[i].Rank = uint64(i + 1)
+	return r.db.WithContext(ctx).Table("(?) AS ranked", ranked), nil
+}
+
+// GetFriendRanking 获取好友排行榜（按指定指标排序，包含记录为0的用户）
+// 排名、排序和分页都在一条窗口函数查询中完成
+func (r *recordRepository) GetFriendRanking(ctx context.Context, metric string, userIDs []uint64, visibilities []string, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
+	if len(userIDs) == 0 {
+		return []*entity.RankingItem{}, 0, nil
 	}
 
-	// 6. 计算总数
-	total := len(allRankingItems)
+	query, err := r.friendRankingQuery(ctx, metric, userIDs, visibilities, startDate, endDate)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// 7. 分页
-	var pagedItems []*entity.RankingItem
-	if offset < len(allRankingItems) {
-		end := offset + pageSize
-		if end > len(allRankingItems) {
-			end = len(allRankingItems)
-		}
-		pagedItems = allRankingItems[offset:end]
-	} else {
-		pagedItems = []*entity.RankingItem{}
+	// 1. 计算总数
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("id IN ?", userIDs).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 2. 分页查询
+	offset := (page - 1) * pageSize
+	var rankingItems []*entity.RankingItem
+	if err := query.Order("row_num ASC").Offset(offset).Limit(pageSize).Scan(&rankingItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for _, item := range rankingItems {
+		item.Metric = metric
+	}
+
+	return rankingItems, int(total), nil
+}
+
+// GetUserFriendRank 获取用户在好友排行榜中的名次以及前后相邻的用户
+func (r *recordRepository) GetUserFriendRank(ctx context.Context, metric string, userID uint64, userIDs []uint64, visibilities []string, start, end time.Time) (*entity.RankingPosition, error) {
+	if len(userIDs) == 0 {
+		return &entity.RankingPosition{Me: &entity.RankingItem{UserID: userID, Metric: metric}}, nil
+	}
+	return r.rankPosition(metric, userID, func() (*gorm.DB, error) {
+		return r.friendRankingQuery(ctx, metric, userIDs, visibilities, start, end)
+	})
+}
+
+// GetUserRecordStats 按用户汇总日期范围内的记录，userIDs为空时统计全部用户，被反作弊标记的记录不计入
+// visibilities不为空时只统计这些可见范围的记录，为空时统计全部记录
+func (r *recordRepository) GetUserRecordStats(ctx context.Context, userIDs []uint64, visibilities []string, start, end time.Time) ([]*entity.UserRecordStats, error) {
+	var stats []*entity.UserRecordStats
+
+	query := r.db.WithContext(ctx).Model(&model.Record{}).
+		Select("user_id, COUNT(*) as record_count, COALESCE(SUM(duration), 0) as total_duration, "+
+			"SUM(CASE WHEN poop_type_id IN ? THEN 1 ELSE 0 END) as healthy_count", entity.HealthyPoopTypeIDs).
+		Where("record_time BETWEEN ? AND ?", start, end).
+		Where("id NOT IN (?)", r.flaggedRecordIDs(ctx))
+	if len(userIDs) > 0 {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+	if len(visibilities) > 0 {
+		query = query.Where("visibility IN ?", visibilities)
 	}
 
-	return pagedItems, total, nil
+	if err := query.Group("user_id").Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
 }
 
 // GetUsersDailyRecordStats 批量获取指定用户当天的拉屎记录总数和时间
-func (r *recordRepository) GetUsersDailyRecordStats(ctx context.Context, userIDs []uint64, date time.Time) (map[uint64]*entity.DailyRecordStats, error) {
+// 查看者不可见的记录不计入统计，accesses 中没有的用户视为全部不可见
+func (r *recordRepository) GetUsersDailyRecordStats(ctx context.Context, userIDs []uint64, accesses map[uint64]*entity.RecordAccess, date time.Time) (map[uint64]*entity.DailyRecordStats, error) {
 	// 设置日期范围为当天的0点到23:59:59
 	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	endDate := time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 999999999, date.Location())
@@ -396,6 +639,9 @@ func (r *recordRepository) GetUsersDailyRecordStats(ctx context.Context, userIDs
 			// 这种情况不应该发生，因为我们已经初始化了所有用户的统计数据
 			continue
 		}
+		if access, ok := accesses[record.UserID]; !ok || !access.CanView(record.ToEntity()) {
+			continue
+		}
 		
 		stats.Count++
 		stats.TotalTime += record.Duration
@@ -405,8 +651,185 @@ func (r *recordRepository) GetUsersDailyRecordStats(ctx context.Context, userIDs
 	return result, nil
 }
 
+// FindAllByDateRange 查询用户在日期范围内的全部记录（不分页，按时间升序）
+func (r *recordRepository) FindAllByDateRange(ctx context.Context, userID uint64, start, end time.Time) ([]*entity.Record, error) {
+	var recordModels []model.Record
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND record_time BETWEEN ? AND ?", userID, start, end).
+		Order("record_time ASC").
+		Find(&recordModels).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]*entity.Record, len(recordModels))
+	for i, recordModel := range recordModels {
+		records[i] = recordModel.ToEntity()
+	}
+
+	return records, nil
+}
+
+// CountCreatedBetween 统计用户在时间范围内提交的记录数（按创建时间，包含两端）
+func (r *recordRepository) CountCreatedBetween(ctx context.Context, userID uint64, start, end time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Record{}).
+		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, start, end).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindRecordTimes 查询用户在日期范围内的全部记录时间（按时间升序）
+func (r *recordRepository) FindRecordTimes(ctx context.Context, userID uint64, start, end time.Time) ([]time.Time, error) {
+	var recordTimes []time.Time
+	if err := r.db.WithContext(ctx).Model(&model.Record{}).
+		Where("user_id = ? AND record_time BETWEEN ? AND ?", userID, start, end).
+		Order("record_time ASC").
+		Pluck("record_time", &recordTimes).Error; err != nil {
+		return nil, err
+	}
+	return recordTimes, nil
+}
+
 // 为了兼容接口，保留原来的方法但内部调用新方法
 func (r *recordRepository) GetRankingByUserIDs(ctx context.Context, userIDs []uint64, startDate, endDate time.Time, offset, limit int) ([]*entity.RankingItem, int, error) {
 	page := offset/limit + 1
-	return r.GetFriendRanking(ctx, userIDs, startDate, endDate, page, limit)
+	return r.GetFriendRanking(ctx, entity.RankingMetricCount, userIDs, entity.RecordVisibilitiesFriends, startDate, endDate, page, limit)
+}
+
+// GetDailyCounts 按天汇总指定用户在时间段内的记录，只返回有记录的日期，日期与localDateExpr一样按东八区计算
+// 小组成员不一定互为好友，与小组排行榜一样只统计公开的记录
+func (r *recordRepository) GetDailyCounts(ctx context.Context, userIDs []uint64, start, end time.Time) ([]*entity.DailyRecordCount, error) {
+	if len(userIDs) == 0 {
+		return []*entity.DailyRecordCount{}, nil
+	}
+
+	var rows []struct {
+		Day           string
+		RecordCount   int64
+		TotalDuration int64
+		ActiveUsers   int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Record{}).
+		Select("DATE_FORMAT(CONVERT_TZ(record_time, ?, '+08:00'), '%Y-%m-%d') AS day, COUNT(*) AS record_count, "+
+			"COALESCE(SUM(duration), 0) AS total_duration, COUNT(DISTINCT user_id) AS active_users", appZoneOffset()).
+		Where("user_id IN ?", userIDs).
+		Where("record_time BETWEEN ? AND ?", start, end).
+		Where("visibility IN ?", entity.RecordVisibilitiesPublic).
+		Where("id NOT IN (?)", r.flaggedRecordIDs(ctx)).
+		Group("day").
+		Order("day").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make([]*entity.DailyRecordCount, 0, len(rows))
+	for _, row := range rows {
+		day, err := time.ParseInLocation("2006-01-02", row.Day, start.Location())
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, &entity.DailyRecordCount{
+			Date:          day,
+			RecordCount:   row.RecordCount,
+			TotalDuration: row.TotalDuration,
+			ActiveUsers:   row.ActiveUsers,
+		})
+	}
+	return counts, nil
+}
+
+// squadRankingOrder 小组排行榜排序：指标值降序，相同时记录次数多的在前，再按小组ID升序保证结果稳定
+const squadRankingOrder = "metric_value DESC, record_count DESC, squad_id ASC"
+
+// GetSquadRanking 分页获取小组排行榜，只包含时间段内有记录的小组
+// 成员统计复用排行榜的统计子查询，设置为不参与排行的成员不计入记录数，但计入人均的分母
+func (r *recordRepository) GetSquadRanking(ctx context.Context, metric string, start, end time.Time, page, pageSize int) ([]*entity.SquadRankingItem, int, error) {
+	var metricExpr string
+	switch metric {
+	case entity.SquadMetricCount:
+		metricExpr = "t.record_count"
+	case entity.SquadMetricAvgCount:
+		metricExpr = "ROUND(t.record_count / t.member_count, 2)"
+	default:
+		return nil, 0, fmt.Errorf("不支持的小组排行榜指标: %s", metric)
+	}
+
+	excluded := r.db.WithContext(ctx).Model(&model.RankingSetting{}).
+		Select("user_id").
+		Where("visibility = ?", entity.RankingVisibilityExcluded)
+
+	totals := r.db.WithContext(ctx).Table("squad_members AS m").
+		Joins("LEFT JOIN (?) AS s ON s.user_id = m.user_id AND m.user_id NOT IN (?)", r.rankingStatsQuery(ctx, nil, entity.RecordVisibilitiesPublic, start, end), excluded).
+		Select("m.squad_id AS squad_id, COUNT(*) AS member_count, " +
+			"COALESCE(SUM(s.record_count), 0) AS record_count, COALESCE(SUM(s.total_duration), 0) AS total_duration").
+		Group("m.squad_id")
+
+	base := r.db.WithContext(ctx).Table("(?) AS t", totals).
+		Joins("JOIN squads AS sq ON sq.id = t.squad_id").
+		Select("t.squad_id AS squad_id, sq.name AS name, t.member_count AS member_count, t.record_count AS record_count, " +
+			"t.total_duration AS total_duration, " + metricExpr + " AS metric_value").
+		Where("t.record_count > 0")
+
+	// 1. 计算上榜小组数
+	var total int64
+	if err := r.db.WithContext(ctx).Table("(?) AS b", base).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 2. 分页查询
+	ranked := r.db.WithContext(ctx).Table("(?) AS b", base).
+		Select("b.*, DENSE_RANK() OVER (ORDER BY metric_value DESC) AS `rank`, " +
+			"ROW_NUMBER() OVER (ORDER BY " + squadRankingOrder + ") AS row_num")
+
+	offset := (page - 1) * pageSize
+	var items []*entity.SquadRankingItem
+	if err := r.db.WithContext(ctx).Table("(?) AS ranked", ranked).
+		Order("row_num ASC").Offset(offset).Limit(pageSize).
+		Scan(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for _, item := range items {
+		item.Metric = metric
+	}
+	return items, int(total), nil
+}
+
+// FindAllCounted 查询用户计入统计的全部记录（排除被反作弊标记的记录，按时间升序）
+func (r *recordRepository) FindAllCounted(ctx context.Context, userID uint64) ([]*entity.Record, error) {
+	var recordModels []model.Record
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("id NOT IN (?)", r.flaggedRecordIDs(ctx)).
+		Order("record_time ASC, id ASC").
+		Find(&recordModels).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]*entity.Record, len(recordModels))
+	for i, recordModel := range recordModels {
+		records[i] = recordModel.ToEntity()
+	}
+	return records, nil
+}
+
+// FindSameMinuteUserIDs 查询与用户在[start, end)内的记录处于同一分钟的其他用户
+// 双方的记录都不能是仅自己可见，且不能被反作弊标记；对方的记录按所在分钟的起止时间范围匹配
+func (r *recordRepository) FindSameMinuteUserIDs(ctx context.Context, userID uint64, otherIDs []uint64, start, end time.Time) ([]uint64, error) {
+	if len(otherIDs) == 0 {
+		return []uint64{}, nil
+	}
+
+	const minuteExpr = "CAST(DATE_FORMAT(a.record_time, '%Y-%m-%d %H:%i:00') AS DATETIME)"
+	var ids []uint64
+	err := r.db.WithContext(ctx).Table("records AS a").
+		Joins("JOIN records AS b ON b.user_id IN ? AND b.record_time >= "+minuteExpr+" AND b.record_time < "+minuteExpr+" + INTERVAL 1 MINUTE", otherIDs).
+		Where("a.user_id = ? AND a.record_time >= ? AND a.record_time < ?", userID, start, end).
+		Where("a.visibility <> ? AND b.visibility <> ?", entity.RecordVisibilityPrivate, entity.RecordVisibilityPrivate).
+		Where("a.id NOT IN (?) AND b.id NOT IN (?)", r.flaggedRecordIDs(ctx), r.flaggedRecordIDs(ctx)).
+		Distinct("b.user_id").
+		Pluck("b.user_id", &ids).Error
+	return ids, err
 }
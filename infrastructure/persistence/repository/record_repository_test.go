@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"record-project/domain/entity"
+	"record-project/infrastructure/persistence/model"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// benchDSNEnv 基准测试使用的MySQL连接串（需要MySQL 8，排行榜查询依赖窗口函数）
+// 未设置时跳过基准测试；请使用单独的测试库，测试会清理并重新写入open_id以bench-开头的用户及其记录
+const benchDSNEnv = "RECORD_BENCH_MYSQL_DSN"
+
+// benchFriendSizes 基准测试的好友人数
+var benchFriendSizes = []int{50, 200, 1000}
+
+// benchRecordsPerUser 每个用户在统计周期内的最多记录数
+const benchRecordsPerUser = 40
+
+// benchPageSize 每页人数
+const benchPageSize = 20
+
+var (
+	benchOnce    sync.Once
+	benchDB      *gorm.DB
+	benchUserIDs []uint64
+	benchErr     error
+)
+
+// benchPeriod 统计周期
+func benchPeriod() (time.Time, time.Time) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// openBenchDB 连接测试库并写入数据，所有基准测试共用同一份数据
+func openBenchDB(b *testing.B) (*gorm.DB, []uint64) {
+	dsn := os.Getenv(benchDSNEnv)
+	if dsn == "" {
+		b.Skipf("未设置%s，跳过基准测试", benchDSNEnv)
+	}
+
+	benchOnce.Do(func() {
+		benchDB, benchErr = gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger:                                   logger.Default.LogMode(logger.Silent),
+			NamingStrategy:                           schema.NamingStrategy{SingularTable: true},
+			DisableForeignKeyConstraintWhenMigrating: true,
+		})
+		if benchErr != nil {
+			return
+		}
+		benchUserIDs, benchErr = seedBenchData(benchDB, benchFriendSizes[len(benchFriendSizes)-1])
+	})
+	if benchErr != nil {
+		b.Fatalf("准备测试数据失败: %v", benchErr)
+	}
+	return benchDB, benchUserIDs
+}
+
+// seedBenchData 写入users个用户，每个用户在统计周期内有0到benchRecordsPerUser条随机记录
+func seedBenchData(db *gorm.DB, users int) ([]uint64, error) {
+	if err := db.AutoMigrate(&model.User{}, &model.Record{}, &model.RecordFlag{}); err != nil {
+		return nil, err
+	}
+
+	benchUsers := db.Model(&model.User{}).Select("id").Where("open_id LIKE ?", "bench-%")
+	if err := db.Where("user_id IN (?)", benchUsers).Delete(&model.Record{}).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("open_id LIKE ?", "bench-%").Delete(&model.User{}).Error; err != nil {
+		return nil, err
+	}
+
+	userModels := make([]model.User, users)
+	for i := range userModels {
+		userModels[i] = model.User{OpenID: fmt.Sprintf("bench-%d", i), Nickname: fmt.Sprintf("用户%d", i), Status: 1}
+	}
+	if err := db.CreateInBatches(userModels, 500).Error; err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	start, _ := benchPeriod()
+	userIDs := make([]uint64, users)
+	var records []model.Record
+	for i, user := range userModels {
+		userIDs[i] = user.ID
+		for n := rng.Intn(benchRecordsPerUser + 1); n > 0; n-- {
+			records = append(records, model.Record{
+				UserID:     user.ID,
+				RecordTime: start.Add(time.Duration(rng.Int63n(int64(31 * 24 * time.Hour)))),
+				Duration:   60 + rng.Intn(900),
+				PoopTypeID: uint64(1 + rng.Intn(7)),
+				Visibility: entity.RecordVisibilityFriends,
+			})
+		}
+	}
+	if err := db.CreateInBatches(records, 1000).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// legacyFriendRanking 改为窗口函数之前的做法：一次GROUP BY查询全部好友的统计，在Go中排序、计算名次后截取一页
+// 统计子查询与当前实现相同，两者的差别只在排序和分页的位置
+func legacyFriendRanking(ctx context.Context, r *recordRepository, userIDs []uint64, startDate, endDate time.Time, page, pageSize int) ([]*entity.RankingItem, int, error) {
+	var stats []*entity.RankingItem
+	if err := r.rankingStatsQuery(ctx, userIDs, entity.RecordVisibilitiesFriends, startDate, endDate).Scan(&stats).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := make(map[uint64]*entity.RankingItem, len(userIDs))
+	all := make([]*entity.RankingItem, 0, len(userIDs))
+	for _, userID := range userIDs {
+		item := &entity.RankingItem{UserID: userID, Metric: entity.RankingMetricCount}
+		items[userID] = item
+		all = append(all, item)
+	}
+	for _, stat := range stats {
+		item := items[stat.UserID]
+		item.RecordCount = stat.RecordCount
+		item.TotalDuration = stat.TotalDuration
+		item.MetricValue = float64(stat.RecordCount)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].MetricValue != all[j].MetricValue {
+			return all[i].MetricValue > all[j].MetricValue
+		}
+		if all[i].RecordCount != all[j].RecordCount {
+			return all[i].RecordCount > all[j].RecordCount
+		}
+		return all[i].UserID < all[j].UserID
+	})
+	var rank uint64
+	for i, item := range all {
+		if i == 0 || item.MetricValue != all[i-1].MetricValue {
+			rank++
+		}
+		item.Rank = rank
+	}
+
+	offset := (page - 1) * pageSize
+	if offset >= len(all) {
+		return []*entity.RankingItem{}, len(all), nil
+	}
+	end := offset + pageSize
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[offset:end], len(all), nil
+}
+
+// BenchmarkGetFriendRankingLoadAndSort 旧实现：读取全部好友的统计后在Go中排序分页
+func BenchmarkGetFriendRankingLoadAndSort(b *testing.B) {
+	db, userIDs := openBenchDB(b)
+	start, end := benchPeriod()
+	ctx := context.Background()
+	repo := &recordRepository{db: db}
+
+	for _, size := range benchFriendSizes {
+		b.Run(fmt.Sprintf("friends=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := legacyFriendRanking(ctx, repo, userIDs[:size], start, end, 1, benchPageSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGetFriendRankingWindowFunction 当前实现：DENSE_RANK/ROW_NUMBER在数据库中排序分页
+func BenchmarkGetFriendRankingWindowFunction(b *testing.B) {
+	db, userIDs := openBenchDB(b)
+	start, end := benchPeriod()
+	ctx := context.Background()
+	repo := NewRecordRepository(db)
+
+	for _, size := range benchFriendSizes {
+		b.Run(fmt.Sprintf("friends=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repo.GetFriendRanking(ctx, entity.RankingMetricCount, userIDs[:size], entity.RecordVisibilitiesFriends, start, end, 1, benchPageSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
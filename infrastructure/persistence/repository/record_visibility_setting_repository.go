@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordVisibilitySettingRepository 记录默认可见范围设置仓储实现
+type recordVisibilitySettingRepository struct {
+	db *gorm.DB
+}
+
+// NewRecordVisibilitySettingRepository 创建记录默认可见范围设置仓储
+func NewRecordVisibilitySettingRepository(db *gorm.DB) repository.RecordVisibilitySettingRepository {
+	return &recordVisibilitySettingRepository{db: db}
+}
+
+// FindByUserID 查询用户的默认可见范围设置
+func (r *recordVisibilitySettingRepository) FindByUserID(ctx context.Context, userID uint64) (*entity.RecordVisibilitySetting, error) {
+	var settingModel model.RecordVisibilitySetting
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settingModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return settingModel.ToEntity(), nil
+}
+
+// Save 保存用户的默认可见范围设置，已存在时覆盖
+func (r *recordVisibilitySettingRepository) Save(ctx context.Context, setting *entity.RecordVisibilitySetting) error {
+	setting.UpdatedAt = time.Now()
+
+	var settingModel model.RecordVisibilitySetting
+	settingModel.FromEntity(setting)
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"visibility", "circle_id", "updated_at"}),
+	}).Create(&settingModel).Error
+}
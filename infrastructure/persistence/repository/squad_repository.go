@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// squadRepository 小组仓储实现
+type squadRepository struct {
+	db *gorm.DB
+}
+
+// NewSquadRepository 创建小组仓储
+func NewSquadRepository(db *gorm.DB) repository.SquadRepository {
+	return &squadRepository{db: db}
+}
+
+// findOne 按条件查找小组并统计成员数
+func (r *squadRepository) findOne(ctx context.Context, query string, args ...interface{}) (*entity.Squad, error) {
+	var squadModel model.Squad
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&squadModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	squad := squadModel.ToEntity()
+	if err := r.db.WithContext(ctx).Model(&model.SquadMember{}).
+		Where("squad_id = ?", squad.ID).
+		Count(&squad.MemberCount).Error; err != nil {
+		return nil, err
+	}
+	return squad, nil
+}
+
+// FindByID 查找小组并统计成员数
+func (r *squadRepository) FindByID(ctx context.Context, id uint64) (*entity.Squad, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+// FindByInviteCode 根据邀请码查找小组并统计成员数
+func (r *squadRepository) FindByInviteCode(ctx context.Context, code string) (*entity.Squad, error) {
+	return r.findOne(ctx, "invite_code = ?", code)
+}
+
+// FindByMemberID 查询用户加入的小组，并填写用户在各小组中的角色
+func (r *squadRepository) FindByMemberID(ctx context.Context, userID uint64) ([]*entity.Squad, error) {
+	var rows []struct {
+		model.Squad
+		MyRole string
+	}
+	if err := r.db.WithContext(ctx).Table("squads AS sq").
+		Select("sq.*, m.role AS my_role").
+		Joins("JOIN squad_members AS m ON m.squad_id = sq.id").
+		Where("m.user_id = ?", userID).
+		Order("m.joined_at DESC, m.id DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []*entity.Squad{}, nil
+	}
+
+	squadIDs := make([]uint64, len(rows))
+	for i, row := range rows {
+		squadIDs[i] = row.ID
+	}
+
+	var counts []struct {
+		SquadID uint64
+		Total   int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.SquadMember{}).
+		Select("squad_id, COUNT(*) AS total").
+		Where("squad_id IN ?", squadIDs).
+		Group("squad_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	countMap := make(map[uint64]int64, len(counts))
+	for _, count := range counts {
+		countMap[count.SquadID] = count.Total
+	}
+
+	squads := make([]*entity.Squad, len(rows))
+	for i := range rows {
+		squads[i] = rows[i].Squad.ToEntity()
+		squads[i].MemberCount = countMap[rows[i].ID]
+		squads[i].MyRole = rows[i].MyRole
+	}
+	return squads, nil
+}
+
+// CountByMemberID 统计用户加入的小组数
+func (r *squadRepository) CountByMemberID(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.SquadMember{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Create 在同一事务中保存小组和创建者的成员信息
+func (r *squadRepository) Create(ctx context.Context, squad *entity.Squad) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var squadModel model.Squad
+		squadModel.FromEntity(squad)
+		if err := tx.Create(&squadModel).Error; err != nil {
+			return err
+		}
+
+		ownerModel := model.SquadMember{SquadID: squadModel.ID, UserID: squad.OwnerID, Role: entity.SquadRoleOwner}
+		if err := tx.Create(&ownerModel).Error; err != nil {
+			return err
+		}
+
+		squad.ID = squadModel.ID
+		squad.CreatedAt = squadModel.CreatedAt
+		squad.UpdatedAt = squadModel.UpdatedAt
+		squad.MemberCount = 1
+		return nil
+	})
+}
+
+// Update 更新小组名称和成员上限
+func (r *squadRepository) Update(ctx context.Context, squad *entity.Squad) error {
+	return r.db.WithContext(ctx).Model(&model.Squad{}).
+		Where("id = ?", squad.ID).
+		Updates(map[string]interface{}{
+			"name":       squad.Name,
+			"member_cap": squad.MemberCap,
+		}).Error
+}
+
+// UpdateInviteCode 更换邀请码
+func (r *squadRepository) UpdateInviteCode(ctx context.Context, squadID uint64, code string) error {
+	return r.db.WithContext(ctx).Model(&model.Squad{}).Where("id = ?", squadID).Update("invite_code", code).Error
+}
+
+// Delete 删除小组及其成员
+func (r *squadRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("squad_id = ?", id).Delete(&model.SquadMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Squad{}, id).Error
+	})
+}
+
+// FindMember 查询用户在小组中的成员信息
+func (r *squadRepository) FindMember(ctx context.Context, squadID, userID uint64) (*entity.SquadMember, error) {
+	var memberModel model.SquadMember
+	if err := r.db.WithContext(ctx).Where("squad_id = ? AND user_id = ?", squadID, userID).First(&memberModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return memberModel.ToEntity(), nil
+}
+
+// FindMembers 查询小组的全部成员，创建者和管理员在前
+func (r *squadRepository) FindMembers(ctx context.Context, squadID uint64) ([]*entity.SquadMember, error) {
+	var memberModels []model.SquadMember
+	if err := r.db.WithContext(ctx).Where("squad_id = ?", squadID).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "FIELD(role, ?, ?, ?), joined_at, id",
+			Vars: []interface{}{entity.SquadRoleOwner, entity.SquadRoleAdmin, entity.SquadRoleMember},
+		}}).
+		Find(&memberModels).Error; err != nil {
+		return nil, err
+	}
+
+	members := make([]*entity.SquadMember, len(memberModels))
+	for i := range memberModels {
+		members[i] = memberModels[i].ToEntity()
+	}
+	return members, nil
+}
+
+// FindMemberIDs 查询小组的全部成员ID
+func (r *squadRepository) FindMemberIDs(ctx context.Context, squadID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.WithContext(ctx).Model(&model.SquadMember{}).Where("squad_id = ?", squadID).Order("id").Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// AddMember 加入小组，锁定小组行后再统计人数，避免并发加入时超出上限
+func (r *squadRepository) AddMember(ctx context.Context, member *entity.SquadMember) (bool, error) {
+	added := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var squadModel model.Squad
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&squadModel, member.SquadID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.SquadMember{}).Where("squad_id = ?", member.SquadID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(squadModel.MemberCap) {
+			return nil
+		}
+
+		var memberModel model.SquadMember
+		memberModel.FromEntity(member)
+		if err := tx.Create(&memberModel).Error; err != nil {
+			return err
+		}
+		member.ID = memberModel.ID
+		member.JoinedAt = memberModel.JoinedAt
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+// UpdateMemberRole 修改成员角色
+func (r *squadRepository) UpdateMemberRole(ctx context.Context, squadID, userID uint64, role string) error {
+	return r.db.WithContext(ctx).Model(&model.SquadMember{}).
+		Where("squad_id = ? AND user_id = ?", squadID, userID).
+		Update("role", role).Error
+}
+
+// RemoveMember 移除成员
+func (r *squadRepository) RemoveMember(ctx context.Context, squadID, userID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Where("squad_id = ? AND user_id = ?", squadID, userID).Delete(&model.SquadMember{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"record-project/domain/entity"
+	"record-project/domain/repository"
+	"record-project/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// userBadgeRepository 用户徽章仓储实现
+type userBadgeRepository struct {
+	db *gorm.DB
+}
+
+// NewUserBadgeRepository 创建用户徽章仓储
+func NewUserBadgeRepository(db *gorm.DB) repository.UserBadgeRepository {
+	return &userBadgeRepository{db: db}
+}
+
+// Award 保存获得的徽章，依赖唯一索引保证同一徽章只获得一次
+func (r *userBadgeRepository) Award(ctx context.Context, badge *entity.UserBadge) (bool, error) {
+	var badgeModel model.UserBadge
+	badgeModel.FromEntity(badge)
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&badgeModel)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	badge.ID = badgeModel.ID
+	badge.CreatedAt = badgeModel.CreatedAt
+	return result.RowsAffected > 0, nil
+}
+
+// FindByUserID 查询用户获得的全部徽章
+func (r *userBadgeRepository) FindByUserID(ctx context.Context, userID uint64) ([]*entity.UserBadge, error) {
+	var badgeModels []model.UserBadge
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("awarded_at DESC, id DESC").
+		Find(&badgeModels).Error; err != nil {
+		return nil, err
+	}
+
+	badges := make([]*entity.UserBadge, len(badgeModels))
+	for i := range badgeModels {
+		badges[i] = badgeModels[i].ToEntity()
+	}
+	return badges, nil
+}
+
+// FindCodesByUserID 查询用户已获得的徽章代码
+func (r *userBadgeRepository) FindCodesByUserID(ctx context.Context, userID uint64) (map[string]bool, error) {
+	var codes []string
+	if err := r.db.WithContext(ctx).Model(&model.UserBadge{}).Where("user_id = ?", userID).Pluck("badge_code", &codes).Error; err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		owned[code] = true
+	}
+	return owned, nil
+}
@@ -103,7 +103,8 @@ func (r *userRepository) SearchUsers(ctx context.Context, keyword string, page,
 
 	// 构建查询条件
 	query := r.db.WithContext(ctx).Model(&model.User{}).
-		Where("id != ?", excludeUserID). // 排除当前用户
+		Where("id != ?", excludeUserID).                                              // 排除当前用户
+		Where("id NOT IN (?)", blockedUserIDs(r.db.WithContext(ctx), excludeUserID)). // 排除存在屏蔽关系的用户
 		Where("nickname LIKE ?", "%"+keyword+"%")
 
 	// 计算总数
@@ -133,7 +134,8 @@ func (r *userRepository) SearchUsersExcludeFriends(ctx context.Context, keyword
 
 	// 构建查询条件
 	query := r.db.WithContext(ctx).Model(&model.User{}).
-		Where("id != ?", excludeUserID) // 排除当前用户
+		Where("id != ?", excludeUserID).                                             // 排除当前用户
+		Where("id NOT IN (?)", blockedUserIDs(r.db.WithContext(ctx), excludeUserID)) // 排除存在屏蔽关系的用户
 
 	// 如果有好友ID列表，排除这些ID
 	if len(friendIDs) > 0 {
@@ -162,3 +164,15 @@ func (r *userRepository) SearchUsersExcludeFriends(ctx context.Context, keyword
 
 	return users, total, nil
 }
+
+// FindAllIDs 获取所有正常状态用户的ID
+func (r *userRepository) FindAllIDs(ctx context.Context) ([]uint64, error) {
+	var ids []uint64
+	if err := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("status = ?", 1).
+		Order("id ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
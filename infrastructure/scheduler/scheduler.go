@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// job 定时任务
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// Scheduler 简单的进程内定时任务调度器
+// 任务需要自行保证幂等，调度器只负责按固定间隔触发
+type Scheduler struct {
+	jobs   []job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler 创建定时任务调度器
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// AddJob 注册定时任务，需在Start之前调用
+func (s *Scheduler) AddJob(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, job{
+		name:     name,
+		interval: interval,
+		run:      run,
+	})
+}
+
+// Start 启动所有定时任务，任务启动时立即执行一次
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Stop 停止所有定时任务并等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop 按间隔循环执行任务
+func (s *Scheduler) loop(j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	s.execute(j)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.execute(j)
+		}
+	}
+}
+
+// execute 执行一次任务，捕获panic避免影响其他任务
+func (s *Scheduler) execute(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("定时任务[%s]发生panic: %v", j.name, r)
+		}
+	}()
+
+	start := time.Now()
+	if err := j.run(s.ctx); err != nil {
+		log.Printf("定时任务[%s]执行失败: %v", j.name, err)
+		return
+	}
+	log.Printf("定时任务[%s]执行完成，耗时%s", j.name, time.Since(start))
+}
@@ -1,29 +1,57 @@
 package wechat
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
+// DefaultAPIBaseURL 微信开放接口地址
+const DefaultAPIBaseURL = "https://api.weixin.qq.com"
+
+// accessTokenRefreshAhead 提前刷新access_token的时间，避免临近过期时调用失败
+const accessTokenRefreshAhead = 5 * time.Minute
+
 // WechatService 微信服务接口
 type WechatService interface {
 	Code2Session(code string) (*Code2SessionResponse, error)
+
+	// GetUnlimitedQRCode 生成带scene参数的小程序码，返回图片内容和图片类型
+	GetUnlimitedQRCode(scene, page string, width int) ([]byte, string, error)
 }
 
 // wechatService 微信服务实现
 type wechatService struct {
-	appID     string
-	appSecret string
+	appID      string
+	appSecret  string
+	apiBaseURL string
+	httpClient *http.Client
+
+	// tokenMu 保护access_token缓存
+	tokenMu         sync.Mutex
+	accessToken     string
+	accessTokenTill time.Time
 }
 
-// NewWechatService 创建微信服务
-func NewWechatService(appID, appSecret string) WechatService {
+// NewWechatService 创建微信服务，apiBaseURL为空时使用微信官方接口地址
+// 测试时可以指向本地的模拟服务
+func NewWechatService(appID, appSecret, apiBaseURL string) WechatService {
+	if apiBaseURL == "" {
+		apiBaseURL = DefaultAPIBaseURL
+	}
 	return &wechatService{
-		appID:     appID,
-		appSecret: appSecret,
+		appID:      appID,
+		appSecret:  appSecret,
+		apiBaseURL: strings.TrimRight(apiBaseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
@@ -38,10 +66,10 @@ type Code2SessionResponse struct {
 
 // Code2Session 微信登录，用code换取openid和session_key
 func (s *wechatService) Code2Session(code string) (*Code2SessionResponse, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/sns/jscode2session?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code",
-		s.appID, s.appSecret, code)
+	url := fmt.Sprintf("%s/sns/jscode2session?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code",
+		s.apiBaseURL, s.appID, s.appSecret, code)
 
-	resp, err := http.Get(url)
+	resp, err := s.httpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -63,3 +91,107 @@ func (s *wechatService) Code2Session(code string) (*Code2SessionResponse, error)
 
 	return &result, nil
 }
+
+// apiError 微信接口的错误返回
+type apiError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// accessTokenResponse 获取access_token的返回结果
+type accessTokenResponse struct {
+	apiError
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// getAccessToken 获取接口调用凭证，有效期内复用缓存
+func (s *wechatService) getAccessToken() (string, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.accessTokenTill) {
+		return s.accessToken, nil
+	}
+
+	query := url.Values{}
+	query.Set("grant_type", "client_credential")
+	query.Set("appid", s.appID)
+	query.Set("secret", s.appSecret)
+
+	resp, err := s.httpClient.Get(s.apiBaseURL + "/cgi-bin/token?" + query.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result accessTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.ErrCode != 0 || result.AccessToken == "" {
+		return "", fmt.Errorf("获取access_token失败: %d %s", result.ErrCode, result.ErrMsg)
+	}
+
+	s.accessToken = result.AccessToken
+	s.accessTokenTill = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - accessTokenRefreshAhead)
+	return s.accessToken, nil
+}
+
+// resetAccessToken 清除缓存的access_token，下次调用时重新获取
+func (s *wechatService) resetAccessToken() {
+	s.tokenMu.Lock()
+	s.accessToken = ""
+	s.tokenMu.Unlock()
+}
+
+// GetUnlimitedQRCode 生成带scene参数的小程序码
+// 成功时接口直接返回图片，失败时返回JSON格式的错误信息
+func (s *wechatService) GetUnlimitedQRCode(scene, page string, width int) ([]byte, string, error) {
+	token, err := s.getAccessToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"scene":      scene,
+		"page":       page,
+		"width":      width,
+		"check_path": false,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := s.httpClient.Post(s.apiBaseURL+"/wxa/getwxacodeunlimit?access_token="+url.QueryEscape(token), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/plain") || bytes.HasPrefix(body, []byte("{")) {
+		var result apiError
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, "", err
+		}
+		// access_token失效时清除缓存，下次调用会重新获取
+		if result.ErrCode == 40001 || result.ErrCode == 42001 {
+			s.resetAccessToken()
+		}
+		return nil, "", fmt.Errorf("生成小程序码失败: %d %s", result.ErrCode, result.ErrMsg)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("生成小程序码失败: HTTP %d", resp.StatusCode)
+	}
+
+	if contentType == "" {
+		contentType = "image/jpeg"
+	}
+	return body, contentType, nil
+}
@@ -23,7 +23,8 @@ func NewAuthHandler(authService service.AuthService) *AuthHandler {
 // WechatLogin 微信登录
 func (h *AuthHandler) WechatLogin(c *gin.Context) {
 	var request struct {
-		Code string `json:"code" binding:"required"`
+		Code  string `json:"code" binding:"required"`
+		Scene string `json:"scene"` // 通过邀请小程序码进入时的scene参数
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -31,7 +32,7 @@ func (h *AuthHandler) WechatLogin(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authService.WechatLogin(c, request.Code)
+	user, token, err := h.authService.WechatLogin(c, request.Code, request.Scene)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"record-project/application/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BadgeHandler 徽章API处理器
+type BadgeHandler struct {
+	badgeService service.BadgeService
+	authService  service.AuthService
+}
+
+// NewBadgeHandler 创建徽章API处理器
+func NewBadgeHandler(badgeService service.BadgeService, authService service.AuthService) *BadgeHandler {
+	return &BadgeHandler{
+		badgeService: badgeService,
+		authService:  authService,
+	}
+}
+
+// GetBadges 获取全部徽章定义和当前用户已获得的徽章
+func (h *BadgeHandler) GetBadges(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	badges, err := h.badgeService.GetUserBadges(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取徽章失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"catalog": h.badgeService.GetCatalog(),
+		"awarded": badges,
+	})
+}
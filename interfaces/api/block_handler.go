@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockHandler 屏蔽API处理器
+type BlockHandler struct {
+	blockService service.BlockService
+	authService  service.AuthService
+}
+
+// NewBlockHandler 创建屏蔽API处理器
+func NewBlockHandler(blockService service.BlockService, authService service.AuthService) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+		authService:  authService,
+	}
+}
+
+// GetBlockedUsers 获取屏蔽列表
+func (h *BlockHandler) GetBlockedUsers(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	blocks, total, err := h.blockService.GetBlockedUsers(c, userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取屏蔽列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"blocks":    blocks,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// BlockUser 屏蔽用户
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		UserID uint64 `json:"user_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	block, err := h.blockService.BlockUser(c, userID, request.UserID)
+	if err != nil {
+		h.handleError(c, "屏蔽用户失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, block)
+}
+
+// UnblockUser 解除屏蔽
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	if err := h.blockService.UnblockUser(c, userID, targetID); err != nil {
+		h.handleError(c, "解除屏蔽失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已解除屏蔽"})
+}
+
+// handleError 根据错误类型返回对应的状态码
+func (h *BlockHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrBlockUserNotFound), errors.Is(err, service.ErrBlockNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": message + ": " + err.Error()})
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChallengeHandler 好友挑战API处理器
+type ChallengeHandler struct {
+	challengeService service.ChallengeService
+	authService      service.AuthService
+}
+
+// NewChallengeHandler 创建好友挑战API处理器
+func NewChallengeHandler(challengeService service.ChallengeService, authService service.AuthService) *ChallengeHandler {
+	return &ChallengeHandler{
+		challengeService: challengeService,
+		authService:      authService,
+	}
+}
+
+// CreateChallenge 创建挑战并邀请好友
+func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Title      string    `json:"title" binding:"required"`
+		Goal       string    `json:"goal" binding:"required"`
+		StartAt    time.Time `json:"start_at"` // 为空时立即开始
+		EndAt      time.Time `json:"end_at" binding:"required"`
+		InviteeIDs []uint64  `json:"invitee_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	challenge, err := h.challengeService.CreateChallenge(c, userID, request.Title, request.Goal, request.StartAt, request.EndAt, request.InviteeIDs, time.Now())
+	if err != nil {
+		h.handleError(c, "创建挑战失败", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, challenge)
+}
+
+// GetChallenges 获取挑战列表，status=history时返回已结束的挑战
+func (h *ChallengeHandler) GetChallenges(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+	history := c.Query("status") == "history"
+
+	challenges, total, err := h.challengeService.GetChallenges(c, userID, history, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取挑战失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"challenges": challenges,
+		"total":      total,
+		"page":       page,
+		"page_size":  pageSize,
+	})
+}
+
+// GetChallenge 获取挑战详情和排名
+func (h *ChallengeHandler) GetChallenge(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	challengeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的挑战ID"})
+		return
+	}
+
+	detail, err := h.challengeService.GetChallenge(c, userID, challengeID)
+	if err != nil {
+		h.handleError(c, "获取挑战失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// Accept 接受挑战邀请
+func (h *ChallengeHandler) Accept(c *gin.Context) {
+	h.respond(c, true)
+}
+
+// Decline 拒绝挑战邀请
+func (h *ChallengeHandler) Decline(c *gin.Context) {
+	h.respond(c, false)
+}
+
+// respond 回应挑战邀请
+func (h *ChallengeHandler) respond(c *gin.Context, accept bool) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	challengeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的挑战ID"})
+		return
+	}
+
+	if err := h.challengeService.Respond(c, userID, challengeID, accept, time.Now()); err != nil {
+		h.handleError(c, "回应挑战邀请失败", err)
+		return
+	}
+
+	message := "已拒绝挑战"
+	if accept {
+		message = "已参加挑战"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// handleError 根据错误类型返回对应的状态码
+func (h *ChallengeHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrChallengeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrChallengeInviteeNotFriend):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrChallengeEnded), errors.Is(err, service.ErrChallengeNotInvited):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrChallengeTitleInvalid), errors.Is(err, service.ErrChallengeGoalInvalid),
+		errors.Is(err, service.ErrChallengeTimeInvalid), errors.Is(err, service.ErrChallengeParticipantsInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message + ": " + err.Error()})
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedHandler 好友动态API处理器
+type FeedHandler struct {
+	feedService service.FeedService
+	authService service.AuthService
+}
+
+// NewFeedHandler 创建好友动态API处理器
+func NewFeedHandler(feedService service.FeedService, authService service.AuthService) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+		authService: authService,
+	}
+}
+
+// GetFeed 获取好友动态，使用上一页返回的next_cursor翻页
+func (h *FeedHandler) GetFeed(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	page, err := h.feedService.GetFeed(c, userID, c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrFeedCursorInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取好友动态失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
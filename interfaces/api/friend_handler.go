@@ -1,26 +1,35 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"record-project/application/service"
+	"record-project/domain/entity"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // FriendHandler 好友API处理器
 type FriendHandler struct {
-	friendService service.FriendService
-	authService   service.AuthService
-	userService   service.UserService
+	friendService     service.FriendService
+	authService       service.AuthService
+	userService       service.UserService
+	suggestionService service.FriendSuggestionService
+	circleService     service.CircleService
+	nudgeService      service.NudgeService
 }
 
 // NewFriendHandler 创建好友API处理器
-func NewFriendHandler(friendService service.FriendService, authService service.AuthService, userService service.UserService) *FriendHandler {
+func NewFriendHandler(friendService service.FriendService, authService service.AuthService, userService service.UserService, suggestionService service.FriendSuggestionService, circleService service.CircleService, nudgeService service.NudgeService) *FriendHandler {
 	return &FriendHandler{
-		friendService: friendService,
-		authService:   authService,
-		userService:   userService,
+		friendService:     friendService,
+		authService:       authService,
+		userService:       userService,
+		suggestionService: suggestionService,
+		circleService:     circleService,
+		nudgeService:      nudgeService,
 	}
 }
 
@@ -50,8 +59,22 @@ func (h *FriendHandler) GetFriends(c *gin.Context) {
 		}
 	}
 
+	// 按分组筛选
+	var circleID uint64
+	if circleIDStr := c.Query("circle_id"); circleIDStr != "" {
+		circleID, err = strconv.ParseUint(circleIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分组ID"})
+			return
+		}
+		if _, err := h.circleService.GetCircle(c, userID, circleID); err != nil {
+			h.handleCircleError(c, "获取好友列表失败", err)
+			return
+		}
+	}
+
 	// 获取好友列表
-	friends, total, err := h.friendService.GetFriendsByUserIDWithPagination(c, userID, page, pageSize, keyword)
+	friends, total, err := h.friendService.GetFriendsByUserIDWithPagination(c, userID, page, pageSize, keyword, circleID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取好友列表失败: " + err.Error()})
 		return
@@ -104,6 +127,14 @@ func (h *FriendHandler) AddFriend(c *gin.Context) {
 
 	// 添加好友
 	if err := h.friendService.AddFriend(c, userID, request.FriendID); err != nil {
+		if errors.Is(err, service.ErrTooManyPendingRequests) || errors.Is(err, entity.ErrFriendRerequestTooSoon) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrFriendStatusConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "添加好友失败: " + err.Error()})
 		return
 	}
@@ -112,19 +143,18 @@ func (h *FriendHandler) AddFriend(c *gin.Context) {
 }
 
 // UpdateFriendStatus 更新好友状态
+// 当前用户作为操作者，只能执行状态机允许的变更，如接收方同意或拒绝申请
 func (h *FriendHandler) UpdateFriendStatus(c *gin.Context) {
 	// 从请求中获取token，解析用户ID
-	_, err := h.authService.GetUserIDFromToken(c)
+	userID, err := h.authService.GetUserIDFromToken(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
 		return
 	}
 
-	// 解析请求体
+	// 解析请求体，状态可能为0（重新申请），用指针区分未传
 	var request struct {
-		UserID   uint64 `json:"user_id" binding:"required"`
-		FriendID uint64 `json:"friend_id" binding:"required"`
-		Status   int8   `json:"status" binding:"required"`
+		Status *entity.FriendStatus `json:"status" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -133,7 +163,7 @@ func (h *FriendHandler) UpdateFriendStatus(c *gin.Context) {
 	}
 
 	// 验证状态值
-	if request.Status < 0 || request.Status > 3 {
+	if !request.Status.Valid() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的状态值"})
 		return
 	}
@@ -146,8 +176,8 @@ func (h *FriendHandler) UpdateFriendStatus(c *gin.Context) {
 	}
 
 	// 更新好友状态
-	if err := h.friendService.UpdateFriendStatusWithVerification(c, relationID, request.UserID, request.FriendID, request.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新好友状态失败: " + err.Error()})
+	if err := h.friendService.UpdateFriendStatus(c, relationID, userID, *request.Status); err != nil {
+		writeFriendStatusError(c, "更新好友状态失败: ", err)
 		return
 	}
 
@@ -175,13 +205,29 @@ func (h *FriendHandler) DeleteFriend(c *gin.Context) {
 
 	// 删除好友
 	if err := h.friendService.DeleteFriend(c, request.ID, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除好友失败: " + err.Error()})
+		writeFriendStatusError(c, "删除好友失败: ", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "好友已删除"})
 }
 
+// writeFriendStatusError 按好友关系状态变更的错误类型返回对应的状态码
+func writeFriendStatusError(c *gin.Context, prefix string, err error) {
+	switch {
+	case errors.Is(err, service.ErrFriendRelationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrFriendNotParticipant):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrFriendTransitionNotAllowed), errors.Is(err, service.ErrFriendStatusConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrFriendRerequestTooSoon):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": prefix + err.Error()})
+	}
+}
+
 // GetFriendRequests 获取好友申请
 func (h *FriendHandler) GetFriendRequests(c *gin.Context) {
 	// 从请求中获取token，解析用户ID
@@ -221,3 +267,363 @@ func (h *FriendHandler) GetFriendRequests(c *gin.Context) {
 		"page_size": pageSize,
 	})
 }
+
+// GetSentRequests 获取自己发出的待确认好友申请
+func (h *FriendHandler) GetSentRequests(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	requests, total, err := h.friendService.GetSentRequests(c, userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取已发送的好友申请失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requests":  requests,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// WithdrawRequest 撤回自己发出的好友申请
+func (h *FriendHandler) WithdrawRequest(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的好友申请ID"})
+		return
+	}
+
+	if err := h.friendService.WithdrawRequest(c, userID, requestID); err != nil {
+		if errors.Is(err, service.ErrFriendRequestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrFriendStatusConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "撤回好友申请失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "好友申请已撤回"})
+}
+
+// GetSuggestions 获取好友推荐
+func (h *FriendHandler) GetSuggestions(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	suggestions, total, err := h.suggestionService.GetSuggestions(c, userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取好友推荐失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suggestions": suggestions,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+	})
+}
+
+// GetCircles 获取好友分组
+func (h *FriendHandler) GetCircles(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	circles, err := h.circleService.GetCircles(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取分组失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"circles": circles})
+}
+
+// CreateCircle 创建好友分组
+func (h *FriendHandler) CreateCircle(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	circle, err := h.circleService.CreateCircle(c, userID, request.Name)
+	if err != nil {
+		h.handleCircleError(c, "创建分组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, circle)
+}
+
+// UpdateCircle 修改好友分组名称
+func (h *FriendHandler) UpdateCircle(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	circleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分组ID"})
+		return
+	}
+
+	var request struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	circle, err := h.circleService.RenameCircle(c, userID, circleID, request.Name)
+	if err != nil {
+		h.handleCircleError(c, "修改分组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, circle)
+}
+
+// DeleteCircle 删除好友分组
+func (h *FriendHandler) DeleteCircle(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	circleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分组ID"})
+		return
+	}
+
+	if err := h.circleService.DeleteCircle(c, userID, circleID); err != nil {
+		h.handleCircleError(c, "删除分组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "分组已删除"})
+}
+
+// ReorderCircles 调整好友分组的顺序
+func (h *FriendHandler) ReorderCircles(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		CircleIDs []uint64 `json:"circle_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if err := h.circleService.ReorderCircles(c, userID, request.CircleIDs); err != nil {
+		h.handleCircleError(c, "调整分组顺序失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "分组顺序已更新"})
+}
+
+// AddCircleMembers 把好友加入分组
+func (h *FriendHandler) AddCircleMembers(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	circleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分组ID"})
+		return
+	}
+
+	var request struct {
+		UserIDs []uint64 `json:"user_ids" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if err := h.circleService.AddMembers(c, userID, circleID, request.UserIDs); err != nil {
+		h.handleCircleError(c, "添加分组成员失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已加入分组"})
+}
+
+// RemoveCircleMember 把成员移出分组
+func (h *FriendHandler) RemoveCircleMember(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	circleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分组ID"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	if err := h.circleService.RemoveMember(c, userID, circleID, memberID); err != nil {
+		h.handleCircleError(c, "移除分组成员失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已移出分组"})
+}
+
+// handleCircleError 根据分组相关错误的类型返回对应的状态码
+func (h *FriendHandler) handleCircleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrCircleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCircleNameInvalid), errors.Is(err, service.ErrCircleNameDuplicate),
+		errors.Is(err, service.ErrTooManyCircles), errors.Is(err, service.ErrCircleOrderInvalid),
+		errors.Is(err, service.ErrCircleMemberNotFriend):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message + ": " + err.Error()})
+	}
+}
+
+// Nudge 戳一戳今天还没有记录的好友，:id 为好友的用户ID
+func (h *FriendHandler) Nudge(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	nudge, err := h.nudgeService.Nudge(c, userID, targetID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNudgeSelf):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNudgeNotFriend), errors.Is(err, service.ErrNudgeMuted):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNudgeTargetLogged), errors.Is(err, service.ErrNudgeAlreadySent):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "戳一戳失败: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, nudge)
+}
+
+// GetNudgeSetting 获取自己的戳一戳设置
+func (h *FriendHandler) GetNudgeSetting(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	setting, err := h.nudgeService.GetSetting(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取戳一戳设置失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// UpdateNudgeSetting 设置是否接收好友的戳一戳
+func (h *FriendHandler) UpdateNudgeSetting(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Muted *bool `json:"muted" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setting, err := h.nudgeService.UpdateSetting(c, userID, *request.Muted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新戳一戳设置失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"record-project/domain/entity"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GoalHandler 个人目标API处理器
+type GoalHandler struct {
+	goalService service.GoalService
+	authService service.AuthService
+}
+
+// NewGoalHandler 创建个人目标API处理器
+func NewGoalHandler(goalService service.GoalService, authService service.AuthService) *GoalHandler {
+	return &GoalHandler{
+		goalService: goalService,
+		authService: authService,
+	}
+}
+
+// CreateGoal 创建目标
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Name   string  `json:"name" binding:"required"`
+		Metric string  `json:"metric" binding:"required"`
+		Target float64 `json:"target" binding:"required"`
+		Window string  `json:"window" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	goal := &entity.Goal{
+		UserID: userID,
+		Name:   request.Name,
+		Metric: request.Metric,
+		Target: request.Target,
+		Window: request.Window,
+	}
+
+	if err := h.goalService.CreateGoal(c, goal); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "创建目标失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// GetGoals 获取当前用户的目标及当前周期进度
+func (h *GoalHandler) GetGoals(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	includeArchived := c.Query("include_archived") == "true"
+
+	goals, err := h.goalService.GetGoals(c, userID, includeArchived, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取目标失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"goals": goals,
+	})
+}
+
+// GetGoal 获取单个目标的当前周期进度
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标ID"})
+		return
+	}
+
+	progress, err := h.goalService.GetGoalProgress(c, userID, goalID, time.Now())
+	if err != nil {
+		h.handleError(c, "获取目标失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// UpdateGoal 更新目标
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标ID"})
+		return
+	}
+
+	var request struct {
+		Name   string  `json:"name" binding:"required"`
+		Target float64 `json:"target" binding:"required"`
+		Status *int8   `json:"status" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	goal := &entity.Goal{
+		ID:     goalID,
+		Name:   request.Name,
+		Target: request.Target,
+		Status: *request.Status,
+	}
+
+	if err := h.goalService.UpdateGoal(c, userID, goal); err != nil {
+		h.handleError(c, "更新目标失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DeleteGoal 删除目标
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标ID"})
+		return
+	}
+
+	if err := h.goalService.DeleteGoal(c, userID, goalID); err != nil {
+		h.handleError(c, "删除目标失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "目标已删除"})
+}
+
+// GetGoalHistory 获取目标的完成记录
+func (h *GoalHandler) GetGoalHistory(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标ID"})
+		return
+	}
+
+	// 获取分页参数
+	page := 1
+	pageSize := 10
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
+			pageSize = ps
+		}
+	}
+
+	completions, total, err := h.goalService.GetGoalHistory(c, userID, goalID, page, pageSize)
+	if err != nil {
+		h.handleError(c, "获取目标完成记录失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"completions": completions,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+	})
+}
+
+// handleError 根据错误类型返回对应的状态码
+func (h *GoalHandler) handleError(c *gin.Context, message string, err error) {
+	if errors.Is(err, service.ErrGoalNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": message + ": " + err.Error()})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"record-project/application/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InviteHandler 好友邀请API处理器
+type InviteHandler struct {
+	inviteService service.InviteService
+	authService   service.AuthService
+}
+
+// NewInviteHandler 创建好友邀请API处理器
+func NewInviteHandler(inviteService service.InviteService, authService service.AuthService) *InviteHandler {
+	return &InviteHandler{
+		inviteService: inviteService,
+		authService:   authService,
+	}
+}
+
+// GetInvite 获取自己的邀请码和邀请人数
+func (h *InviteHandler) GetInvite(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	summary, err := h.inviteService.GetSummary(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取邀请码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// RotateCode 更换邀请码
+func (h *InviteHandler) RotateCode(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	summary, err := h.inviteService.RotateCode(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更换邀请码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetQRCode 获取邀请小程序码图片
+func (h *InviteHandler) GetQRCode(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	image, contentType, err := h.inviteService.GetQRCode(c, userID)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "生成小程序码失败: " + err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, image)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"record-project/domain/entity"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeagueHandler 赛季联赛API处理器
+type LeagueHandler struct {
+	leagueService  service.LeagueService
+	authService    service.AuthService
+	userService    service.UserService
+	settingService service.RankingSettingService
+}
+
+// NewLeagueHandler 创建赛季联赛API处理器
+func NewLeagueHandler(leagueService service.LeagueService, authService service.AuthService, userService service.UserService, settingService service.RankingSettingService) *LeagueHandler {
+	return &LeagueHandler{
+		leagueService:  leagueService,
+		authService:    authService,
+		userService:    userService,
+		settingService: settingService,
+	}
+}
+
+// GetCurrent 获取当前赛季的段位和所在分组的积分榜
+func (h *LeagueHandler) GetCurrent(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	overview, err := h.leagueService.GetOverview(c, userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取赛季信息失败"})
+		return
+	}
+
+	items := make([]*entity.RankingItem, len(overview.Standings))
+	for i, standing := range overview.Standings {
+		items[i] = standing.RankingItem
+	}
+
+	// 补充用户信息
+	userIDs := make([]uint64, len(items))
+	for i, item := range items {
+		userIDs[i] = item.UserID
+	}
+	users, err := h.userService.GetUsersByIDs(c, userIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户信息失败"})
+		return
+	}
+	userMap := make(map[uint64]*entity.User)
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	for _, item := range items {
+		if user, exists := userMap[item.UserID]; exists {
+			item.Nickname = user.Nickname
+			item.AvatarURL = user.AvatarURL
+		}
+	}
+
+	// 分组中有非好友的用户，按全局排行榜的规则隐藏匿名用户的身份
+	if err := h.settingService.AnonymizeGlobal(c, userID, items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜设置失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
+
+// Join 加入当前赛季
+func (h *LeagueHandler) Join(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	membership, err := h.leagueService.JoinCurrentSeason(c, userID, time.Now())
+	if err != nil {
+		if errors.Is(err, service.ErrLeagueExcluded) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "加入赛季失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, membership)
+}
+
+// GetHistory 获取参赛历史
+func (h *LeagueHandler) GetHistory(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	memberships, total, err := h.leagueService.GetHistory(c, userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取参赛历史失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"memberships": memberships,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+	})
+}
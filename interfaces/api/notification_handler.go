@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"record-project/application/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 站内通知API处理器
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	authService         service.AuthService
+}
+
+// NewNotificationHandler 创建站内通知API处理器
+func NewNotificationHandler(notificationService service.NotificationService, authService service.AuthService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		authService:         authService,
+	}
+}
+
+// GetInbox 分页获取收件箱中的通知
+func (h *NotificationHandler) GetInbox(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	notifications, total, unread, err := h.notificationService.GetInbox(c, userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取通知失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"total":         total,
+		"unread":        unread,
+		"page":          page,
+		"page_size":     pageSize,
+	})
+}
+
+// GetUnreadCount 获取未读通知数
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	unread, err := h.notificationService.CountUnread(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取未读通知数失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": unread})
+}
+
+// MarkRead 将通知标记为已读，ids 为空时标记全部
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		IDs []uint64 `json:"ids"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c, userID, request.IDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记已读失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已标记为已读"})
+}
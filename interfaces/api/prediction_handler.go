@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"record-project/application/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PredictionHandler 如厕时间预测API处理器
+type PredictionHandler struct {
+	predictionService service.PredictionService
+	authService       service.AuthService
+}
+
+// NewPredictionHandler 创建如厕时间预测API处理器
+func NewPredictionHandler(predictionService service.PredictionService, authService service.AuthService) *PredictionHandler {
+	return &PredictionHandler{
+		predictionService: predictionService,
+		authService:       authService,
+	}
+}
+
+// GetNextVisitPrediction 获取当前用户未来24小时的如厕概率预测
+func (h *PredictionHandler) GetNextVisitPrediction(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	// 提醒阈值，默认0.5
+	threshold := 0.5
+	if thresholdStr := c.Query("threshold"); thresholdStr != "" {
+		t, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || t <= 0 || t > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的阈值，取值范围为(0, 1]"})
+			return
+		}
+		threshold = t
+	}
+
+	prediction, err := h.predictionService.PredictNextDay(c, userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取预测失败: " + err.Error()})
+		return
+	}
+
+	// 未来24小时内第一个达到阈值的时段，供提醒使用
+	c.JSON(http.StatusOK, gin.H{
+		"prediction":  prediction,
+		"threshold":   threshold,
+		"next_likely": prediction.NextLikely(threshold),
+	})
+}
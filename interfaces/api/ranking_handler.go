@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"record-project/application/service"
 	"record-project/domain/entity"
@@ -10,28 +11,42 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxRankingPageSize 排行榜每页最多人数
+const maxRankingPageSize = 100
+
 // RankingHandler 排行榜API处理器
 type RankingHandler struct {
 	recordService service.RecordService
 	authService   service.AuthService
 	userService   service.UserService
 	friendService service.FriendService
+
+	benchmarkService service.BenchmarkService
+	snapshotService  service.RankingSnapshotService
+	leaderboardCache service.LeaderboardCacheService
+	settingService   service.RankingSettingService
+	circleService    service.CircleService
 }
 
 // NewRankingHandler 创建排行榜API处理器
-func NewRankingHandler(recordService service.RecordService, authService service.AuthService, userService service.UserService, friendService service.FriendService) *RankingHandler {
+func NewRankingHandler(recordService service.RecordService, authService service.AuthService, userService service.UserService, friendService service.FriendService, benchmarkService service.BenchmarkService, snapshotService service.RankingSnapshotService, leaderboardCache service.LeaderboardCacheService, settingService service.RankingSettingService, circleService service.CircleService) *RankingHandler {
 	return &RankingHandler{
-		recordService: recordService,
-		authService:   authService,
-		userService:   userService,
-		friendService: friendService,
+		recordService:    recordService,
+		authService:      authService,
+		userService:      userService,
+		friendService:    friendService,
+		benchmarkService: benchmarkService,
+		snapshotService:  snapshotService,
+		leaderboardCache: leaderboardCache,
+		settingService:   settingService,
+		circleService:    circleService,
 	}
 }
 
 // GetRanking 获取全局排行榜
 func (h *RankingHandler) GetRanking(c *gin.Context) {
 	// 从请求中获取token，解析用户ID
-	_, err := h.authService.GetUserIDFromToken(c)
+	userID, err := h.authService.GetUserIDFromToken(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
 		return
@@ -41,6 +56,37 @@ func (h *RankingHandler) GetRanking(c *gin.Context) {
 	startDateStr := c.Query("start_date")
 	endDateStr := c.Query("end_date")
 
+	// 排行指标，默认按记录次数
+	metric := c.DefaultQuery("metric", entity.RankingMetricCount)
+	if !entity.IsValidRankingMetric(metric) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的排行指标，可选值为count、total_duration、avg_duration、streak、health_score"})
+		return
+	}
+
+	// 快照周期，指定时查询当前周期至今的排行榜
+	period := c.Query("period")
+	if period != "" && !entity.IsValidRankingPeriod(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的快照周期，可选值为daily、weekly、monthly"})
+		return
+	}
+
+	// 获取分页参数
+	pageStr := c.DefaultQuery("page", "1")
+	pageSizeStr := c.DefaultQuery("page_size", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > maxRankingPageSize {
+		pageSize = maxRankingPageSize
+	}
+
 	// 默认查询参数
 	if startDateStr == "" {
 		// 默认为当前月份的第一天
@@ -70,32 +116,104 @@ func (h *RankingHandler) GetRanking(c *gin.Context) {
 	// 设置结束日期为当天的23:59:59（东八区）
 	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, loc)
 
-	// 获取全局排行榜数据（前10名）
-	rankingItems, err := h.recordService.GetGlobalRanking(c, startDate, endDate, 10)
+	// 确定用于对比名次变化的周期，区间对应不上任何周期时不返回名次变化
+	var periodStart time.Time
+	if period != "" {
+		startDate, endDate = h.snapshotService.CurrentPeriodRange(period, time.Now())
+		periodStart = startDate
+	} else if p, ps, ok := h.snapshotService.ResolvePeriod(startDate, endDate); ok {
+		period, periodStart = p, ps
+	}
+
+	// 当前周期至今的排行榜从缓存读取，其他时间段直接查询数据库
+	now := time.Now()
+	useCache := h.leaderboardCache.IsCurrentPeriod(period, startDate, endDate, now)
+
+	// 分页获取全局排行榜数据
+	var rankingItems []*entity.RankingItem
+	var total int
+	if useCache {
+		rankingItems, total, err = h.leaderboardCache.GetGlobalRanking(c, period, metric, now, page, pageSize)
+	} else {
+		rankingItems, total, err = h.recordService.GetGlobalRanking(c, metric, startDate, endDate, page, pageSize)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜数据失败"})
 		return
 	}
 
-	// 如果没有排行数据，直接返回空列表
-	if len(rankingItems) == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"rankings": []interface{}{},
-		})
+	// 获取当前用户的名次及前后相邻的用户，即使不在当前页
+	var position *entity.RankingPosition
+	if useCache {
+		position, err = h.leaderboardCache.GetUserGlobalRank(c, period, metric, userID, now)
+	} else {
+		position, err = h.recordService.GetUserGlobalRank(c, metric, userID, startDate, endDate)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户排名失败"})
+		return
+	}
+
+	// 补充用户信息
+	items := append([]*entity.RankingItem{position.Me}, rankingItems...)
+	if position.Above != nil {
+		items = append(items, position.Above)
+	}
+	if position.Below != nil {
+		items = append(items, position.Below)
+	}
+	if err := h.fillUserInfo(c, items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户信息失败"})
 		return
 	}
 
+	// 补充上一个周期的名次
+	if period != "" {
+		if err := h.snapshotService.AttachPreviousRanks(c, entity.RankingScopeGlobal, 0, period, periodStart, metric, items); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取历史名次失败"})
+			return
+		}
+	}
+
+	// 隐藏匿名用户的身份，需在补充历史名次之后执行
+	if err := h.settingService.AnonymizeGlobal(c, userID, items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜设置失败"})
+		return
+	}
+
+	if rankingItems == nil {
+		rankingItems = []*entity.RankingItem{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rankings":  rankingItems,
+		"metric":    metric,
+		"period":    period,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"me":        position.Me,
+		"above":     position.Above,
+		"below":     position.Below,
+	})
+}
+
+// fillUserInfo 为排行榜项目补充昵称和头像
+func (h *RankingHandler) fillUserInfo(c *gin.Context, items []*entity.RankingItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
 	// 收集所有需要查询的用户ID
 	var userIDs []uint64
-	for _, item := range rankingItems {
+	for _, item := range items {
 		userIDs = append(userIDs, item.UserID)
 	}
 
 	// 查询用户信息
 	users, err := h.userService.GetUsersByIDs(c, userIDs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户信息失败"})
-		return
+		return err
 	}
 
 	// 创建用户ID到用户信息的映射
@@ -105,16 +223,13 @@ func (h *RankingHandler) GetRanking(c *gin.Context) {
 	}
 
 	// 组装完整的排行榜数据
-	for _, item := range rankingItems {
+	for _, item := range items {
 		if user, exists := userMap[item.UserID]; exists {
 			item.Nickname = user.Nickname
 			item.AvatarURL = user.AvatarURL
 		}
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"rankings": rankingItems,
-	})
+	return nil
 }
 
 // GetFriendRanking 获取好友排行榜
@@ -130,6 +245,20 @@ func (h *RankingHandler) GetFriendRanking(c *gin.Context) {
 	startDateStr := c.Query("start_date")
 	endDateStr := c.Query("end_date")
 
+	// 排行指标，默认按记录次数
+	metric := c.DefaultQuery("metric", entity.RankingMetricCount)
+	if !entity.IsValidRankingMetric(metric) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的排行指标，可选值为count、total_duration、avg_duration、streak、health_score"})
+		return
+	}
+
+	// 快照周期，指定时查询当前周期至今的排行榜
+	period := c.Query("period")
+	if period != "" && !entity.IsValidRankingPeriod(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的快照周期，可选值为daily、weekly、monthly"})
+		return
+	}
+
 	// 获取分页参数
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "10")
@@ -143,6 +272,9 @@ func (h *RankingHandler) GetFriendRanking(c *gin.Context) {
 	if err != nil || pageSize < 1 {
 		pageSize = 10
 	}
+	if pageSize > maxRankingPageSize {
+		pageSize = maxRankingPageSize
+	}
 
 	// 默认查询参数
 	if startDateStr == "" {
@@ -173,59 +305,201 @@ func (h *RankingHandler) GetFriendRanking(c *gin.Context) {
 	// 设置结束日期为当天的23:59:59（东八区）
 	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, loc)
 
-	// 获取用户的好友列表
-	friends, err := h.friendService.GetFriendsByUserID(c, userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取好友列表失败"})
-		return
+	// 确定用于对比名次变化的周期，区间对应不上任何周期时不返回名次变化
+	var periodStart time.Time
+	if period != "" {
+		startDate, endDate = h.snapshotService.CurrentPeriodRange(period, time.Now())
+		periodStart = startDate
+	} else if p, ps, ok := h.snapshotService.ResolvePeriod(startDate, endDate); ok {
+		period, periodStart = p, ps
 	}
 
-	// 收集所有好友ID，包括自己
+	// 收集所有好友ID，包括自己；指定分组时只包含分组中的好友
 	friendIDs := []uint64{userID} // 包含自己
-	for _, friend := range friends {
-		if friend.FriendID != userID {
-			friendIDs = append(friendIDs, friend.FriendID)
+	circleID, _ := strconv.ParseUint(c.Query("circle_id"), 10, 64)
+	if circleID > 0 {
+		memberIDs, err := h.circleService.GetCircleFriendIDs(c, userID, circleID)
+		if err != nil {
+			if errors.Is(err, service.ErrCircleNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取分组成员失败"})
+			return
+		}
+		friendIDs = append(friendIDs, memberIDs...)
+	} else {
+		// 获取用户的好友列表
+		friends, err := h.friendService.GetFriendsByUserID(c, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取好友列表失败"})
+			return
+		}
+		for _, friend := range friends {
+			if friend.FriendID != userID {
+				friendIDs = append(friendIDs, friend.FriendID)
+			}
 		}
 	}
 
-	// 获取好友排行榜数据
-	rankingItems, total, err := h.recordService.GetFriendRanking(c, friendIDs, startDate, endDate, page, pageSize)
+	// 排除设置为不参与排行的用户
+	friendIDs, err = h.settingService.FilterFriendMembers(c, friendIDs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜数据失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜设置失败"})
 		return
 	}
 
-	// 收集所有需要查询的用户ID
-	var userIDs []uint64
-	for _, item := range rankingItems {
-		userIDs = append(userIDs, item.UserID)
+	// 获取好友排行榜数据，好友之间还统计好友可见的记录，不能使用全局榜单的缓存
+	rankingItems, total, err := h.recordService.GetFriendRanking(c, metric, friendIDs, startDate, endDate, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜数据失败"})
+		return
 	}
 
-	// 查询用户信息
-	users, err := h.userService.GetUsersByIDs(c, userIDs)
+	// 获取当前用户的名次及前后相邻的用户，即使不在当前页
+	position, err := h.recordService.GetUserFriendRank(c, metric, userID, friendIDs, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户信息失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户排名失败"})
 		return
 	}
 
-	// 创建用户ID到用户信息的映射
-	userMap := make(map[uint64]*entity.User)
-	for _, user := range users {
-		userMap[user.ID] = user
+	// 补充用户信息
+	items := append([]*entity.RankingItem{position.Me}, rankingItems...)
+	if position.Above != nil {
+		items = append(items, position.Above)
+	}
+	if position.Below != nil {
+		items = append(items, position.Below)
+	}
+	if err := h.fillUserInfo(c, items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户信息失败"})
+		return
 	}
 
-	// 组装完整的排行榜数据
-	for _, item := range rankingItems {
-		if user, exists := userMap[item.UserID]; exists {
-			item.Nickname = user.Nickname
-			item.AvatarURL = user.AvatarURL
+	// 补充上一个周期的名次，快照按全部好友生成，按分组筛选时不返回名次变化
+	if period != "" && circleID == 0 {
+		if err := h.snapshotService.AttachPreviousRanks(c, entity.RankingScopeFriends, userID, period, periodStart, metric, items); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取历史名次失败"})
+			return
 		}
 	}
 
+	if rankingItems == nil {
+		rankingItems = []*entity.RankingItem{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"rankings":  rankingItems,
+		"metric":    metric,
+		"period":    period,
 		"total":     total,
 		"page":      page,
 		"page_size": pageSize,
+		"me":        position.Me,
+		"above":     position.Above,
+		"below":     position.Below,
 	})
 }
+
+// GetBenchmarks 获取当前用户在好友和全体用户中的匿名百分位对比
+func (h *RankingHandler) GetBenchmarks(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	// 统计最近多少天，默认30天，最多一年
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 366 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的天数，取值范围为1-366"})
+		return
+	}
+
+	report, err := h.benchmarkService.GetBenchmarks(c, userID, days, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取对比数据失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetRankingHistory 获取当前用户在排行榜中的名次历史
+func (h *RankingHandler) GetRankingHistory(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	scope := c.DefaultQuery("scope", entity.RankingScopeGlobal)
+	period := c.DefaultQuery("period", entity.RankingPeriodDaily)
+	metric := c.DefaultQuery("metric", entity.RankingMetricCount)
+
+	// 最近多少个周期，默认30个
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if err != nil || limit < 1 {
+		limit = 30
+	}
+
+	history, err := h.snapshotService.GetHistory(c, scope, userID, period, metric, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "获取名次历史失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"history": history,
+		"scope":   scope,
+		"period":  period,
+		"metric":  metric,
+	})
+}
+
+// GetRankingSetting 获取当前用户的排行榜设置
+func (h *RankingHandler) GetRankingSetting(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	setting, err := h.settingService.GetSetting(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜设置失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// UpdateRankingSetting 更新当前用户的排行榜设置
+func (h *RankingHandler) UpdateRankingSetting(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Visibility string `json:"visibility" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	setting, err := h.settingService.UpdateSetting(c, userID, request.Visibility)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "更新排行榜设置失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"record-project/application/service"
+	"record-project/domain/entity"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecapHandler 回顾API处理器
+type RecapHandler struct {
+	recapService service.RecapService
+	authService  service.AuthService
+}
+
+// NewRecapHandler 创建回顾API处理器
+func NewRecapHandler(recapService service.RecapService, authService service.AuthService) *RecapHandler {
+	return &RecapHandler{
+		recapService: recapService,
+		authService:  authService,
+	}
+}
+
+// GetRecaps 获取当前用户的月度/年度回顾列表
+func (h *RecapHandler) GetRecaps(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	// 周期类型，为空时返回全部
+	periodType := c.Query("period_type")
+	if periodType != "" && periodType != entity.RecapPeriodMonth && periodType != entity.RecapPeriodYear {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的周期类型，可选值为month或year"})
+		return
+	}
+
+	// 获取分页参数
+	page := 1
+	pageSize := 10
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
+			pageSize = ps
+		}
+	}
+
+	recaps, total, err := h.recapService.GetRecaps(c, userID, periodType, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取回顾失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recaps":    recaps,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetRecap 获取单个回顾详情
+func (h *RecapHandler) GetRecap(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的回顾ID"})
+		return
+	}
+
+	recap, err := h.recapService.GetRecapByID(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取回顾失败: " + err.Error()})
+		return
+	}
+
+	// 只能查看自己的回顾
+	if recap == nil || recap.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回顾不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recap)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordFlagHandler 反作弊标记API处理器，包含用户申诉和审核员审核
+type RecordFlagHandler struct {
+	antiCheatService service.AntiCheatService
+	authService      service.AuthService
+}
+
+// NewRecordFlagHandler 创建反作弊标记API处理器
+func NewRecordFlagHandler(antiCheatService service.AntiCheatService, authService service.AuthService) *RecordFlagHandler {
+	return &RecordFlagHandler{
+		antiCheatService: antiCheatService,
+		authService:      authService,
+	}
+}
+
+// GetMyFlags 获取当前用户被标记的记录
+func (h *RecordFlagHandler) GetMyFlags(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	flags, total, err := h.antiCheatService.GetUserFlags(c, userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取标记记录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flags":     flags,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// Appeal 对标记提出申诉
+func (h *RecordFlagHandler) Appeal(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	flagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标记ID"})
+		return
+	}
+
+	var request struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	flag, err := h.antiCheatService.Appeal(c, userID, flagID, request.Reason)
+	if err != nil {
+		h.handleError(c, "申诉失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// GetReviewQueue 审核员获取待审核的标记
+func (h *RecordFlagHandler) GetReviewQueue(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	flags, total, err := h.antiCheatService.GetReviewQueue(c, userID, c.Query("status"), page, pageSize)
+	if err != nil {
+		h.handleError(c, "获取审核队列失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flags":     flags,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// Review 审核员审核标记
+func (h *RecordFlagHandler) Review(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	flagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的标记ID"})
+		return
+	}
+
+	var request struct {
+		Decision string `json:"decision" binding:"required"`
+		Note     string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	flag, err := h.antiCheatService.Review(c, userID, flagID, request.Decision, request.Note)
+	if err != nil {
+		h.handleError(c, "审核失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// handleError 根据错误类型返回对应的状态码
+func (h *RecordFlagHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecordFlagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotModerator):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": message + ": " + err.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"record-project/application/service"
 	"record-project/domain/entity"
@@ -16,6 +17,9 @@ type RecordHandler struct {
 	userService     service.UserService
 	tagService      service.TagService
 	poopTypeService service.PoopTypeService
+	authService     service.AuthService
+	visibility      service.RecordVisibilityService
+	interactions    service.RecordInteractionService
 }
 
 // NewRecordHandler 创建记录API处理器
@@ -24,24 +28,37 @@ func NewRecordHandler(
 	userService service.UserService,
 	tagService service.TagService,
 	poopTypeService service.PoopTypeService,
+	authService service.AuthService,
+	visibility service.RecordVisibilityService,
+	interactions service.RecordInteractionService,
 ) *RecordHandler {
 	return &RecordHandler{
 		recordService:   recordService,
 		userService:     userService,
 		tagService:      tagService,
 		poopTypeService: poopTypeService,
+		authService:     authService,
+		visibility:      visibility,
+		interactions:    interactions,
 	}
 }
 
-// GetRecord 获取记录
+// GetRecord 获取记录，查看者不可见的记录按不存在处理
 func (h *RecordHandler) GetRecord(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
 		return
 	}
 
-	record, err := h.recordService.GetRecordByID(c, id)
+	record, err := h.recordService.GetVisibleRecord(c, viewerID, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -73,12 +90,20 @@ func (h *RecordHandler) GetRecord(c *gin.Context) {
 		return
 	}
 
+	// 获取回应和评论汇总
+	interactions, err := h.interactions.GetInteractions(c, viewerID, []uint64{record.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 构建响应
 	response := map[string]interface{}{
-		"record":    record,
-		"tags":      tags,
-		"user":      user,
-		"poop_type": poopType,
+		"record":       record,
+		"tags":         tags,
+		"user":         user,
+		"poop_type":    poopType,
+		"interactions": interactions[record.ID],
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -86,6 +111,13 @@ func (h *RecordHandler) GetRecord(c *gin.Context) {
 
 // GetRecordsByUserID 根据用户ID获取记录列表
 func (h *RecordHandler) GetRecordsByUserID(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
 	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
@@ -95,7 +127,7 @@ func (h *RecordHandler) GetRecordsByUserID(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
 
-	records, total, err := h.recordService.GetRecordsByUserID(c, userID, page, size)
+	records, total, err := h.recordService.GetRecordsByUserID(c, viewerID, userID, page, size)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -142,13 +174,21 @@ func (h *RecordHandler) GetRecordsByUserID(c *gin.Context) {
 			}
 		}
 
-		// 4. 组装完整记录
+		// 4. 批量获取回应和评论汇总
+		interactionMap, err := h.interactions.GetInteractions(c, viewerID, recordIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// 5. 组装完整记录
 		var completeRecords []map[string]interface{}
 		for _, record := range records {
 			completeRecord := map[string]interface{}{
-				"record":    record,
-				"tags":      recordTagsMap[record.ID],
-				"poop_type": poopTypeMap[record.PoopTypeID],
+				"record":       record,
+				"tags":         recordTagsMap[record.ID],
+				"poop_type":    poopTypeMap[record.PoopTypeID],
+				"interactions": interactionMap[record.ID],
 			}
 			completeRecords = append(completeRecords, completeRecord)
 		}
@@ -171,6 +211,13 @@ func (h *RecordHandler) GetRecordsByUserID(c *gin.Context) {
 
 // GetRecordsByDateRange 根据日期范围获取记录
 func (h *RecordHandler) GetRecordsByDateRange(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
 	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
@@ -203,7 +250,7 @@ func (h *RecordHandler) GetRecordsByDateRange(c *gin.Context) {
 	// 设置结束日期为当天的23:59:59
 	endTime = endTime.Add(24*time.Hour - time.Second)
 
-	total, err := h.recordService.CountRecordsByDateRange(c, userID, startTime, endTime)
+	total, err := h.recordService.CountRecordsByDateRange(c, viewerID, userID, startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -218,7 +265,7 @@ func (h *RecordHandler) GetRecordsByDateRange(c *gin.Context) {
 	}
 
 	// 直接传递time.Time对象，而不是字符串
-	records, err := h.recordService.GetRecordsByDateRange(c, userID, startTime, endTime, page, size)
+	records, err := h.recordService.GetRecordsByDateRange(c, viewerID, userID, startTime, endTime, page, size)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -263,13 +310,21 @@ func (h *RecordHandler) GetRecordsByDateRange(c *gin.Context) {
 		}
 	}
 
-	// 4. 组装完整记录
+	// 4. 批量获取回应和评论汇总
+	interactionMap, err := h.interactions.GetInteractions(c, viewerID, recordIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 5. 组装完整记录
 	var completeRecords []map[string]interface{}
 	for _, record := range records {
 		completeRecord := map[string]interface{}{
-			"record":    record,
-			"tags":      recordTagsMap[record.ID],
-			"poop_type": poopTypeMap[record.PoopTypeID],
+			"record":       record,
+			"tags":         recordTagsMap[record.ID],
+			"poop_type":    poopTypeMap[record.PoopTypeID],
+			"interactions": interactionMap[record.ID],
 		}
 		completeRecords = append(completeRecords, completeRecord)
 	}
@@ -294,7 +349,7 @@ func (h *RecordHandler) CreateRecord(c *gin.Context) {
 
 	// 使用事务创建记录并关联标签
 	if err := h.recordService.CreateRecordWithTags(c, request.Record, request.TagIDs); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleWriteError(c, err)
 		return
 	}
 
@@ -322,7 +377,7 @@ func (h *RecordHandler) UpdateRecord(c *gin.Context) {
 	request.Record.ID = id
 	// 使用事务更新记录并关联标签
 	if err := h.recordService.UpdateRecordWithTags(c, request.Record, request.TagIDs); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleWriteError(c, err)
 		return
 	}
 
@@ -346,7 +401,15 @@ func (h *RecordHandler) DeleteRecord(c *gin.Context) {
 }
 
 // GetUsersDailyRecordStats 批量获取指定用户当天的拉屎记录统计
+// 只统计当前用户可见的记录
 func (h *RecordHandler) GetUsersDailyRecordStats(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
 	// 获取用户ID列表
 	userIDsStr := c.QueryArray("user_ids")
 	if len(userIDsStr) == 0 {
@@ -377,7 +440,7 @@ func (h *RecordHandler) GetUsersDailyRecordStats(c *gin.Context) {
 	}
 
 	// 获取统计数据
-	stats, err := h.recordService.GetUsersDailyRecordStats(c, userIDs, date)
+	stats, err := h.recordService.GetUsersDailyRecordStats(c, viewerID, userIDs, date)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取记录统计失败: " + err.Error()})
 		return
@@ -406,3 +469,59 @@ func (h *RecordHandler) GetUsersDailyRecordStats(c *gin.Context) {
 		"date":  date.Format("2006-01-02"),
 	})
 }
+
+// GetVisibilitySetting 获取当前用户新记录的默认可见范围
+func (h *RecordHandler) GetVisibilitySetting(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	setting, err := h.visibility.GetSetting(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取可见范围设置失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// UpdateVisibilitySetting 更新当前用户新记录的默认可见范围
+func (h *RecordHandler) UpdateVisibilitySetting(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Visibility string `json:"visibility" binding:"required"`
+		CircleID   uint64 `json:"circle_id"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	setting, err := h.visibility.UpdateSetting(c, userID, request.Visibility, request.CircleID)
+	if err != nil {
+		h.handleWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// handleWriteError 可见范围校验失败返回400，其他错误返回500
+func (h *RecordHandler) handleWriteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecordVisibilityInvalid), errors.Is(err, service.ErrRecordVisibilityCircleInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
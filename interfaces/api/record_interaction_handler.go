@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordInteractionHandler 记录回应和评论API处理器
+type RecordInteractionHandler struct {
+	interactionService service.RecordInteractionService
+	authService        service.AuthService
+}
+
+// NewRecordInteractionHandler 创建记录回应和评论API处理器
+func NewRecordInteractionHandler(interactionService service.RecordInteractionService, authService service.AuthService) *RecordInteractionHandler {
+	return &RecordInteractionHandler{
+		interactionService: interactionService,
+		authService:        authService,
+	}
+}
+
+// GetReactions 获取记录的全部回应
+func (h *RecordInteractionHandler) GetReactions(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+		return
+	}
+
+	reactions, err := h.interactionService.GetReactions(c, viewerID, recordID)
+	if err != nil {
+		h.handleError(c, "获取回应失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reactions": reactions})
+}
+
+// AddReaction 对记录添加表情回应
+func (h *RecordInteractionHandler) AddReaction(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+		return
+	}
+
+	var request struct {
+		Emoji string `json:"emoji" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interactions, err := h.interactionService.AddReaction(c, viewerID, recordID, request.Emoji)
+	if err != nil {
+		h.handleError(c, "添加回应失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, interactions)
+}
+
+// RemoveReaction 取消对记录的表情回应，表情通过emoji查询参数指定
+func (h *RecordInteractionHandler) RemoveReaction(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+		return
+	}
+
+	interactions, err := h.interactionService.RemoveReaction(c, viewerID, recordID, c.Query("emoji"))
+	if err != nil {
+		h.handleError(c, "取消回应失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, interactions)
+}
+
+// GetComments 分页获取记录的评论
+func (h *RecordInteractionHandler) GetComments(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+
+	comments, total, err := h.interactionService.GetComments(c, viewerID, recordID, page, pageSize)
+	if err != nil {
+		h.handleError(c, "获取评论失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments":  comments,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// AddComment 发表评论，parent_id 不为空时回复该评论
+func (h *RecordInteractionHandler) AddComment(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+		return
+	}
+
+	var request struct {
+		Content  string `json:"content" binding:"required"`
+		ParentID uint64 `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.interactionService.AddComment(c, viewerID, recordID, request.Content, request.ParentID)
+	if err != nil {
+		h.handleError(c, "发表评论失败", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// DeleteComment 删除评论
+func (h *RecordInteractionHandler) DeleteComment(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+		return
+	}
+
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	if err := h.interactionService.DeleteComment(c, viewerID, recordID, commentID); err != nil {
+		h.handleError(c, "删除评论失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "评论已删除"})
+}
+
+// handleError 根据错误类型返回对应的状态码
+func (h *RecordInteractionHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInteractionRecordNotFound), errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInteractionNotFriend), errors.Is(err, service.ErrCommentDeleteForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReactionEmojiInvalid), errors.Is(err, service.ErrCommentContentInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInteractionRateLimited):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message + ": " + err.Error()})
+	}
+}
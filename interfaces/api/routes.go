@@ -7,7 +7,7 @@ import (
 )
 
 // RegisterRoutes 注册路由
-func RegisterRoutes(r *gin.Engine, userHandler *UserHandler, recordHandler *RecordHandler, tagHandler *TagHandler, poopTypeHandler *PoopTypeHandler, authHandler *AuthHandler, fileHandler *FileHandler, rankingHandler *RankingHandler, friendHandler *FriendHandler) {
+func RegisterRoutes(r *gin.Engine, userHandler *UserHandler, recordHandler *RecordHandler, tagHandler *TagHandler, poopTypeHandler *PoopTypeHandler, authHandler *AuthHandler, fileHandler *FileHandler, rankingHandler *RankingHandler, friendHandler *FriendHandler, recapHandler *RecapHandler, predictionHandler *PredictionHandler, goalHandler *GoalHandler, recordFlagHandler *RecordFlagHandler, leagueHandler *LeagueHandler, blockHandler *BlockHandler, inviteHandler *InviteHandler, feedHandler *FeedHandler, recordInteractionHandler *RecordInteractionHandler, notificationHandler *NotificationHandler, squadHandler *SquadHandler, challengeHandler *ChallengeHandler, badgeHandler *BadgeHandler) {
 	// API版本
 	v1 := r.Group("/api/v1")
 
@@ -49,6 +49,14 @@ func RegisterRoutes(r *gin.Engine, userHandler *UserHandler, recordHandler *Reco
 		recordRoutes.GET("/user/:user_id", recordHandler.GetRecordsByUserID)
 		recordRoutes.GET("/date-range", recordHandler.GetRecordsByDateRange)
 		recordRoutes.GET("/daily-stats", recordHandler.GetUsersDailyRecordStats)
+		recordRoutes.GET("/visibility-settings", recordHandler.GetVisibilitySetting)
+		recordRoutes.PUT("/visibility-settings", recordHandler.UpdateVisibilitySetting)
+		recordRoutes.GET("/:id/reactions", recordInteractionHandler.GetReactions)
+		recordRoutes.POST("/:id/reactions", recordInteractionHandler.AddReaction)
+		recordRoutes.DELETE("/:id/reactions", recordInteractionHandler.RemoveReaction)
+		recordRoutes.GET("/:id/comments", recordInteractionHandler.GetComments)
+		recordRoutes.POST("/:id/comments", recordInteractionHandler.AddComment)
+		recordRoutes.DELETE("/:id/comments/:comment_id", recordInteractionHandler.DeleteComment)
 	}
 
 	rankingRoutes := v1.Group("/rankings")
@@ -56,6 +64,10 @@ func RegisterRoutes(r *gin.Engine, userHandler *UserHandler, recordHandler *Reco
 	{
 		rankingRoutes.GET("", rankingHandler.GetRanking)
 		rankingRoutes.GET("/friends", rankingHandler.GetFriendRanking)
+		rankingRoutes.GET("/benchmarks", rankingHandler.GetBenchmarks)
+		rankingRoutes.GET("/history", rankingHandler.GetRankingHistory)
+		rankingRoutes.GET("/settings", rankingHandler.GetRankingSetting)
+		rankingRoutes.PUT("/settings", rankingHandler.UpdateRankingSetting)
 	}
 
 	// 标签相关路由 - 需要认证
@@ -87,5 +99,143 @@ func RegisterRoutes(r *gin.Engine, userHandler *UserHandler, recordHandler *Reco
 		friendRoutes.DELETE("/:id", friendHandler.DeleteFriend)
 		// 添加获取好友申请的路由
 		friendRoutes.GET("/requests", friendHandler.GetFriendRequests)
+		friendRoutes.GET("/requests/sent", friendHandler.GetSentRequests)
+		friendRoutes.GET("/suggestions", friendHandler.GetSuggestions)
+		friendRoutes.DELETE("/requests/:id", friendHandler.WithdrawRequest)
+		// 好友分组
+		friendRoutes.GET("/circles", friendHandler.GetCircles)
+		friendRoutes.POST("/circles", friendHandler.CreateCircle)
+		friendRoutes.PUT("/circles/order", friendHandler.ReorderCircles)
+		friendRoutes.PUT("/circles/:id", friendHandler.UpdateCircle)
+		friendRoutes.DELETE("/circles/:id", friendHandler.DeleteCircle)
+		friendRoutes.POST("/circles/:id/members", friendHandler.AddCircleMembers)
+		friendRoutes.DELETE("/circles/:id/members/:user_id", friendHandler.RemoveCircleMember)
+
+		friendRoutes.POST("/:id/nudge", friendHandler.Nudge)
+		friendRoutes.GET("/nudge-settings", friendHandler.GetNudgeSetting)
+		friendRoutes.PUT("/nudge-settings", friendHandler.UpdateNudgeSetting)
+	}
+
+	// 回顾相关路由 - 需要认证
+	recapRoutes := v1.Group("/recaps")
+	recapRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		recapRoutes.GET("", recapHandler.GetRecaps)
+		recapRoutes.GET("/:id", recapHandler.GetRecap)
+	}
+
+	// 预测相关路由 - 需要认证
+	predictionRoutes := v1.Group("/predictions")
+	predictionRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		predictionRoutes.GET("/next-visit", predictionHandler.GetNextVisitPrediction)
+	}
+
+	// 个人目标相关路由 - 需要认证
+	goalRoutes := v1.Group("/goals")
+	goalRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		goalRoutes.POST("", goalHandler.CreateGoal)
+		goalRoutes.GET("", goalHandler.GetGoals)
+		goalRoutes.GET("/:id", goalHandler.GetGoal)
+		goalRoutes.PUT("/:id", goalHandler.UpdateGoal)
+		goalRoutes.DELETE("/:id", goalHandler.DeleteGoal)
+		goalRoutes.GET("/:id/history", goalHandler.GetGoalHistory)
+	}
+
+	// 反作弊标记相关路由 - 需要认证
+	flagRoutes := v1.Group("/flags")
+	flagRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		flagRoutes.GET("", recordFlagHandler.GetMyFlags)
+		flagRoutes.POST("/:id/appeal", recordFlagHandler.Appeal)
+	}
+
+	// 审核相关路由 - 需要认证，仅审核员可用
+	moderationRoutes := v1.Group("/moderation")
+	moderationRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		moderationRoutes.GET("/flags", recordFlagHandler.GetReviewQueue)
+		moderationRoutes.POST("/flags/:id/review", recordFlagHandler.Review)
+	}
+
+	// 赛季联赛相关路由 - 需要认证
+	leagueRoutes := v1.Group("/leagues")
+	leagueRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		leagueRoutes.GET("/current", leagueHandler.GetCurrent)
+		leagueRoutes.POST("/join", leagueHandler.Join)
+		leagueRoutes.GET("/history", leagueHandler.GetHistory)
+	}
+
+	// 屏蔽相关路由 - 需要认证
+	blockRoutes := v1.Group("/blocks")
+	blockRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		blockRoutes.GET("", blockHandler.GetBlockedUsers)
+		blockRoutes.POST("", blockHandler.BlockUser)
+		blockRoutes.DELETE("/:user_id", blockHandler.UnblockUser)
+	}
+
+	// 好友邀请相关路由 - 需要认证
+	inviteRoutes := v1.Group("/invites")
+	inviteRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		inviteRoutes.GET("", inviteHandler.GetInvite)
+		inviteRoutes.POST("/rotate", inviteHandler.RotateCode)
+		inviteRoutes.GET("/qrcode", inviteHandler.GetQRCode)
+	}
+
+	// 好友动态相关路由 - 需要认证
+	feedRoutes := v1.Group("/feed")
+	feedRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		feedRoutes.GET("", feedHandler.GetFeed)
+	}
+
+	// 站内通知相关路由 - 需要认证
+	notificationRoutes := v1.Group("/notifications")
+	notificationRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		notificationRoutes.GET("", notificationHandler.GetInbox)
+		notificationRoutes.GET("/unread-count", notificationHandler.GetUnreadCount)
+		notificationRoutes.POST("/read", notificationHandler.MarkRead)
+	}
+
+	// 小组相关路由 - 需要认证
+	squadRoutes := v1.Group("/squads")
+	squadRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		squadRoutes.GET("", squadHandler.GetMySquads)
+		squadRoutes.POST("", squadHandler.CreateSquad)
+		squadRoutes.GET("/leaderboard", squadHandler.GetLeaderboard)
+		squadRoutes.POST("/join", squadHandler.Join)
+		squadRoutes.GET("/:id", squadHandler.GetSquad)
+		squadRoutes.PUT("/:id", squadHandler.UpdateSquad)
+		squadRoutes.DELETE("/:id", squadHandler.DeleteSquad)
+		squadRoutes.GET("/:id/dashboard", squadHandler.GetDashboard)
+		squadRoutes.GET("/:id/invite", squadHandler.GetInvite)
+		squadRoutes.POST("/:id/invite/rotate", squadHandler.RotateInvite)
+		squadRoutes.POST("/:id/leave", squadHandler.Leave)
+		squadRoutes.PUT("/:id/members/:user_id", squadHandler.SetMemberRole)
+		squadRoutes.DELETE("/:id/members/:user_id", squadHandler.RemoveMember)
+	}
+
+	// 好友挑战相关路由 - 需要认证
+	challengeRoutes := v1.Group("/challenges")
+	challengeRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		challengeRoutes.GET("", challengeHandler.GetChallenges)
+		challengeRoutes.POST("", challengeHandler.CreateChallenge)
+		challengeRoutes.GET("/:id", challengeHandler.GetChallenge)
+		challengeRoutes.POST("/:id/accept", challengeHandler.Accept)
+		challengeRoutes.POST("/:id/decline", challengeHandler.Decline)
+	}
+
+	// 徽章相关路由 - 需要认证
+	badgeRoutes := v1.Group("/badges")
+	badgeRoutes.Use(middleware.JWTAuthMiddleware())
+	{
+		badgeRoutes.GET("", badgeHandler.GetBadges)
 	}
 }
@@ -0,0 +1,392 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"record-project/application/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SquadHandler 小组API处理器
+type SquadHandler struct {
+	squadService   service.SquadService
+	authService    service.AuthService
+	settingService service.RankingSettingService
+}
+
+// NewSquadHandler 创建小组API处理器
+func NewSquadHandler(squadService service.SquadService, authService service.AuthService, settingService service.RankingSettingService) *SquadHandler {
+	return &SquadHandler{
+		squadService:   squadService,
+		authService:    authService,
+		settingService: settingService,
+	}
+}
+
+// GetMySquads 获取当前用户加入的小组
+func (h *SquadHandler) GetMySquads(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squads, err := h.squadService.GetMySquads(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取小组失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"squads": squads})
+}
+
+// CreateSquad 创建小组
+func (h *SquadHandler) CreateSquad(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Name      string `json:"name" binding:"required"`
+		MemberCap int    `json:"member_cap"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	squad, err := h.squadService.CreateSquad(c, userID, request.Name, request.MemberCap)
+	if err != nil {
+		h.handleError(c, "创建小组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, squad)
+}
+
+// GetSquad 获取小组信息和成员列表
+func (h *SquadHandler) GetSquad(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	squad, members, err := h.squadService.GetSquad(c, userID, squadID)
+	if err != nil {
+		h.handleError(c, "获取小组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"squad":   squad,
+		"members": members,
+	})
+}
+
+// UpdateSquad 修改小组名称和成员上限
+func (h *SquadHandler) UpdateSquad(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Name      string `json:"name" binding:"required"`
+		MemberCap int    `json:"member_cap"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	squad, err := h.squadService.UpdateSquad(c, userID, squadID, request.Name, request.MemberCap)
+	if err != nil {
+		h.handleError(c, "修改小组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, squad)
+}
+
+// DeleteSquad 解散小组
+func (h *SquadHandler) DeleteSquad(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.squadService.DeleteSquad(c, userID, squadID); err != nil {
+		h.handleError(c, "解散小组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "小组已解散"})
+}
+
+// GetDashboard 获取小组在当前周期内的每日汇总和成员排行
+func (h *SquadHandler) GetDashboard(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.squadService.GetDashboard(c, userID, squadID, c.Query("period"), c.Query("metric"), time.Now())
+	if err != nil {
+		h.handleError(c, "获取小组看板失败", err)
+		return
+	}
+
+	// 小组成员不一定是好友，按全局排行榜的规则隐藏匿名用户的身份
+	if err := h.settingService.AnonymizeGlobal(c, userID, dashboard.Rankings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取排行榜设置失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GetLeaderboard 获取小组排行榜
+func (h *SquadHandler) GetLeaderboard(c *gin.Context) {
+	if _, err := h.authService.GetUserIDFromToken(c); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+	metric := c.Query("metric")
+
+	items, total, err := h.squadService.GetLeaderboard(c, c.Query("period"), metric, time.Now(), page, pageSize)
+	if err != nil {
+		h.handleError(c, "获取小组排行榜失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rankings":  items,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetInvite 获取小组邀请链接
+func (h *SquadHandler) GetInvite(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	invite, err := h.squadService.GetInvite(c, userID, squadID)
+	if err != nil {
+		h.handleError(c, "获取邀请链接失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invite)
+}
+
+// RotateInvite 更换小组邀请码
+func (h *SquadHandler) RotateInvite(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	invite, err := h.squadService.RotateInvite(c, userID, squadID)
+	if err != nil {
+		h.handleError(c, "更换邀请码失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invite)
+}
+
+// Join 通过邀请码加入小组
+func (h *SquadHandler) Join(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	var request struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	squad, err := h.squadService.Join(c, userID, request.Code)
+	if err != nil {
+		h.handleError(c, "加入小组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, squad)
+}
+
+// Leave 退出小组
+func (h *SquadHandler) Leave(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.squadService.Leave(c, userID, squadID); err != nil {
+		h.handleError(c, "退出小组失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已退出小组"})
+}
+
+// SetMemberRole 设置成员角色
+func (h *SquadHandler) SetMemberRole(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var request struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if err := h.squadService.SetMemberRole(c, userID, squadID, targetID, request.Role); err != nil {
+		h.handleError(c, "设置成员角色失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "成员角色已更新"})
+}
+
+// RemoveMember 移除成员
+func (h *SquadHandler) RemoveMember(c *gin.Context) {
+	// 从请求中获取token，解析用户ID
+	userID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权的访问"})
+		return
+	}
+
+	squadID, ok := parseSquadID(c)
+	if !ok {
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	if err := h.squadService.RemoveMember(c, userID, squadID, targetID); err != nil {
+		h.handleError(c, "移除成员失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "成员已移除"})
+}
+
+// parseSquadID 解析路径中的小组ID，无效时直接返回400
+func parseSquadID(c *gin.Context) (uint64, bool) {
+	squadID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的小组ID"})
+		return 0, false
+	}
+	return squadID, true
+}
+
+// handleError 根据错误类型返回对应的状态码
+func (h *SquadHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrSquadNotFound), errors.Is(err, service.ErrSquadMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSquadForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSquadFull), errors.Is(err, service.ErrSquadJoinLimit),
+		errors.Is(err, service.ErrSquadAlreadyMember), errors.Is(err, service.ErrSquadOwnerCannotLeave):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSquadNameInvalid), errors.Is(err, service.ErrSquadMemberCapInvalid),
+		errors.Is(err, service.ErrSquadRoleInvalid), errors.Is(err, service.ErrSquadMetricInvalid),
+		errors.Is(err, service.ErrInviteCodeInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message + ": " + err.Error()})
+	}
+}
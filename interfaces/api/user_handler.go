@@ -11,17 +11,21 @@ import (
 
 // UserHandler 用户API处理器
 type UserHandler struct {
-	userService   service.UserService
-	authService   service.AuthService
-	friendService service.FriendService // 添加好友服务
+	userService       service.UserService
+	authService       service.AuthService
+	friendService     service.FriendService // 添加好友服务
+	suggestionService service.FriendSuggestionService
+	badgeService      service.BadgeService
 }
 
 // NewUserHandler 创建用户API处理器
-func NewUserHandler(userService service.UserService, authService service.AuthService, friendService service.FriendService) *UserHandler {
+func NewUserHandler(userService service.UserService, authService service.AuthService, friendService service.FriendService, suggestionService service.FriendSuggestionService, badgeService service.BadgeService) *UserHandler {
 	return &UserHandler{
-		userService:   userService,
-		authService:   authService,
-		friendService: friendService,
+		userService:       userService,
+		authService:       authService,
+		friendService:     friendService,
+		suggestionService: suggestionService,
+		badgeService:      badgeService,
 	}
 }
 
@@ -44,7 +48,33 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	badges, err := h.badgeService.GetUserBadges(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取徽章失败"})
+		return
+	}
+	profile := &entity.UserProfile{
+		User:          user,
+		MutualFriends: []*entity.User{},
+		Badges:        badges,
+	}
+
+	// 查看他人资料时附带共同好友
+	viewerID, err := h.authService.GetUserIDFromToken(c)
+	if err != nil || viewerID == id {
+		c.JSON(http.StatusOK, profile)
+		return
+	}
+
+	mutualFriends, err := h.suggestionService.GetMutualFriends(c, viewerID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取共同好友失败"})
+		return
+	}
+
+	profile.MutualFriendCount = len(mutualFriends)
+	profile.MutualFriends = mutualFriends
+	c.JSON(http.StatusOK, profile)
 }
 
 // GetUserByOpenID 根据OpenID获取用户
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"record-project/application/service"
+	"record-project/domain/event"
 	"record-project/infrastructure/auth"
+	"record-project/infrastructure/cache"
 	"record-project/infrastructure/config"
+	"record-project/infrastructure/eventbus"
+	"record-project/infrastructure/notify"
 	"record-project/infrastructure/persistence"
 	"record-project/infrastructure/persistence/repository"
+	"record-project/infrastructure/scheduler"
 	"record-project/infrastructure/storage"
 	"record-project/infrastructure/wechat"
 	"record-project/interfaces/api"
@@ -41,9 +47,49 @@ func main() {
 	poopTypeRepo := repository.NewPoopTypeRepository(db.DB)
 	recordTagRepo := repository.NewRecordTagRepository(db.DB)
 	friendRepo := repository.NewFriendRepository(db.DB)
+	recapRepo := repository.NewRecapRepository(db.DB)
+	goalRepo := repository.NewGoalRepository(db.DB)
+	goalCompletionRepo := repository.NewGoalCompletionRepository(db.DB)
+	rankingSnapshotRepo := repository.NewRankingSnapshotRepository(db.DB)
+	rankingSettingRepo := repository.NewRankingSettingRepository(db.DB)
+	recordFlagRepo := repository.NewRecordFlagRepository(db.DB)
+	leagueRepo := repository.NewLeagueRepository(db.DB)
+	leagueDivisionRepo := repository.NewLeagueDivisionRepository(db.DB)
+	leagueMembershipRepo := repository.NewLeagueMembershipRepository(db.DB)
+	blockRepo := repository.NewBlockRepository(db.DB)
+	circleRepo := repository.NewCircleRepository(db.DB)
+	recordVisibilitySettingRepo := repository.NewRecordVisibilitySettingRepository(db.DB)
+	inviteCodeRepo := repository.NewInviteCodeRepository(db.DB)
+	inviteRedemptionRepo := repository.NewInviteRedemptionRepository(db.DB)
+	activityRepo := repository.NewActivityRepository(db.DB)
+	recordReactionRepo := repository.NewRecordReactionRepository(db.DB)
+	recordCommentRepo := repository.NewRecordCommentRepository(db.DB)
+	notificationRepo := repository.NewNotificationRepository(db.DB)
+	nudgeRepo := repository.NewNudgeRepository(db.DB)
+	nudgeSettingRepo := repository.NewNudgeSettingRepository(db.DB)
+	squadRepo := repository.NewSquadRepository(db.DB)
+	challengeRepo := repository.NewChallengeRepository(db.DB)
+	userBadgeRepo := repository.NewUserBadgeRepository(db.DB)
+
+	// 初始化事件总线
+	eventBus := eventbus.NewBus()
+
+	// 初始化排行榜缓存，多实例部署时使用Redis共享缓存
+	var leaderboardStore cache.LeaderboardStore
+	if cfg.Cache.Driver == "redis" {
+		leaderboardStore = cache.NewRedisStore(cache.RedisConfig{
+			Addr:      cfg.Cache.RedisAddr,
+			Password:  cfg.Cache.RedisPassword,
+			DB:        cfg.Cache.RedisDB,
+			KeyPrefix: cfg.Cache.RedisKeyPrefix,
+			PoolSize:  cfg.Cache.RedisPoolSize,
+		})
+	} else {
+		leaderboardStore = cache.NewMemoryStore()
+	}
 
 	// 初始化微信服务
-	wechatService := wechat.NewWechatService(cfg.Wechat.AppID, cfg.Wechat.AppSecret)
+	wechatService := wechat.NewWechatService(cfg.Wechat.AppID, cfg.Wechat.AppSecret, cfg.Wechat.APIBaseURL)
 
 	// 初始化阿里云OSS服务
 	ossService, err := storage.NewOSSService(&cfg.Aliyun)
@@ -53,28 +99,108 @@ func main() {
 
 	// 初始化应用服务
 	userService := service.NewUserService(userRepo)
-	recordService := service.NewRecordService(recordRepo, recordTagRepo)
+	recordVisibilityService := service.NewRecordVisibilityService(recordVisibilitySettingRepo, circleRepo, friendRepo, blockRepo)
+	recordService := service.NewRecordService(recordRepo, recordTagRepo, eventBus, recordVisibilityService)
 	tagService := service.NewTagService(tagRepo, recordTagRepo)
 	poopTypeService := service.NewPoopTypeService(poopTypeRepo)
-	authService := service.NewAuthService(userService, wechatService)
+	inviteService := service.NewInviteService(inviteCodeRepo, inviteRedemptionRepo, friendRepo, wechatService, cfg.Wechat.InvitePage, eventBus)
+	authService := service.NewAuthService(userService, wechatService, inviteService)
 	fileService := service.NewFileService(ossService)
-	friendService := service.NewFriendService(friendRepo)
+	friendService := service.NewFriendService(friendRepo, cfg.Friend.MaxPendingRequests, time.Duration(cfg.Friend.RequestExpiryDays)*24*time.Hour, eventBus)
+	recapService := service.NewRecapService(recapRepo, recordRepo, friendRepo, rankingSettingRepo)
+	predictionService := service.NewPredictionService(recordRepo)
+	benchmarkService := service.NewBenchmarkService(recordRepo, friendRepo, userRepo)
+	goalService := service.NewGoalService(goalRepo, goalCompletionRepo, recordRepo, eventBus)
+	rankingSnapshotService := service.NewRankingSnapshotService(rankingSnapshotRepo, recordRepo, friendRepo, userRepo, rankingSettingRepo, eventBus)
+	leaderboardCacheService := service.NewLeaderboardCacheService(leaderboardStore, recordRepo, rankingSettingRepo)
+	rankingSettingService := service.NewRankingSettingService(rankingSettingRepo, eventBus)
+	antiCheatService := service.NewAntiCheatService(recordFlagRepo, recordRepo, eventBus, cfg.Moderation.ModeratorIDs)
+	leagueService := service.NewLeagueService(leagueRepo, leagueDivisionRepo, leagueMembershipRepo, recordRepo, friendRepo, rankingSettingRepo, cfg.League.Period, cfg.League.Metric)
+	blockService := service.NewBlockService(blockRepo, userRepo)
+	friendSuggestionService := service.NewFriendSuggestionService(friendRepo, userRepo, blockRepo, leagueMembershipRepo)
+	circleService := service.NewCircleService(circleRepo, friendRepo)
+	recordInteractionService := service.NewRecordInteractionService(recordReactionRepo, recordCommentRepo, recordRepo, userRepo, recordVisibilityService)
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, notify.NewLogNotifier())
+	nudgeService := service.NewNudgeService(nudgeRepo, nudgeSettingRepo, friendRepo, userRepo, recordService, notificationService)
+	feedService := service.NewFeedService(activityRepo, recordRepo, friendRepo, circleRepo, userRepo, rankingSnapshotRepo, rankingSettingRepo, recordInteractionService)
+	squadService := service.NewSquadService(squadRepo, recordRepo, userRepo, rankingSettingRepo, cfg.Squad.MaxMembers, cfg.Squad.MaxJoined, cfg.Squad.InvitePage)
+	challengeService := service.NewChallengeService(challengeRepo, recordRepo, friendRepo, userRepo, notificationService)
+	badgeService := service.NewBadgeService(userBadgeRepo, recordRepo, friendRepo, userRepo, notificationService, eventBus)
+
+	// 订阅领域事件，反作弊检查需要最先执行，以便后续处理时已排除被标记的记录
+	eventBus.Subscribe(event.RecordCreated, antiCheatService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordUpdated, antiCheatService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordCreated, goalService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordUpdated, goalService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordDeleted, goalService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordCreated, leaderboardCacheService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordUpdated, leaderboardCacheService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordDeleted, leaderboardCacheService.HandleRecordChanged)
+	eventBus.Subscribe(event.RankingSettingChanged, leaderboardCacheService.HandleRankingSettingChanged)
+	eventBus.Subscribe(event.RecordFlagged, leaderboardCacheService.HandleRecordFlagChanged)
+	eventBus.Subscribe(event.RecordCreated, feedService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordUpdated, feedService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordDeleted, feedService.HandleRecordChanged)
+	eventBus.Subscribe(event.GoalMet, feedService.HandleGoalMet)
+	eventBus.Subscribe(event.GoalRevoked, feedService.HandleGoalRevoked)
+	eventBus.Subscribe(event.RankingSnapshotTaken, feedService.HandleRankingSnapshotTaken)
+	eventBus.Subscribe(event.RecordDeleted, recordInteractionService.HandleRecordDeleted)
+	eventBus.Subscribe(event.RecordCreated, badgeService.HandleRecordChanged)
+	eventBus.Subscribe(event.RecordUpdated, badgeService.HandleRecordChanged)
+	eventBus.Subscribe(event.FriendAccepted, badgeService.HandleFriendAccepted)
+	eventBus.Subscribe(event.BadgeAwarded, feedService.HandleBadgeAwarded)
 
 	// 初始化API处理器
-	userHandler := api.NewUserHandler(userService, authService, friendService)
-	recordHandler := api.NewRecordHandler(recordService, userService, tagService, poopTypeService)
+	userHandler := api.NewUserHandler(userService, authService, friendService, friendSuggestionService, badgeService)
+	recordHandler := api.NewRecordHandler(recordService, userService, tagService, poopTypeService, authService, recordVisibilityService, recordInteractionService)
 	tagHandler := api.NewTagHandler(tagService)
 	poopTypeHandler := api.NewPoopTypeHandler(poopTypeService)
 	authHandler := api.NewAuthHandler(authService)
 	fileHandler := api.NewFileHandler(fileService)
-	rankingHandler := api.NewRankingHandler(recordService, authService, userService, friendService)
-	friendHandler := api.NewFriendHandler(friendService, authService, userService)
+	rankingHandler := api.NewRankingHandler(recordService, authService, userService, friendService, benchmarkService, rankingSnapshotService, leaderboardCacheService, rankingSettingService, circleService)
+	friendHandler := api.NewFriendHandler(friendService, authService, userService, friendSuggestionService, circleService, nudgeService)
+	recapHandler := api.NewRecapHandler(recapService, authService)
+	predictionHandler := api.NewPredictionHandler(predictionService, authService)
+	goalHandler := api.NewGoalHandler(goalService, authService)
+	recordFlagHandler := api.NewRecordFlagHandler(antiCheatService, authService)
+	leagueHandler := api.NewLeagueHandler(leagueService, authService, userService, rankingSettingService)
+	blockHandler := api.NewBlockHandler(blockService, authService)
+	inviteHandler := api.NewInviteHandler(inviteService, authService)
+	feedHandler := api.NewFeedHandler(feedService, authService)
+	recordInteractionHandler := api.NewRecordInteractionHandler(recordInteractionService, authService)
+	notificationHandler := api.NewNotificationHandler(notificationService, authService)
+	squadHandler := api.NewSquadHandler(squadService, authService, rankingSettingService)
+	challengeHandler := api.NewChallengeHandler(challengeService, authService)
+	badgeHandler := api.NewBadgeHandler(badgeService, authService)
+
+	// 初始化定时任务
+	jobScheduler := scheduler.NewScheduler()
+	jobScheduler.AddJob("生成月度/年度回顾", time.Hour, func(ctx context.Context) error {
+		return recapService.GenerateDueRecaps(ctx, time.Now())
+	})
+	jobScheduler.AddJob("结算个人目标", 10*time.Minute, func(ctx context.Context) error {
+		return goalService.SettleGoals(ctx, time.Now())
+	})
+	jobScheduler.AddJob("生成排行榜快照", time.Hour, func(ctx context.Context) error {
+		return rankingSnapshotService.TakeDueSnapshots(ctx, time.Now())
+	})
+	jobScheduler.AddJob("联赛赛季轮换", 10*time.Minute, func(ctx context.Context) error {
+		return leagueService.RolloverSeasons(ctx, time.Now())
+	})
+	jobScheduler.AddJob("清理过期好友申请", time.Hour, func(ctx context.Context) error {
+		return friendService.ExpireRequests(ctx, time.Now())
+	})
+	jobScheduler.AddJob("结算好友挑战", 5*time.Minute, func(ctx context.Context) error {
+		return challengeService.SettleDueChallenges(ctx, time.Now())
+	})
+	jobScheduler.Start()
+	defer jobScheduler.Stop()
 
 	// 创建Gin引擎
 	r := gin.Default()
 
 	// 注册路由
-	api.RegisterRoutes(r, userHandler, recordHandler, tagHandler, poopTypeHandler, authHandler, fileHandler, rankingHandler, friendHandler)
+	api.RegisterRoutes(r, userHandler, recordHandler, tagHandler, poopTypeHandler, authHandler, fileHandler, rankingHandler, friendHandler, recapHandler, predictionHandler, goalHandler, recordFlagHandler, leagueHandler, blockHandler, inviteHandler, feedHandler, recordInteractionHandler, notificationHandler, squadHandler, challengeHandler, badgeHandler)
 
 	// 启动服务器
 	log.Printf("服务器启动在 :%d 端口", cfg.Server.Port)